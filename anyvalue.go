@@ -0,0 +1,45 @@
+package docparser
+
+import "regexp"
+
+// PatternAnyValue is a Pattern implementation that tries several regexes
+// in order for a single field, i.e. phone numbers written in
+// structurally different ways, instead of alternating them in one regex
+type PatternAnyValue struct {
+	Name string
+
+	// FieldName is the field the value is stored in
+	FieldName string
+
+	// Regexes are tried in order and the first one that matches gives
+	// the value: its first capturing group, or the whole match if it
+	// has none
+	Regexes []*regexp.Regexp
+
+	Optional bool
+}
+
+// Search content with each regex in Regexes until one matches
+//
+// Return NoMatch error if none matches and the pattern isn't Optional
+func (pa *PatternAnyValue) Search(content string) (Fields, error) {
+	for _, regex := range pa.Regexes {
+		match := regex.FindStringSubmatch(content)
+		if match == nil {
+			continue
+		}
+		if len(match) > 1 {
+			return Fields{pa.FieldName: match[1]}, nil
+		}
+		return Fields{pa.FieldName: match[0]}, nil
+	}
+	if pa.Optional {
+		return Fields{}, nil
+	}
+	return Fields{}, NewNoMatch(pa.Name, content)
+}
+
+// FieldNames returns FieldName
+func (pa *PatternAnyValue) FieldNames() []string {
+	return []string{pa.FieldName}
+}
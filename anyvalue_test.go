@@ -0,0 +1,69 @@
+package docparser_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+func ExamplePatternAnyValue() {
+	pattern := &docparser.PatternAnyValue{
+		Name:      "Phone",
+		FieldName: "phone",
+		Regexes: []*regexp.Regexp{
+			regexp.MustCompile(`Phone: (.*)`),
+			regexp.MustCompile(`call me at (\S+)`),
+			regexp.MustCompile(`\(\d{3}\) \d{3}-\d{4}`),
+		},
+	}
+
+	for _, content := range []string{
+		"Phone: 221-1122",
+		"Please call me at 808-221-1122 tomorrow",
+		"Reach me on (808) 221-1122",
+	} {
+		fields, err := pattern.Search(content)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(fields.GetString("phone"))
+	}
+	// Output:
+	// 221-1122
+	// 808-221-1122
+	// (808) 221-1122
+}
+
+func TestPatternAnyValueOrder(t *testing.T) {
+	pattern := &docparser.PatternAnyValue{
+		FieldName: "phone",
+		Regexes: []*regexp.Regexp{
+			regexp.MustCompile(`Mobile: (.*)`),
+			regexp.MustCompile(`Phone: (.*)`),
+		},
+	}
+	fields, err := pattern.Search("Phone: 111\nMobile: 222")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if phone := fields.GetString("phone"); phone != "222" {
+		t.Errorf("want first regex to win, got %q", phone)
+	}
+}
+
+func TestPatternAnyValueNoMatch(t *testing.T) {
+	pattern := &docparser.PatternAnyValue{
+		Name:      "Phone",
+		FieldName: "phone",
+		Regexes:   []*regexp.Regexp{regexp.MustCompile(`Phone: (.*)`)},
+	}
+	if _, err := pattern.Search("Email: bob@site.com"); err == nil || err.Error() != `No match for "Phone"` {
+		t.Errorf("want NoMatch got %v", err)
+	}
+	pattern.Optional = true
+	if fields, err := pattern.Search("Email: bob@site.com"); err != nil || len(fields) != 0 {
+		t.Errorf("want empty fields got %v %v", fields, err)
+	}
+}
@@ -0,0 +1,93 @@
+package docparser
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// PatternBase64 is a Pattern implementation that decodes a base64 blob
+// found in the content, like the payload some automated senders attach,
+// and searches the decoded text with another Pattern
+type PatternBase64 struct {
+	Name string
+
+	// Regex where the first group captures the blob. Whitespace and
+	// line breaks inside the blob are ignored, standard and URL-safe
+	// encodings are accepted, with or without padding
+	Regex *regexp.Regexp
+
+	// Pattern searches the decoded text. Optional if FieldName is set
+	Pattern Pattern
+
+	// FieldName stores the decoded text in a field. Optional
+	FieldName string
+
+	Optional bool
+}
+
+// Search for the blob in content, decode it and return the fields of
+// Pattern plus FieldName
+//
+// Return NoMatch error if Regex doesn't match, and an error if the blob
+// isn't valid base64
+func (pb *PatternBase64) Search(content string) (Fields, error) {
+	matches := pb.Regex.FindStringSubmatch(content)
+	if matches == nil {
+		if pb.Optional {
+			return Fields{}, nil
+		}
+		return Fields{}, NewNoMatch(pb.Name, content)
+	}
+
+	decoded, err := decodeBase64(matches[1])
+	if err != nil {
+		return Fields{}, fmt.Errorf("failed to decode base64 for %s: %v", pb.Name, err)
+	}
+	fields := Fields{}
+	if pb.Pattern != nil {
+		if fields, err = pb.Pattern.Search(decoded); err != nil {
+			return Fields{}, err
+		}
+		if fields == nil {
+			fields = Fields{}
+		}
+	}
+	if pb.FieldName != "" {
+		fields[pb.FieldName] = decoded
+	}
+	return fields, nil
+}
+
+// decodeBase64 decodes blob with the standard or URL-safe encoding,
+// depending on its alphabet, ignoring whitespace and padding
+func decodeBase64(blob string) (string, error) {
+	blob = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, blob)
+	blob = strings.TrimRight(blob, "=")
+	encoding := base64.RawStdEncoding
+	if strings.ContainsAny(blob, "-_") {
+		encoding = base64.RawURLEncoding
+	}
+	decoded, err := encoding.DecodeString(blob)
+	if err != nil {
+		return "", err
+	}
+	return string(decoded), nil
+}
+
+// FieldNames returns the field names of Pattern if it's a FieldNamer,
+// plus FieldName
+func (pb *PatternBase64) FieldNames() []string {
+	names := wrappedPattern{pb.Pattern}.FieldNames()
+	if pb.FieldName != "" {
+		names = append(names, pb.FieldName)
+	}
+	return names
+}
@@ -0,0 +1,96 @@
+package docparser_test
+
+import (
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+func ExamplePatternBase64() {
+	pattern := &docparser.PatternBase64{
+		Name:  "Payload",
+		Regex: regexp.MustCompile(`(?s:-----BEGIN PAYLOAD-----\n(.*?)-----END PAYLOAD-----)`),
+		Pattern: &docparser.PatternGroup{
+			Name:  "Contact",
+			Regex: regexp.MustCompile(`name=(?P<name>.*)\nphone=(?P<phone>.*)`),
+		},
+	}
+
+	content := `New lead, see attached payload
+-----BEGIN PAYLOAD-----
+bmFtZT1NYXJrIFN0ZXdh
+cnQKcGhvbmU9MjIxLTExMjI=
+-----END PAYLOAD-----
+`
+
+	fields, err := pattern.Search(content)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(fields.GetString("name"))
+	fmt.Println(fields.GetString("phone"))
+	// Output:
+	// Mark Stewart
+	// 221-1122
+}
+
+func TestPatternBase64Encodings(t *testing.T) {
+	pattern := &docparser.PatternBase64{
+		Regex:     regexp.MustCompile(`data:(\S+)`),
+		FieldName: "decoded",
+	}
+	text := "a?b>c~"
+	for _, encoding := range []*base64.Encoding{
+		base64.StdEncoding, base64.RawStdEncoding,
+		base64.URLEncoding, base64.RawURLEncoding,
+	} {
+		content := "data:" + encoding.EncodeToString([]byte(text))
+		fields, err := pattern.Search(content)
+		if err != nil {
+			t.Errorf("content %q failed: %s", content, err)
+			continue
+		}
+		if want := (docparser.Fields{"decoded": text}); !reflect.DeepEqual(fields, want) {
+			t.Errorf("content %q want %v got %v", content, want, fields)
+		}
+	}
+}
+
+func TestPatternBase64Errors(t *testing.T) {
+	pattern := &docparser.PatternBase64{
+		Name:      "Payload",
+		Regex:     regexp.MustCompile(`data:(\S+)`),
+		FieldName: "decoded",
+	}
+	if _, err := pattern.Search("nothing"); err == nil || err.Error() != `No match for "Payload"` {
+		t.Errorf("want NoMatch got %v", err)
+	}
+	_, err := pattern.Search("data:not*base64")
+	if err == nil || err.Error() != "failed to decode base64 for Payload: illegal base64 data at input byte 3" {
+		t.Errorf("invalid error: %v", err)
+	}
+	pattern.Optional = true
+	if fields, err := pattern.Search("nothing"); err != nil || len(fields) != 0 {
+		t.Errorf("want empty fields got %v %v", fields, err)
+	}
+}
+
+func TestPatternBase64NilFields(t *testing.T) {
+	pattern := &docparser.PatternBase64{
+		Regex:     regexp.MustCompile(`Payload: (\S+)`),
+		Pattern:   &constantPattern{},
+		FieldName: "raw",
+	}
+	fields, err := pattern.Search("Payload: " + base64.StdEncoding.EncodeToString([]byte("hi")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (docparser.Fields{"raw": "hi"}); !reflect.DeepEqual(fields, want) {
+		t.Errorf("want %v got %v", want, fields)
+	}
+}
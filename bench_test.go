@@ -0,0 +1,122 @@
+package docparser_test
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+const benchContent = `Lead from website
+
+Name: Mark Stewart
+Email: mark@site.com
+Phone: (123) 221-1122
+
+Properties:
+ - MLS #2211 / 331 Kailua Rd, HI
+ - MLS #9090 / 990 Kaelepulu Dr, HI
+ - MLS #4411 / 12 Lanikai Ave, HI
+`
+
+func BenchmarkPatternGroup(b *testing.B) {
+	pattern := &docparser.PatternGroup{
+		Regex: regexp.MustCompile(`Name: (?P<name>.*)\nEmail: (?P<email>.*)\nPhone: (?P<phone>.*)`),
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := pattern.Search(benchContent); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPatternList(b *testing.B) {
+	pattern := &docparser.PatternList{
+		Name:       "properties",
+		ListRegex:  regexp.MustCompile(`(?s:Properties:\n(?P<list>.*))`),
+		SplitRegex: regexp.MustCompile(`\n`),
+		ItemRegex:  regexp.MustCompile(` - MLS #(?P<mls>\d+) / (?P<address>.*)`),
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := pattern.Search(benchContent); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDocumentSinglePattern(b *testing.B) {
+	document := &docparser.Document{
+		Patterns: []docparser.Pattern{
+			&docparser.PatternGroup{
+				Regex: regexp.MustCompile(`Name: (?P<name>.*)\nEmail: (?P<email>.*)\nPhone: (?P<phone>.*)`),
+			},
+		},
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := document.Search(benchContent); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDocument(b *testing.B) {
+	document := &docparser.Document{
+		Patterns: []docparser.Pattern{
+			&docparser.PatternGroup{Regex: regexp.MustCompile(`Name: (?P<name>.*)`)},
+			&docparser.PatternGroup{Regex: regexp.MustCompile(`Email: (?P<email>.*)`)},
+			&docparser.PatternGroup{Regex: regexp.MustCompile(`Phone: (?P<phone>.*)`)},
+		},
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := document.Search(benchContent); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkDocuments searches many layouts where only the last matches
+func BenchmarkDocuments(b *testing.B) {
+	for _, n := range []int{1, 10, 100} {
+		documents := docparser.Documents{}
+		for i := 0; i < n-1; i++ {
+			documents = append(documents, &docparser.Document{
+				Patterns: []docparser.Pattern{
+					&docparser.PatternGroup{Regex: regexp.MustCompile(fmt.Sprintf(`Layout %d name: (?P<name>.*)`, i))},
+				},
+			})
+		}
+		documents = append(documents, &docparser.Document{
+			Patterns: []docparser.Pattern{
+				&docparser.PatternGroup{Regex: regexp.MustCompile(`Name: (?P<name>.*)`)},
+			},
+		})
+		b.Run(fmt.Sprintf("layouts=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := documents.Search(benchContent); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkPatternGroupLargeContent(b *testing.B) {
+	pattern := &docparser.PatternGroup{
+		Regex: regexp.MustCompile(`Name: (?P<name>.*)`),
+	}
+	content := strings.Repeat("filler line of an oversized email\n", 10000) + benchContent
+	b.SetBytes(int64(len(content)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := pattern.Search(content); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
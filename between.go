@@ -0,0 +1,68 @@
+package docparser
+
+import (
+	"regexp"
+	"strings"
+)
+
+// PatternBetween is a Pattern implementation that captures the text
+// between two markers as a field, i.e. the free text note between the
+// "Subject:" line and the "Sent from" footer
+//
+// Unlike PatternScoped, which searches another Pattern in the region,
+// the region itself is the value
+type PatternBetween struct {
+	Name string
+
+	// Start marks the beginning of the text, which starts right after
+	// the first Start match
+	Start *regexp.Regexp
+
+	// End marks the end of the text, which ends right before the first
+	// End match after Start
+	End *regexp.Regexp
+
+	// FieldName is the field that gets the text
+	FieldName string
+
+	// TrimSpace removes leading and trailing white space from the text
+	TrimSpace bool
+
+	Optional bool
+}
+
+// Search for the text between Start and End
+//
+// Return NoMatch error if Start is not found or End is not found after
+// it, even if there's an End match before Start
+func (pb *PatternBetween) Search(content string) (Fields, error) {
+	text, ok := pb.find(content)
+	if !ok {
+		if pb.Optional {
+			return Fields{}, nil
+		}
+		return Fields{}, NewNoMatch(pb.Name, content)
+	}
+	if pb.TrimSpace {
+		text = strings.TrimSpace(text)
+	}
+	return Fields{pb.FieldName: text}, nil
+}
+
+func (pb *PatternBetween) find(content string) (string, bool) {
+	start := pb.Start.FindStringIndex(content)
+	if start == nil {
+		return "", false
+	}
+	text := content[start[1]:]
+	end := pb.End.FindStringIndex(text)
+	if end == nil {
+		return "", false
+	}
+	return text[:end[0]], true
+}
+
+// FieldNames returns FieldName
+func (pb *PatternBetween) FieldNames() []string {
+	return []string{pb.FieldName}
+}
@@ -0,0 +1,74 @@
+package docparser_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+func ExamplePatternBetween() {
+	pattern := &docparser.PatternBetween{
+		Name:      "Note",
+		Start:     regexp.MustCompile(`(?m:^Subject: .*$)`),
+		End:       regexp.MustCompile(`(?m:^Sent from)`),
+		FieldName: "note",
+		TrimSpace: true,
+	}
+
+	content := `Subject: Open house
+I'd like to visit on Sunday,
+after lunch if possible.
+
+Sent from my phone`
+
+	fields, err := pattern.Search(content)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(fields.GetString("note"))
+	// Output:
+	// I'd like to visit on Sunday,
+	// after lunch if possible.
+}
+
+func TestPatternBetween(t *testing.T) {
+	pattern := &docparser.PatternBetween{
+		Name:      "Note",
+		Start:     regexp.MustCompile(`<<`),
+		End:       regexp.MustCompile(`>>`),
+		FieldName: "note",
+	}
+	var tests = []struct {
+		content, note string
+		ok            bool
+	}{
+		{"a << b >> c", " b ", true},
+		{"<<>>", "", true},
+		{"<< one >> << two >>", " one ", true},
+		{">> x << y >>", " y ", true},
+		{">> x <<", "", false},
+		{"no markers", "", false},
+		{"<< no end", "", false},
+	}
+	for _, tt := range tests {
+		fields, err := pattern.Search(tt.content)
+		if !tt.ok {
+			if _, ok := err.(*docparser.NoMatch); !ok {
+				t.Errorf("%q: want NoMatch got %v", tt.content, err)
+			}
+			continue
+		}
+		if err != nil || fields.GetString("note") != tt.note {
+			t.Errorf("%q: want %q got %v (%v)", tt.content, tt.note, fields, err)
+		}
+	}
+
+	pattern.Optional = true
+	fields, err := pattern.Search("no markers")
+	if err != nil || len(fields) != 0 {
+		t.Errorf("optional want empty fields got %v (%v)", fields, err)
+	}
+}
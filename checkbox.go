@@ -0,0 +1,92 @@
+package docparser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// CheckboxRegex is the default regex of PatternCheckboxes, for options
+// like "[x] Option A  [ ] Option B", each label running up to the next
+// checkbox or the end of the line
+var CheckboxRegex = regexp.MustCompile(`\[(?P<marker>[^\]\r\n]?)\][ \t]*(?P<label>[^\[\r\n]*)`)
+
+// PatternCheckboxes is a Pattern implementation that extracts the
+// options selected in checkbox lists, as rendered by forms forwarded as
+// email
+type PatternCheckboxes struct {
+	Name string
+
+	// Regex matches each option, the group "marker" capturing the
+	// checkbox state and "label" the option. Defaults to CheckboxRegex
+	Regex *regexp.Regexp
+
+	// Checked are the markers of selected options, after trimming
+	// white space. Defaults to "x", "X", "*" and "✓"
+	Checked []string
+
+	// OutputKey is the field that gets the []string of selected
+	// labels, or the []Fields of all options if All is set
+	OutputKey string
+
+	// All returns every option as Fields with the keys "label" and
+	// "checked", a bool, instead of only the selected labels
+	All bool
+
+	Optional bool
+}
+
+// Search for all options in content, in order
+//
+// Labels are trimmed and options with an empty label are ignored.
+// Return NoMatch error if no option is found and the pattern isn't
+// Optional. Finding options but none selected is not an error. Return
+// an error if Regex has no "label" group
+func (pc *PatternCheckboxes) Search(content string) (Fields, error) {
+	regex := pc.Regex
+	if regex == nil {
+		regex = CheckboxRegex
+	}
+	checked := pc.Checked
+	if checked == nil {
+		checked = []string{"x", "X", "*", "\u2713"}
+	}
+	markerIndex, labelIndex := regex.SubexpIndex("marker"), regex.SubexpIndex("label")
+	if labelIndex == -1 {
+		return Fields{}, fmt.Errorf("no \"label\" group in regex for %s", pc.Name)
+	}
+
+	selected, all := []string{}, []Fields{}
+	for _, match := range regex.FindAllStringSubmatch(content, -1) {
+		label := strings.TrimSpace(match[labelIndex])
+		if label == "" {
+			continue
+		}
+		isChecked := false
+		if markerIndex != -1 {
+			marker := strings.TrimSpace(match[markerIndex])
+			for _, c := range checked {
+				isChecked = isChecked || marker == c
+			}
+		}
+		if isChecked {
+			selected = append(selected, label)
+		}
+		all = append(all, Fields{"label": label, "checked": isChecked})
+	}
+	if len(all) == 0 {
+		if pc.Optional {
+			return Fields{}, nil
+		}
+		return Fields{}, NewNoMatch(pc.Name, content)
+	}
+	if pc.All {
+		return Fields{pc.OutputKey: all}, nil
+	}
+	return Fields{pc.OutputKey: selected}, nil
+}
+
+// FieldNames returns OutputKey
+func (pc *PatternCheckboxes) FieldNames() []string {
+	return []string{pc.OutputKey}
+}
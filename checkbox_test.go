@@ -0,0 +1,90 @@
+package docparser_test
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+func ExamplePatternCheckboxes() {
+	pattern := &docparser.PatternCheckboxes{
+		Name:      "Interests",
+		OutputKey: "interests",
+	}
+
+	fields, err := pattern.Search("Interested in: [x] Buying  [ ] Selling  [X] Renting\n")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("%q\n", fields["interests"])
+	// Output:
+	// ["Buying" "Renting"]
+}
+
+func TestPatternCheckboxes(t *testing.T) {
+	content := "[x] Option A  [ ] Option B\n[\u2713] Option C\n[] Option D\n[*]\n"
+
+	pattern := &docparser.PatternCheckboxes{OutputKey: "options"}
+	fields, err := pattern.Search(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"Option A", "Option C"}; !reflect.DeepEqual(fields["options"], want) {
+		t.Errorf("want %q got %q", want, fields["options"])
+	}
+
+	pattern.All = true
+	pattern.Checked = []string{"\u2713"}
+	fields, err = pattern.Search(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []docparser.Fields{
+		{"label": "Option A", "checked": false},
+		{"label": "Option B", "checked": false},
+		{"label": "Option C", "checked": true},
+		{"label": "Option D", "checked": false},
+	}
+	if !reflect.DeepEqual(fields["options"], want) {
+		t.Errorf("want %v got %v", want, fields["options"])
+	}
+	if options := fields.GetMapSlice("options"); len(options) != 4 || options[2]["checked"] != "true" {
+		t.Errorf("GetMapSlice: invalid options %v", options)
+	}
+
+	pattern = &docparser.PatternCheckboxes{
+		Regex:     regexp.MustCompile(`(?m:^\((?P<marker>.)\) (?P<label>.*)$)`),
+		Checked:   []string{"o"},
+		OutputKey: "options",
+	}
+	fields, err = pattern.Search("(o) Yes\n( ) No\n")
+	if err != nil || !reflect.DeepEqual(fields["options"], []string{"Yes"}) {
+		t.Errorf("custom regex: invalid result %v (%v)", fields, err)
+	}
+}
+
+func TestPatternCheckboxesErrors(t *testing.T) {
+	pattern := &docparser.PatternCheckboxes{Name: "Options", OutputKey: "options"}
+	if _, err := pattern.Search("no options"); err == nil || err.Error() != `No match for "Options"` {
+		t.Errorf("want NoMatch got %v", err)
+	}
+	fields, err := pattern.Search("[ ] A [ ] B")
+	if err != nil || !reflect.DeepEqual(fields["options"], []string{}) {
+		t.Errorf("none selected: invalid result %v (%v)", fields, err)
+	}
+
+	pattern.Regex = regexp.MustCompile(`\[(?P<marker>.)\] (?P<option>\w+)`)
+	if _, err := pattern.Search("[x] A"); err == nil {
+		t.Error("regex without label group did not return error")
+	}
+
+	pattern.Regex = nil
+	pattern.Optional = true
+	if fields, err := pattern.Search("no options"); err != nil || len(fields) != 0 {
+		t.Errorf("optional want empty fields got %v (%v)", fields, err)
+	}
+}
@@ -0,0 +1,96 @@
+package clean
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/RealGeeks/docparser"
+)
+
+// usStates are the USPS codes of states, territories and military
+// addresses
+var usStates = map[string]bool{
+	"AL": true, "AK": true, "AZ": true, "AR": true, "CA": true, "CO": true,
+	"CT": true, "DE": true, "DC": true, "FL": true, "GA": true, "HI": true,
+	"ID": true, "IL": true, "IN": true, "IA": true, "KS": true, "KY": true,
+	"LA": true, "ME": true, "MD": true, "MA": true, "MI": true, "MN": true,
+	"MS": true, "MO": true, "MT": true, "NE": true, "NV": true, "NH": true,
+	"NJ": true, "NM": true, "NY": true, "NC": true, "ND": true, "OH": true,
+	"OK": true, "OR": true, "PA": true, "RI": true, "SC": true, "SD": true,
+	"TN": true, "TX": true, "UT": true, "VT": true, "VA": true, "WA": true,
+	"WV": true, "WI": true, "WY": true, "AS": true, "GU": true, "MP": true,
+	"PR": true, "VI": true, "AA": true, "AE": true, "AP": true,
+}
+
+var (
+	addressStateZipRe = regexp.MustCompile(`(?i)[\s,]+([a-z]{2})\.?(?:\s+(\d{5})(?:\s*-\s*(\d{4}))?)?$`)
+	addressUnitRe     = regexp.MustCompile(`(?i)(?:^|[\s,]+)((?:apt|apartment|unit|suite|ste|bldg|fl|floor|rm|room)\.?\s*#?\s*(?:[\w-]*\d[\w-]*|[a-z])|#\s*[\w-]+)$`)
+)
+
+// CleanAddress splits the US address in key, like "331 Kailua Rd Apt
+// 2, Kailua, HI 96734-1234", into its components, stored as
+// key+"_street", key+"_unit", key+"_city", key+"_state" and
+// key+"_zip". The full address is left untouched, and components not
+// found are not added
+//
+// Addresses are split with these rules, in order:
+//
+//	Line breaks are commas, so multi-line addresses are supported
+//	The address must end with a state code, optionally followed by a
+//	zip or zip+4, or nothing is added
+//	The part before the state, after the last comma, is the city, so
+//	a comma is required between the street and the city
+//	A trailing unit (Apt, Unit, Suite, Ste, Bldg, Fl, Rm or #) of the
+//	street is the unit, if its number has a digit or is a single
+//	letter, so "1 Apt Way" has no unit
+//
+// The state is stored uppercase and zip+4 as "96734-1234", everything
+// else as written
+func CleanAddress(key string) func(f docparser.Fields) docparser.Fields {
+	return func(f docparser.Fields) docparser.Fields {
+		for component, value := range splitAddress(f.GetString(key)) {
+			if value != "" {
+				f[key+"_"+component] = value
+			}
+		}
+		return f
+	}
+}
+
+func splitAddress(address string) map[string]string {
+	lines := strings.Split(strings.TrimSpace(address), "\n")
+	for i := range lines {
+		lines[i] = strings.Trim(lines[i], " \t\r,")
+	}
+	address = strings.Join(lines, ", ")
+
+	m := addressStateZipRe.FindStringSubmatchIndex(address)
+	if m == nil {
+		return nil
+	}
+	state := strings.ToUpper(address[m[2]:m[3]])
+	if !usStates[state] {
+		return nil
+	}
+	parts := map[string]string{"state": state}
+	if m[4] >= 0 {
+		parts["zip"] = address[m[4]:m[5]]
+		if m[6] >= 0 {
+			parts["zip"] += "-" + address[m[6]:m[7]]
+		}
+	}
+
+	rest := strings.TrimRight(address[:m[0]], " ,")
+	comma := strings.LastIndex(rest, ",")
+	if comma == -1 {
+		return parts
+	}
+	parts["city"] = strings.TrimSpace(rest[comma+1:])
+	street := strings.TrimRight(rest[:comma], " ,")
+	if u := addressUnitRe.FindStringSubmatchIndex(street); u != nil && u[0] > 0 {
+		parts["unit"] = street[u[2]:u[3]]
+		street = strings.TrimRight(street[:u[0]], " ,")
+	}
+	parts["street"] = street
+	return parts
+}
@@ -0,0 +1,58 @@
+package clean_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+	"github.com/RealGeeks/docparser/clean"
+)
+
+func ExampleCleanAddress() {
+	f := clean.CleanAddress("address")(docparser.Fields{"address": "331 Kailua Rd, Kailua, HI 96734"})
+
+	fmt.Println(f.GetString("address_street"))
+	fmt.Println(f.GetString("address_city"))
+	fmt.Println(f.GetString("address_state"))
+	fmt.Println(f.GetString("address_zip"))
+	// Output:
+	// 331 Kailua Rd
+	// Kailua
+	// HI
+	// 96734
+}
+
+func TestCleanAddress(t *testing.T) {
+	var tests = []struct {
+		in   string
+		want docparser.Fields
+	}{
+		{"331 Kailua Rd, Kailua, HI 96734", docparser.Fields{"street": "331 Kailua Rd", "city": "Kailua", "state": "HI", "zip": "96734"}},
+		{"331 Kailua Rd, Kailua, HI 96734-1234", docparser.Fields{"street": "331 Kailua Rd", "city": "Kailua", "state": "HI", "zip": "96734-1234"}},
+		{"331 Kailua Rd, Kailua HI 96734 - 1234", docparser.Fields{"street": "331 Kailua Rd", "city": "Kailua", "state": "HI", "zip": "96734-1234"}},
+		{"331 Kailua Rd Apt 2B, Kailua, HI 96734", docparser.Fields{"street": "331 Kailua Rd", "unit": "Apt 2B", "city": "Kailua", "state": "HI", "zip": "96734"}},
+		{"331 Kailua Rd Unit B, Kailua, HI 96734", docparser.Fields{"street": "331 Kailua Rd", "unit": "Unit B", "city": "Kailua", "state": "HI", "zip": "96734"}},
+		{"331 Kailua Rd, Unit 5, Kailua, HI 96734", docparser.Fields{"street": "331 Kailua Rd", "unit": "Unit 5", "city": "Kailua", "state": "HI", "zip": "96734"}},
+		{"12 Main St #304, San Jose, ca. 95112", docparser.Fields{"street": "12 Main St", "unit": "#304", "city": "San Jose", "state": "CA", "zip": "95112"}},
+		{"500 Ste. Marie Ave, Suite 100, Austin, TX", docparser.Fields{"street": "500 Ste. Marie Ave", "unit": "Suite 100", "city": "Austin", "state": "TX"}},
+		{"1 Apt Way, Honolulu, HI 96815", docparser.Fields{"street": "1 Apt Way", "city": "Honolulu", "state": "HI", "zip": "96815"}},
+		{"331 Kailua Rd\nKailua, HI 96734\n", docparser.Fields{"street": "331 Kailua Rd", "city": "Kailua", "state": "HI", "zip": "96734"}},
+		{"Kailua, HI 96734", docparser.Fields{"state": "HI", "zip": "96734"}},
+		{"331 Kailua Rd Kailua HI 96734", docparser.Fields{"state": "HI", "zip": "96734"}},
+		{"331 Kailua Rd, Kailua, ZZ 96734", docparser.Fields{}},
+		{"331 Kailua Rd, Kailua", docparser.Fields{}},
+		{"", docparser.Fields{}},
+	}
+	for _, tt := range tests {
+		f := clean.CleanAddress("a")(docparser.Fields{"a": tt.in})
+		delete(f, "a")
+		want := docparser.Fields{}
+		for k, v := range tt.want {
+			want["a_"+k] = v
+		}
+		if !reflect.DeepEqual(f, want) {
+			t.Errorf("address %q: want %v got %v", tt.in, want, f)
+		}
+	}
+}
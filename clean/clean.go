@@ -0,0 +1,125 @@
+// Package clean provides built-in Clean functions for docparser patterns
+//
+// Each cleaner is built from the key of the field it should clean and
+// can be used directly as PatternGroup.Clean or PatternList.CleanItem.
+// Importing this package also registers every cleaner by name, see
+// docparser.LookupCleaner
+package clean
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/RealGeeks/docparser"
+)
+
+func init() {
+	docparser.RegisterCleaner("phone", CleanPhone)
+	docparser.RegisterCleaner("email", CleanEmail)
+	docparser.RegisterCleaner("email_lower", CleanEmailLower)
+	docparser.RegisterCleaner("name", CleanName)
+	docparser.RegisterCleaner("address", CleanAddress)
+}
+
+// DefaultCountryCode is the calling code CleanPhone assumes for numbers
+// written without one
+var DefaultCountryCode = "1"
+
+var (
+	phoneRe    = regexp.MustCompile(`^\+?[0-9 ().\-/]+$`)
+	nonDigitRe = regexp.MustCompile(`[^0-9]`)
+)
+
+// CleanPhone normalizes the phone number in key to E.164 form, e.g.
+// "(808) 221-1122" becomes "+18082211122"
+//
+// Numbers without an international prefix ("+" or "00") get
+// DefaultCountryCode prepended. Values that don't look like a phone
+// number are left untouched
+func CleanPhone(key string) func(f docparser.Fields) docparser.Fields {
+	return CleanPhoneCountry(key, DefaultCountryCode)
+}
+
+// CleanPhoneCountry is like CleanPhone but uses country as the default
+// calling code
+//
+// If country is empty numbers without an international prefix are
+// reduced to their digits
+func CleanPhoneCountry(key, country string) func(f docparser.Fields) docparser.Fields {
+	return func(f docparser.Fields) docparser.Fields {
+		if phone, ok := normalizePhone(f.GetString(key), country); ok {
+			f[key] = phone
+		}
+		return f
+	}
+}
+
+func normalizePhone(s, country string) (string, bool) {
+	s = strings.TrimSpace(s)
+	if !phoneRe.MatchString(s) {
+		return "", false
+	}
+	digits := nonDigitRe.ReplaceAllString(s, "")
+	switch {
+	case strings.HasPrefix(s, "+"):
+	case strings.HasPrefix(digits, "00"):
+		digits = digits[2:]
+	case country == "":
+		if len(digits) < 7 || len(digits) > 15 {
+			return "", false
+		}
+		return digits, true
+	case len(digits) > 10 && strings.HasPrefix(digits, country):
+	default:
+		digits = country + digits
+	}
+	if len(digits) < 10 || len(digits) > 15 {
+		return "", false
+	}
+	return "+" + digits, true
+}
+
+var emailRe = regexp.MustCompile(`^[^@\s<>()"',;:]+@[^@\s<>()"',;:]+\.[^@\s<>()"',;:.]+$`)
+
+// CleanEmail normalizes the email address in key
+//
+// A "mailto:" prefix, angle brackets, quotes and trailing punctuation
+// are removed and the domain is lowercased, so "mailto:Bob@Site.com>"
+// becomes "Bob@site.com". Values that don't look like an email address
+// are left untouched
+func CleanEmail(key string) func(f docparser.Fields) docparser.Fields {
+	return cleanEmail(key, false)
+}
+
+// CleanEmailLower is like CleanEmail but lowercases the whole address
+func CleanEmailLower(key string) func(f docparser.Fields) docparser.Fields {
+	return cleanEmail(key, true)
+}
+
+func cleanEmail(key string, lower bool) func(f docparser.Fields) docparser.Fields {
+	return func(f docparser.Fields) docparser.Fields {
+		if email, ok := normalizeEmail(f.GetString(key), lower); ok {
+			f[key] = email
+		}
+		return f
+	}
+}
+
+func normalizeEmail(s string, lower bool) (string, bool) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimLeft(s, `<("'`)
+	if len(s) >= 7 && strings.EqualFold(s[:7], "mailto:") {
+		s = s[7:]
+	}
+	s = strings.TrimLeft(s, `<("'`)
+	s = strings.TrimRight(s, `>)"'.,;:!?`)
+	if !emailRe.MatchString(s) {
+		return "", false
+	}
+	at := strings.LastIndex(s, "@")
+	local, domain := s[:at], strings.ToLower(s[at+1:])
+	if lower {
+		local = strings.ToLower(local)
+	}
+	return local + "@" + domain, true
+}
@@ -0,0 +1,94 @@
+package clean_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+	"github.com/RealGeeks/docparser/clean"
+)
+
+func ExampleCleanPhone() {
+	pattern := &docparser.PatternGroup{
+		Name:  "Phone",
+		Regex: regexp.MustCompile(`Phone: (?P<phone>.*)`),
+		Clean: clean.CleanPhone("phone"),
+	}
+
+	fields, err := pattern.Search("Phone: (808) 221-1122")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(fields.GetString("phone"))
+	// Output:
+	// +18082211122
+}
+
+func TestCleanPhone(t *testing.T) {
+	var tests = []struct {
+		country  string
+		in, want string
+	}{
+		{"1", "(808) 221-1122", "+18082211122"},
+		{"1", "808.221.1122", "+18082211122"},
+		{"1", "+1 808 221 1122", "+18082211122"},
+		{"1", "1-808-221-1122", "+18082211122"},
+		{"1", "0044 20 7946 0958", "+442079460958"},
+		{"1", " 808 221 1122 ", "+18082211122"},
+		{"44", "20 7946 0958", "+442079460958"},
+		{"", "808-221-1122", "8082211122"},
+		{"1", "call me", "call me"},
+		{"1", "221-1122", "221-1122"},
+		{"1", "808 221 1122 ext 5", "808 221 1122 ext 5"},
+		{"1", "", ""},
+	}
+	for _, tt := range tests {
+		f := clean.CleanPhoneCountry("phone", tt.country)(docparser.Fields{"phone": tt.in})
+		if got := f.GetString("phone"); got != tt.want {
+			t.Errorf("country %q phone %q: want %q got %q", tt.country, tt.in, tt.want, got)
+		}
+	}
+}
+
+func TestCleanPhoneMissingKey(t *testing.T) {
+	f := clean.CleanPhone("phone")(docparser.Fields{"name": "bob"})
+	if _, ok := f["phone"]; ok {
+		t.Errorf("phone key should not be added: %v", f)
+	}
+}
+
+func TestCleanEmail(t *testing.T) {
+	var tests = []struct {
+		in, want, wantLower string
+	}{
+		{"mailto:Bob@Site.com>", "Bob@site.com", "bob@site.com"},
+		{"<bob@SITE.com>", "bob@site.com", "bob@site.com"},
+		{"bob@site.com.", "bob@site.com", "bob@site.com"},
+		{" \"Bob.Smith@Site.Co.UK\"; ", "Bob.Smith@site.co.uk", "bob.smith@site.co.uk"},
+		{"MAILTO:bob@site.com", "bob@site.com", "bob@site.com"},
+		{"not an email", "not an email", "not an email"},
+		{"bob@localhost", "bob@localhost", "bob@localhost"},
+		{"Bob@@Site.com", "Bob@@Site.com", "Bob@@Site.com"},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		f := clean.CleanEmail("email")(docparser.Fields{"email": tt.in})
+		if got := f.GetString("email"); got != tt.want {
+			t.Errorf("CleanEmail %q: want %q got %q", tt.in, tt.want, got)
+		}
+		f = clean.CleanEmailLower("email")(docparser.Fields{"email": tt.in})
+		if got := f.GetString("email"); got != tt.wantLower {
+			t.Errorf("CleanEmailLower %q: want %q got %q", tt.in, tt.wantLower, got)
+		}
+	}
+}
+
+func TestRegistered(t *testing.T) {
+	for _, name := range []string{"phone", "email", "email_lower", "name", "address"} {
+		if _, ok := docparser.LookupCleaner(name); !ok {
+			t.Errorf("cleaner %q not registered", name)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package clean
+
+import "github.com/RealGeeks/docparser"
+
+// CleanSeq returns a Clean function that applies fns in order, each one
+// receiving the fields returned by the previous one
+func CleanSeq(fns ...func(f docparser.Fields) docparser.Fields) func(f docparser.Fields) docparser.Fields {
+	return func(f docparser.Fields) docparser.Fields {
+		for _, fn := range fns {
+			f = fn(f)
+		}
+		return f
+	}
+}
+
+// CleanField returns a Clean function that applies fn to the string
+// value of key, i.e. CleanField("email", strings.ToLower). Missing keys
+// and values that aren't strings are left untouched
+func CleanField(key string, fn func(s string) string) func(f docparser.Fields) docparser.Fields {
+	return func(f docparser.Fields) docparser.Fields {
+		if v, ok := f[key].(string); ok {
+			f[key] = fn(v)
+		}
+		return f
+	}
+}
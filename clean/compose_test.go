@@ -0,0 +1,63 @@
+package clean_test
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+	"github.com/RealGeeks/docparser/clean"
+)
+
+func ExampleCleanSeq() {
+	pattern := &docparser.PatternGroup{
+		Name:  "Contact",
+		Regex: regexp.MustCompile(`Name: (?P<name>.*)\nEmail: (?P<email>.*)`),
+		Clean: clean.CleanSeq(
+			clean.CleanField("name", strings.TrimSpace),
+			clean.CleanField("email", strings.ToLower),
+			clean.CleanEmail("email"),
+		),
+	}
+
+	fields, err := pattern.Search("Name:  Mark Stewart \nEmail: <Mark@Site.com>")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("%q %q\n", fields.GetString("name"), fields.GetString("email"))
+	// Output:
+	// "Mark Stewart" "mark@site.com"
+}
+
+func TestCleanSeqOrder(t *testing.T) {
+	fn := clean.CleanSeq(
+		clean.CleanField("name", func(s string) string { return s + "1" }),
+		clean.CleanField("name", func(s string) string { return s + "2" }),
+	)
+	f := fn(docparser.Fields{"name": "bob"})
+	if name := f.GetString("name"); name != "bob12" {
+		t.Errorf("invalid order: %q", name)
+	}
+	if f = clean.CleanSeq()(docparser.Fields{"name": "bob"}); f.GetString("name") != "bob" {
+		t.Errorf("empty CleanSeq should not change fields: %v", f)
+	}
+}
+
+func TestCleanField(t *testing.T) {
+	fn := clean.CleanField("name", strings.ToUpper)
+	var tests = []struct {
+		in, want docparser.Fields
+	}{
+		{docparser.Fields{"name": "bob"}, docparser.Fields{"name": "BOB"}},
+		{docparser.Fields{"email": "bob"}, docparser.Fields{"email": "bob"}},
+		{docparser.Fields{"name": []string{"bob"}}, docparser.Fields{"name": []string{"bob"}}},
+	}
+	for _, tt := range tests {
+		if f := fn(tt.in); !reflect.DeepEqual(f, tt.want) {
+			t.Errorf("want %v got %v", tt.want, f)
+		}
+	}
+}
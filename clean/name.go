@@ -0,0 +1,102 @@
+package clean
+
+import (
+	"strings"
+
+	"github.com/RealGeeks/docparser"
+)
+
+// nameTitles and nameSuffixes are compared lowercase without dots
+var (
+	nameTitles = map[string]bool{
+		"mr": true, "mrs": true, "ms": true, "miss": true, "mx": true,
+		"dr": true, "prof": true, "rev": true, "sir": true,
+	}
+	nameSuffixes = map[string]bool{
+		"jr": true, "sr": true, "ii": true, "iii": true, "iv": true,
+		"v": true, "md": true, "phd": true, "esq": true,
+	}
+	nameParticles = map[string]bool{
+		"da": true, "de": true, "del": true, "della": true, "der": true,
+		"di": true, "du": true, "la": true, "le": true, "van": true,
+		"von": true,
+	}
+)
+
+// CleanName splits the full name in key into its components, stored as
+// key+"_title", key+"_first", key+"_middle", key+"_last" and
+// key+"_suffix". The full name is left untouched, and components not
+// found are not added
+//
+// Names are split with these rules, in order:
+//
+//	Leading titles (Mr, Mrs, Ms, Miss, Mx, Dr, Prof, Rev, Sir) are the title
+//	Trailing suffixes (Jr, Sr, II, III, IV, V, MD, PhD, Esq) are the suffix
+//	With a comma left, the name is "Last, First Middle"
+//	Otherwise the last word is the last name, with the lowercase
+//	particles before it (de, van, von...), the first word the first
+//	name and the words between the middle name
+//
+// Titles and suffixes are matched ignoring case and dots, and stored as
+// written, without trailing commas
+func CleanName(key string) func(f docparser.Fields) docparser.Fields {
+	return func(f docparser.Fields) docparser.Fields {
+		for component, value := range splitName(f.GetString(key)) {
+			if value != "" {
+				f[key+"_"+component] = value
+			}
+		}
+		return f
+	}
+}
+
+func splitName(name string) map[string]string {
+	words := strings.Fields(strings.Replace(name, ",", ", ", -1))
+	parts := map[string]string{}
+	var titles, suffixes []string
+	for len(words) > 1 && nameTitles[nameWord(words[0])] {
+		titles, words = append(titles, strings.TrimRight(words[0], ",")), words[1:]
+	}
+	for len(words) > 1 && nameSuffixes[nameWord(words[len(words)-1])] {
+		suffixes = append([]string{strings.TrimRight(words[len(words)-1], ",")}, suffixes...)
+		words = words[:len(words)-1]
+	}
+	parts["title"] = strings.Join(titles, " ")
+	parts["suffix"] = strings.Join(suffixes, " ")
+	if len(words) > 0 {
+		words[len(words)-1] = strings.TrimRight(words[len(words)-1], ",")
+	}
+
+	for i, word := range words {
+		if strings.HasSuffix(word, ",") && i < len(words)-1 {
+			parts["last"] = strings.TrimRight(strings.Join(words[:i+1], " "), ",")
+			words = words[i+1:]
+			if len(words) > 0 {
+				parts["first"], words = words[0], words[1:]
+			}
+			parts["middle"] = strings.Join(words, " ")
+			return parts
+		}
+	}
+
+	switch len(words) {
+	case 0:
+	case 1:
+		parts["first"] = words[0]
+	default:
+		last := len(words) - 1
+		for last > 1 && nameParticles[words[last-1]] {
+			last--
+		}
+		parts["first"] = words[0]
+		parts["middle"] = strings.Join(words[1:last], " ")
+		parts["last"] = strings.Join(words[last:], " ")
+	}
+	return parts
+}
+
+// nameWord returns word lowercase without dots or commas, to look it up
+// in nameTitles and nameSuffixes
+func nameWord(word string) string {
+	return strings.ToLower(strings.NewReplacer(".", "", ",", "").Replace(word))
+}
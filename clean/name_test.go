@@ -0,0 +1,54 @@
+package clean_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+	"github.com/RealGeeks/docparser/clean"
+)
+
+func ExampleCleanName() {
+	f := clean.CleanName("name")(docparser.Fields{"name": "Mark A. Stewart Jr."})
+
+	fmt.Println(f.GetString("name_first"))
+	fmt.Println(f.GetString("name_middle"))
+	fmt.Println(f.GetString("name_last"))
+	fmt.Println(f.GetString("name_suffix"))
+	// Output:
+	// Mark
+	// A.
+	// Stewart
+	// Jr.
+}
+
+func TestCleanName(t *testing.T) {
+	var tests = []struct {
+		in   string
+		want docparser.Fields
+	}{
+		{"Mark Stewart", docparser.Fields{"first": "Mark", "last": "Stewart"}},
+		{"  Mark   Stewart ", docparser.Fields{"first": "Mark", "last": "Stewart"}},
+		{"Dr. Jane Q Public, PhD", docparser.Fields{"title": "Dr.", "first": "Jane", "middle": "Q", "last": "Public", "suffix": "PhD"}},
+		{"Stewart, Mark A.", docparser.Fields{"first": "Mark", "middle": "A.", "last": "Stewart"}},
+		{"Stewart,Mark", docparser.Fields{"first": "Mark", "last": "Stewart"}},
+		{"Stewart, Mark, Jr", docparser.Fields{"first": "Mark", "last": "Stewart", "suffix": "Jr"}},
+		{"Ludwig van Beethoven", docparser.Fields{"first": "Ludwig", "last": "van Beethoven"}},
+		{"John Henry Smith III", docparser.Fields{"first": "John", "middle": "Henry", "last": "Smith", "suffix": "III"}},
+		{"Cher", docparser.Fields{"first": "Cher"}},
+		{"Dr", docparser.Fields{"first": "Dr"}},
+		{"", docparser.Fields{}},
+	}
+	for _, tt := range tests {
+		f := clean.CleanName("n")(docparser.Fields{"n": tt.in})
+		delete(f, "n")
+		want := docparser.Fields{}
+		for k, v := range tt.want {
+			want["n_"+k] = v
+		}
+		if !reflect.DeepEqual(f, want) {
+			t.Errorf("%q: want %v got %v", tt.in, want, f)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package docparser
+
+// CleanCase is a Clean function applied only to the Fields When
+// returns true for, see CleanWhen
+type CleanCase struct {
+	When  func(f Fields) bool
+	Clean func(f Fields) Fields
+}
+
+// CleanWhen returns a Clean function that applies the Clean of each
+// case whose When returns true, in order, i.e. to parse phones one way
+// when "country" is "US" and another when it's "CA"
+//
+// All matching cases are applied, each one receiving the fields
+// returned by the previous one, and When is called right before its
+// Clean so it sees their changes
+func CleanWhen(cases ...CleanCase) func(f Fields) Fields {
+	return func(f Fields) Fields {
+		for _, c := range cases {
+			if c.When(f) {
+				f = c.Clean(f)
+			}
+		}
+		return f
+	}
+}
+
+// FieldEquals returns a CleanCase.When function reporting whether the
+// value of key is the string value
+func FieldEquals(key, value string) func(f Fields) bool {
+	return func(f Fields) bool {
+		v, ok := f[key].(string)
+		return ok && v == value
+	}
+}
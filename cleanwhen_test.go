@@ -0,0 +1,64 @@
+package docparser_test
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+func ExampleCleanWhen() {
+	prefix := func(p string) func(f docparser.Fields) docparser.Fields {
+		return func(f docparser.Fields) docparser.Fields {
+			f["phone"] = p + f.GetString("phone")
+			return f
+		}
+	}
+	pattern := &docparser.PatternGroup{
+		Name:  "Phone",
+		Regex: regexp.MustCompile(`(?P<country>\w+): (?P<phone>.*)`),
+		Clean: docparser.CleanWhen(
+			docparser.CleanCase{When: docparser.FieldEquals("country", "US"), Clean: prefix("+1 ")},
+			docparser.CleanCase{When: docparser.FieldEquals("country", "UK"), Clean: prefix("+44 ")},
+		),
+	}
+
+	fields, err := pattern.Search("UK: 20 7946 0958")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(fields.GetString("phone"))
+	// Output:
+	// +44 20 7946 0958
+}
+
+func TestCleanWhen(t *testing.T) {
+	upper := func(key string) func(f docparser.Fields) docparser.Fields {
+		return func(f docparser.Fields) docparser.Fields {
+			f[key] = strings.ToUpper(f.GetString(key))
+			return f
+		}
+	}
+	clean := docparser.CleanWhen(
+		docparser.CleanCase{When: docparser.FieldEquals("kind", "a"), Clean: upper("kind")},
+		docparser.CleanCase{When: docparser.FieldEquals("kind", "A"), Clean: upper("name")},
+		docparser.CleanCase{When: docparser.FieldEquals("missing", ""), Clean: upper("kind")},
+	)
+	var tests = []struct {
+		in, want docparser.Fields
+	}{
+		{docparser.Fields{"kind": "a", "name": "bob"}, docparser.Fields{"kind": "A", "name": "BOB"}},
+		{docparser.Fields{"kind": "b", "name": "bob"}, docparser.Fields{"kind": "b", "name": "bob"}},
+		{docparser.Fields{"kind": "a", "missing": "", "name": "bob"}, docparser.Fields{"kind": "A", "missing": "", "name": "BOB"}},
+		{docparser.Fields{"kind": []string{"a"}}, docparser.Fields{"kind": []string{"a"}}},
+	}
+	for _, tt := range tests {
+		if got := clean(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("want %v got %v", tt.want, got)
+		}
+	}
+}
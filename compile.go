@@ -0,0 +1,246 @@
+package docparser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// CompileTemplate compiles a template describing a whole document into
+// a Document, mixing PatternGroup and PatternList, i.e.:
+//
+//	Name: {name}
+//	[Phone: {phone}]
+//	Email: {email}[ ({email_kind})]
+//
+//	Properties:
+//	{list:properties}
+//	 - MLS #{mls} / {address}
+//	{end}
+//
+// The grammar is line based, each line of the template being one of:
+//
+//	A line with {field} placeholders, compiled into a PatternGroup
+//	matching a whole line of the content, anywhere in it
+//	A line wrapped in [ and ], compiled into an Optional PatternGroup
+//	{list:name} on its own line, starting a list block. The line before
+//	it is the list header and can't have placeholders, the only line
+//	inside the block is the template of each item, and {end} on its
+//	own line closes it. It's compiled into a PatternList whose items
+//	are the lines after the header, up to a blank line, like the lists
+//	of TemplateSpec
+//	Any other line, without placeholders, is context and is ignored
+//
+// Within a line, text is matched like in TemplateRegex: literally,
+// except runs of white space that match one or more spaces or tabs, and
+// leading and trailing white space is ignored. Placeholders capture as
+// little as possible, up to the text that follows, so "{first} {last}"
+// puts the first word in first and the rest in last. [text] makes a
+// segment of a line optional, and segments can't be nested. Use {{, }},
+// [[ and ]] for literal braces and brackets. Field names follow the
+// rules of TemplateRegex and can't repeat, except in list items, which
+// have their own fields
+//
+// Return an error with the line and column, 1-based, of the first
+// problem found, or if the template has no placeholders
+func CompileTemplate(tmpl string) (*Document, error) {
+	doc := &Document{}
+	seen := map[string]bool{}
+	lines := strings.Split(tmpl, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSuffix(lines[i], "\r")
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "{end}":
+			return nil, templateError(i+1, line, strings.Index(line, "{"), "unexpected {end}")
+		case strings.HasPrefix(trimmed, "{list:"):
+			p, end, err := compileList(lines, i, seen)
+			if err != nil {
+				return nil, err
+			}
+			doc.Patterns = append(doc.Patterns, p)
+			i = end
+		default:
+			t, err := parseTemplateLine(line, i+1, seen)
+			if err != nil {
+				return nil, err
+			}
+			if len(t.names) == 0 {
+				continue
+			}
+			doc.Patterns = append(doc.Patterns, &PatternGroup{
+				Name:     fmt.Sprintf("line %d", i+1),
+				Regex:    regexp.MustCompile(`(?m)^[ \t]*` + t.regex + `[ \t]*\r?$`),
+				Optional: t.optional,
+			})
+		}
+	}
+	if len(doc.Patterns) == 0 {
+		return nil, fmt.Errorf("template has no placeholders")
+	}
+	return doc, nil
+}
+
+// compileList compiles the list block starting at lines[start],
+// returning the index of its {end} line
+func compileList(lines []string, start int, seen map[string]bool) (Pattern, int, error) {
+	line := strings.TrimSuffix(lines[start], "\r")
+	offset := strings.Index(line, "{")
+	trimmed := strings.TrimSpace(line)
+	close := strings.IndexByte(trimmed, '}')
+	if close != len(trimmed)-1 {
+		return nil, 0, templateError(start+1, line, offset, "{list:name} must be on its own line")
+	}
+	name := trimmed[len("{list:"):close]
+	if !fieldNameRe.MatchString(name) {
+		return nil, 0, templateError(start+1, line, offset, "invalid list name %q", name)
+	}
+	if seen[name] {
+		return nil, 0, templateError(start+1, line, offset, "duplicate field name %q", name)
+	}
+	seen[name] = true
+
+	if start == 0 || strings.TrimSpace(lines[start-1]) == "" {
+		return nil, 0, templateError(start+1, line, offset, "list %q has no header", name)
+	}
+	headerLine := strings.TrimSuffix(lines[start-1], "\r")
+	header, err := parseTemplateLine(headerLine, start, map[string]bool{})
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(header.names) > 0 || header.optional {
+		return nil, 0, templateError(start, headerLine, len(headerLine)-len(strings.TrimLeft(headerLine, " \t")), "list header can't have placeholders or be optional")
+	}
+
+	item, itemNum := templateLine{}, 0
+	for i := start + 1; i < len(lines); i++ {
+		line := strings.TrimSuffix(lines[i], "\r")
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			continue
+		case trimmed == "{end}":
+			if itemNum == 0 {
+				return nil, 0, templateError(i+1, line, strings.Index(line, "{"), "list %q has no item template", name)
+			}
+			return &PatternList{
+				Name:       fmt.Sprintf("line %d", start+1),
+				ListRegex:  listRegex(`(?m:^)[ \t]*`+header.regex+`[ \t]*`, name),
+				SplitRegex: regexp.MustCompile(`\n`),
+				ItemRegex:  regexp.MustCompile(`^[ \t]*` + item.regex + `[ \t]*$`),
+			}, i, nil
+		case strings.HasPrefix(trimmed, "{list:"):
+			return nil, 0, templateError(i+1, line, strings.Index(line, "{"), "lists can't be nested")
+		case itemNum != 0:
+			return nil, 0, templateError(i+1, line, len(line)-len(strings.TrimLeft(line, " \t")), "list %q has more than one item line", name)
+		}
+		if item, err = parseTemplateLine(line, i+1, map[string]bool{}); err != nil {
+			return nil, 0, err
+		}
+		if item.optional {
+			return nil, 0, templateError(i+1, line, strings.Index(line, "["), "list items can't be optional")
+		}
+		itemNum = i + 1
+	}
+	return nil, 0, templateError(start+1, line, offset, "unclosed list %q, missing {end}", name)
+}
+
+// templateLine is a line of a template compiled by parseTemplateLine
+type templateLine struct {
+	// regex matching the line, trimmed and without anchors
+	regex string
+
+	// names of its placeholders
+	names []string
+
+	// optional is set if the whole line is an optional segment, which
+	// regex doesn't include
+	optional bool
+}
+
+// parseTemplateLine compiles the line number num of a template, adding
+// the names of its placeholders to seen
+func parseTemplateLine(line string, num int, seen map[string]bool) (templateLine, error) {
+	start := len(line) - len(strings.TrimLeft(line, " \t"))
+	end := len(strings.TrimRight(line, " \t"))
+
+	t := templateLine{}
+	var parts []string
+	var literal strings.Builder
+	flush := func() {
+		if literal.Len() > 0 {
+			parts = append(parts, quoteLiteral(literal.String(), TemplateOptions{}))
+			literal.Reset()
+		}
+	}
+	open, firstOpen, firstClose := -1, -1, -1
+	for i := start; i < end; i++ {
+		c := line[i]
+		switch {
+		case (c == '{' || c == '}' || c == '[' || c == ']') && i+1 < end && line[i+1] == c:
+			literal.WriteByte(c)
+			i++
+		case c == '{':
+			close := strings.IndexByte(line[i:end], '}')
+			if close == -1 {
+				return t, templateError(num, line, i, "unclosed placeholder")
+			}
+			name := line[i+1 : i+close]
+			if !fieldNameRe.MatchString(name) {
+				return t, templateError(num, line, i, "invalid field name %q", name)
+			}
+			if seen[name] {
+				return t, templateError(num, line, i, "duplicate field name %q", name)
+			}
+			seen[name] = true
+			t.names = append(t.names, name)
+			flush()
+			parts = append(parts, capture(name, true, TemplateOptions{}))
+			i += close
+		case c == '}':
+			return t, templateError(num, line, i, "unexpected }")
+		case c == '[':
+			if open != -1 {
+				return t, templateError(num, line, i, "optional segments can't be nested")
+			}
+			if firstOpen == -1 {
+				firstOpen = len(parts)
+			}
+			open = i
+			flush()
+			parts = append(parts, `(?:`)
+		case c == ']':
+			if open == -1 {
+				return t, templateError(num, line, i, "unexpected ]")
+			}
+			open = -1
+			flush()
+			if firstClose == -1 {
+				firstClose = len(parts)
+			}
+			parts = append(parts, `)?`)
+		default:
+			literal.WriteByte(c)
+		}
+	}
+	if open != -1 {
+		return t, templateError(num, line, open, "unclosed optional segment")
+	}
+	flush()
+
+	// a line that is one optional segment makes the Pattern Optional
+	if firstOpen == 0 && firstClose == len(parts)-1 {
+		t.optional = true
+		parts = parts[1 : len(parts)-1]
+	}
+	t.regex = strings.Join(parts, "")
+	return t, nil
+}
+
+// templateError returns an error at the byte offset of the line number
+// num of a template
+func templateError(num int, line string, offset int, format string, args ...interface{}) error {
+	column := utf8.RuneCountInString(line[:offset]) + 1
+	return fmt.Errorf("line %d, column %d: %s", num, column, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,121 @@
+package docparser_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+func ExampleCompileTemplate() {
+	document, err := docparser.CompileTemplate(`New lead!
+Name: {name}
+[Phone: {phone}]
+Email: {email}[ ({email_kind})]
+
+Properties:
+{list:properties}
+ - MLS #{mls} / {address}
+{end}
+`)
+	if err != nil {
+		panic(err)
+	}
+
+	content := `New lead!
+Email: mark@site.com (work)
+Name: Mark Stewart
+
+Properties:
+ - MLS #2211 / 331 Kailua Rd, HI
+ - MLS #9090 / 990 Kaelepulu Dr, HI
+
+Thanks!
+`
+
+	fields, err := document.Search(content)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(fields.GetString("name"))
+	fmt.Printf("%q\n", fields.GetString("phone"))
+	fmt.Println(fields.GetString("email"), fields.GetString("email_kind"))
+	for _, property := range fields.GetMapSlice("properties") {
+		fmt.Printf("#%s: %s\n", property["mls"], property["address"])
+	}
+	// Output:
+	// Mark Stewart
+	// ""
+	// mark@site.com work
+	// #2211: 331 Kailua Rd, HI
+	// #9090: 990 Kaelepulu Dr, HI
+}
+
+func TestCompileTemplate(t *testing.T) {
+	document, err := docparser.CompileTemplate("  Name: {first} {last}  \r\n" +
+		"Price: [[{price}]][ {{{currency}}}]\n" +
+		"[Notes: {notes}]\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tests = []struct {
+		content string
+		want    docparser.Fields
+	}{
+		{
+			"Price: [450000] {USD}\r\nName:  Mark A. Stewart \r\nNotes: call\n",
+			docparser.Fields{"first": "Mark", "last": "A. Stewart", "price": "450000", "currency": "USD", "notes": "call"},
+		},
+		{
+			"Name: Jane Doe\nPrice: [1]\n",
+			docparser.Fields{"first": "Jane", "last": "Doe", "price": "1", "currency": ""},
+		},
+	}
+	for _, tt := range tests {
+		fields, err := document.Search(tt.content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(fields, tt.want) {
+			t.Errorf("%q: want %v got %v", tt.content, tt.want, fields)
+		}
+	}
+
+	if _, err := document.Search("Name: Jane Doe\n"); err == nil || err.Error() != `No match for "line 2"` {
+		t.Errorf("want NoMatch for line 2 got %v", err)
+	}
+}
+
+func TestCompileTemplateInvalid(t *testing.T) {
+	var tests = []struct {
+		tmpl, err string
+	}{
+		{"Name: {name", "line 1, column 7: unclosed placeholder"},
+		{"A: {a}\n  B: {1b}", `line 2, column 6: invalid field name "1b"`},
+		{"A: {a}\nB: {a}", `line 2, column 4: duplicate field name "a"`},
+		{"Caf\u00e9 } {a}", "line 1, column 6: unexpected }"},
+		{"A: {a}]", "line 1, column 7: unexpected ]"},
+		{"A: [{a} [{b}]]", "line 1, column 9: optional segments can't be nested"},
+		{"A: [{a}", "line 1, column 4: unclosed optional segment"},
+		{"{end}", "line 1, column 1: unexpected {end}"},
+		{"Items:\n{list:items} text\n{end}", "line 2, column 1: {list:name} must be on its own line"},
+		{"Items:\n{list:my items}\n{end}", `line 2, column 1: invalid list name "my items"`},
+		{"{list:items}\n{item}\n{end}", `line 1, column 1: list "items" has no header`},
+		{"Items: {n}\n{list:items}\n{item}\n{end}", "line 1, column 1: list header can't have placeholders or be optional"},
+		{"Items:\n {list:items}\n\n {end}", `line 4, column 2: list "items" has no item template`},
+		{"Items:\n{list:items}\n{a}\n{b}\n{end}", `line 4, column 1: list "items" has more than one item line`},
+		{"Items:\n{list:items}\n[{a}]\n{end}", "line 3, column 1: list items can't be optional"},
+		{"Items:\n{list:items}\n{list:more}\n{end}", "line 3, column 1: lists can't be nested"},
+		{"Items:\n{list:items}\n{a}\n", `line 2, column 1: unclosed list "items", missing {end}`},
+		{"{items}\nItems:\n{list:items}\n{a}\n{end}", `line 3, column 1: duplicate field name "items"`},
+		{"Just text\n[and more]\n", "template has no placeholders"},
+	}
+	for _, tt := range tests {
+		_, err := docparser.CompileTemplate(tt.tmpl)
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("template %q want error %q got %v", tt.tmpl, tt.err, err)
+		}
+	}
+}
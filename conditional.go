@@ -0,0 +1,31 @@
+package docparser
+
+import "regexp"
+
+// PatternConditional is a Pattern that only runs another Pattern if a
+// guard regex matches the content
+//
+// This is useful for optional sections: if the section header is
+// present all its fields are required, if it's absent no fields are
+// returned and no error
+type PatternConditional struct {
+	// Guard must match the content for Then to run
+	Guard *regexp.Regexp
+
+	// Then is searched when Guard matches, errors are returned as is
+	Then Pattern
+}
+
+// Search returns the result of Then.Search() if Guard matches content,
+// otherwise returns empty Fields
+func (pc *PatternConditional) Search(content string) (Fields, error) {
+	if !pc.Guard.MatchString(content) {
+		return Fields{}, nil
+	}
+	return pc.Then.Search(content)
+}
+
+// FieldNames, SetFields and GetFields forward to Then, see wrappedPattern
+func (pc *PatternConditional) FieldNames() []string { return wrappedPattern{pc.Then}.FieldNames() }
+func (pc *PatternConditional) SetFields(f Fields)   { wrappedPattern{pc.Then}.SetFields(f) }
+func (pc *PatternConditional) GetFields() Fields    { return wrappedPattern{pc.Then}.GetFields() }
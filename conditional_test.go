@@ -0,0 +1,79 @@
+package docparser_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+func ExamplePatternConditional() {
+	document := &docparser.Document{
+		Patterns: []docparser.Pattern{
+			&docparser.PatternGroup{
+				Name:  "Buyer",
+				Regex: regexp.MustCompile(`Buyer: (?P<buyer>.*)\n`),
+			},
+			// Co-buyer fields are only required if the section exists
+			&docparser.PatternConditional{
+				Guard: regexp.MustCompile(`Co-Buyer Information`),
+				Then: &docparser.PatternGroup{
+					Name:  "Co-Buyer",
+					Regex: regexp.MustCompile(`Co-Buyer Information\nName: (?P<cobuyer>.*)\n`),
+				},
+			},
+		},
+	}
+
+	fields, err := document.Search("Buyer: Mark\n")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%q %q\n", fields.GetString("buyer"), fields.GetString("cobuyer"))
+
+	fields, err = document.Search("Buyer: Mark\nCo-Buyer Information\nName: Jane\n")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%q %q\n", fields.GetString("buyer"), fields.GetString("cobuyer"))
+
+	_, err = document.Search("Buyer: Mark\nCo-Buyer Information\n")
+	fmt.Println(err)
+	// Output:
+	// "Mark" ""
+	// "Mark" "Jane"
+	// No match for "Co-Buyer"
+}
+
+func TestPatternConditional(t *testing.T) {
+	pattern := &docparser.PatternConditional{
+		Guard: regexp.MustCompile(`Contact:`),
+		Then:  testDocuments[0],
+	}
+	var tests = []struct {
+		content string
+		name    string
+		fails   bool
+	}{
+		{content: "Name: bob\nEmail: bob@site.com\n"},
+		{content: "Contact:\nName: bob\nEmail: bob@site.com\n", name: "bob"},
+		{content: "Contact:\nName: bob\n", fails: true},
+	}
+	for _, tt := range tests {
+		fields, err := pattern.Search(tt.content)
+		if tt.fails {
+			if _, ok := err.(*docparser.NoMatch); !ok {
+				t.Errorf("content %q want NoMatch got %#v", tt.content, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("content %q failed: %s", tt.content, err)
+			continue
+		}
+		if name := fields.GetString("name"); name != tt.name {
+			t.Errorf("content %q want name %q got %q", tt.content, tt.name, name)
+		}
+	}
+}
@@ -0,0 +1,875 @@
+package docparser
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+// PatternBuilder builds a Pattern from its declarative spec, i.e. a
+// JSON object decoded into a map
+//
+// Builders are registered by type name with RegisterPatternBuilder so
+// NewDocumentFromConfig can load Patterns of any type, including
+// custom ones
+type PatternBuilder func(spec map[string]interface{}) (Pattern, error)
+
+var (
+	buildersMu sync.RWMutex
+	builders   map[string]PatternBuilder
+)
+
+// init registers the built-in builders, it can't be done in the var
+// declaration because the builders of wrapped Patterns look up the
+// builders of their nested specs
+func init() {
+	builders = map[string]PatternBuilder{
+		"group":         buildGroup,
+		"list":          buildList,
+		"template":      buildTemplate,
+		"json":          buildJSON,
+		"keyvalue":      buildKeyValue,
+		"table":         buildTable,
+		"anyvalue":      buildAnyValue,
+		"base64":        buildBase64,
+		"between":       buildBetween,
+		"checkboxes":    buildCheckboxes,
+		"conditional":   buildConditional,
+		"count":         buildCount,
+		"fallback":      buildFallback,
+		"fieldmap":      buildFieldMap,
+		"fixedwidth":    buildFixedWidth,
+		"geo":           buildGeo,
+		"header":        buildHeader,
+		"limit":         buildLimit,
+		"markdowntable": buildMarkdownTable,
+		"outline":       buildOutline,
+		"qa":            buildQA,
+		"query":         buildQuery,
+		"range":         buildRange,
+		"scoped":        buildScoped,
+		"sections":      buildSections,
+		"signature":     buildSignature,
+	}
+}
+
+// RegisterPatternBuilder makes build available for the specs whose
+// "type" is typeName
+//
+// Registering a name twice replaces the previous PatternBuilder. Every
+// built-in Pattern is registered by default under its lowercase name
+// without the "Pattern" prefix, i.e. "group" for PatternGroup and
+// "markdowntable" for PatternMarkdownTable, plus "template" for
+// TemplateSpec
+func RegisterPatternBuilder(typeName string, build PatternBuilder) {
+	buildersMu.Lock()
+	defer buildersMu.Unlock()
+	builders[typeName] = build
+}
+
+// LookupPatternBuilder returns the PatternBuilder registered under
+// typeName
+func LookupPatternBuilder(typeName string) (PatternBuilder, bool) {
+	buildersMu.RLock()
+	defer buildersMu.RUnlock()
+	build, ok := builders[typeName]
+	return build, ok
+}
+
+// NewDocumentFromConfig builds a Document from a JSON config like:
+//
+//	{
+//	  "name": "Lead",
+//	  "patterns": [
+//	    {"type": "group", "name": "Name", "regex": "Name: (?P<name>.*)"},
+//	    {"type": "keyvalue", "labels": {"phone": ["Tel", "Cell"]}}
+//	  ]
+//	}
+//
+// Each pattern is built by the PatternBuilder registered for its
+// "type". Keys of the built-in types are the snake case names of the
+// Pattern fields, i.e. "list_regex" for PatternList.ListRegex, and
+// "cleaners" maps field names to the registered Cleaners applied to
+// them, like TemplateSpec.Cleaners. Groups and lists also accept
+// "clean_when", a list of {"field", "equals", "cleaners"} objects
+// whose cleaners are only applied when field has that value, see
+// CleanWhen. Wrapped Patterns, like PatternConditional.Then, are
+// nested specs with their own "type". Fields holding functions, like
+// PatternGroup.Validate, can't be set from a config
+//
+// Return the first error found, prefixed with the index of the
+// pattern. Unknown keys are an error, so a misspelled option isn't
+// silently ignored
+func NewDocumentFromConfig(config []byte) (*Document, error) {
+	var c struct {
+		Name     string                   `json:"name"`
+		Patterns []map[string]interface{} `json:"patterns"`
+	}
+	if err := json.Unmarshal(config, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %v", err)
+	}
+	doc := &Document{Name: c.Name, Patterns: make([]Pattern, 0, len(c.Patterns))}
+	for i, spec := range c.Patterns {
+		typeName, _ := spec["type"].(string)
+		build, ok := LookupPatternBuilder(typeName)
+		if !ok {
+			return nil, fmt.Errorf("pattern %d: unknown type %q", i, typeName)
+		}
+		p, err := build(spec)
+		if err != nil {
+			return nil, fmt.Errorf("pattern %d: %v", i, err)
+		}
+		doc.Patterns = append(doc.Patterns, p)
+	}
+	return doc, nil
+}
+
+// specReader reads typed values from a spec, keeping the first error
+// so builders can check it once at the end
+//
+// It records the keys read, so done can report the ones no builder
+// knows about
+type specReader struct {
+	spec map[string]interface{}
+	used map[string]bool
+	err  error
+}
+
+// get returns the value of key and marks it as known
+func (r *specReader) get(key string) (interface{}, bool) {
+	if r.used == nil {
+		r.used = map[string]bool{}
+	}
+	r.used[key] = true
+	v, ok := r.spec[key]
+	return v, ok
+}
+
+// done returns the first error found, or an error for the first key,
+// in alphabetical order, that wasn't read
+func (r *specReader) done() error {
+	if r.err != nil {
+		return r.err
+	}
+	keys := make([]string, 0, len(r.spec))
+	for key := range r.spec {
+		if !r.used[key] && key != "type" {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) > 0 {
+		sort.Strings(keys)
+		return fmt.Errorf("unknown key %q", keys[0])
+	}
+	return nil
+}
+
+func (r *specReader) fail(key, want string) {
+	if r.err == nil {
+		r.err = fmt.Errorf("%q must be %s", key, want)
+	}
+}
+
+func (r *specReader) string(key string) string {
+	v, ok := r.get(key)
+	if !ok {
+		return ""
+	}
+	s, ok := v.(string)
+	if !ok {
+		r.fail(key, "a string")
+	}
+	return s
+}
+
+func (r *specReader) bool(key string) bool {
+	v, ok := r.get(key)
+	if !ok {
+		return false
+	}
+	b, ok := v.(bool)
+	if !ok {
+		r.fail(key, "a boolean")
+	}
+	return b
+}
+
+func (r *specReader) int(key string) int {
+	v, ok := r.get(key)
+	if !ok {
+		return 0
+	}
+	n, ok := v.(float64)
+	if !ok || n != float64(int(n)) {
+		r.fail(key, "an integer")
+	}
+	return int(n)
+}
+
+// regex compiles the regex in key, returning nil if it's missing and
+// not required
+func (r *specReader) regex(key string, required bool) *regexp.Regexp {
+	s := r.string(key)
+	if s == "" {
+		if required {
+			r.fail(key, "a regex")
+		}
+		return nil
+	}
+	re, err := regexp.Compile(s)
+	if err != nil && r.err == nil {
+		r.err = fmt.Errorf("invalid regex %q: %v", key, err)
+	}
+	return re
+}
+
+func (r *specReader) stringMap(key string) map[string]string {
+	v, ok := r.get(key)
+	if !ok {
+		return nil
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		r.fail(key, "an object of strings")
+		return nil
+	}
+	result := make(map[string]string, len(m))
+	for k, v := range m {
+		if result[k], ok = v.(string); !ok {
+			r.fail(key, "an object of strings")
+		}
+	}
+	return result
+}
+
+func (r *specReader) stringsMap(key string) map[string][]string {
+	v, ok := r.get(key)
+	if !ok {
+		return nil
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		r.fail(key, "an object of string arrays")
+		return nil
+	}
+	result := make(map[string][]string, len(m))
+	for k, v := range m {
+		list, ok := v.([]interface{})
+		if !ok && v != nil {
+			r.fail(key, "an object of string arrays")
+		}
+		items := make([]string, 0, len(list))
+		for _, item := range list {
+			s, ok := item.(string)
+			if !ok {
+				r.fail(key, "an object of string arrays")
+			}
+			items = append(items, s)
+		}
+		result[k] = items
+	}
+	return result
+}
+
+func (r *specReader) strings(key string) []string {
+	v, ok := r.get(key)
+	if !ok {
+		return nil
+	}
+	list, ok := v.([]interface{})
+	if !ok {
+		r.fail(key, "an array of strings")
+		return nil
+	}
+	result := make([]string, 0, len(list))
+	for _, item := range list {
+		s, ok := item.(string)
+		if !ok {
+			r.fail(key, "an array of strings")
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+func (r *specReader) regexes(key string) []*regexp.Regexp {
+	var result []*regexp.Regexp
+	for _, s := range r.strings(key) {
+		re, err := regexp.Compile(s)
+		if err != nil && r.err == nil {
+			r.err = fmt.Errorf("invalid regex %q: %v", key, err)
+		}
+		result = append(result, re)
+	}
+	return result
+}
+
+func (r *specReader) regexMap(key string) map[string]*regexp.Regexp {
+	m := r.stringMap(key)
+	if m == nil {
+		return nil
+	}
+	result := make(map[string]*regexp.Regexp, len(m))
+	for k, s := range m {
+		re, err := regexp.Compile(s)
+		if err != nil && r.err == nil {
+			r.err = fmt.Errorf("invalid regex %q for %q: %v", key, k, err)
+		}
+		result[k] = re
+	}
+	return result
+}
+
+// lookupMap reads an object of objects of strings, like
+// PatternGroup.LookupFields
+func (r *specReader) lookupMap(key string) map[string]map[string]string {
+	v, ok := r.get(key)
+	if !ok {
+		return nil
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		r.fail(key, "an object of objects of strings")
+		return nil
+	}
+	result := make(map[string]map[string]string, len(m))
+	for k, v := range m {
+		table, ok := v.(map[string]interface{})
+		if !ok {
+			r.fail(key, "an object of objects of strings")
+			continue
+		}
+		result[k] = make(map[string]string, len(table))
+		for from, to := range table {
+			if result[k][from], ok = to.(string); !ok {
+				r.fail(key, "an object of objects of strings")
+			}
+		}
+	}
+	return result
+}
+
+// pattern builds the nested spec in key with the PatternBuilder
+// registered for its "type", returning nil if it's missing and not
+// required
+func (r *specReader) pattern(key string, required bool) Pattern {
+	v, ok := r.get(key)
+	if !ok {
+		if required {
+			r.fail(key, "a pattern")
+		}
+		return nil
+	}
+	spec, ok := v.(map[string]interface{})
+	if !ok {
+		r.fail(key, "a pattern")
+		return nil
+	}
+	typeName, _ := spec["type"].(string)
+	build, ok := LookupPatternBuilder(typeName)
+	if !ok {
+		if r.err == nil {
+			r.err = fmt.Errorf("%q: unknown type %q", key, typeName)
+		}
+		return nil
+	}
+	p, err := build(spec)
+	if err != nil && r.err == nil {
+		r.err = fmt.Errorf("%q: %v", key, err)
+	}
+	return p
+}
+
+// objects calls read with a specReader for each object of the array in
+// key, prefixing its errors with the index of the object
+func (r *specReader) objects(key string, read func(item *specReader)) {
+	v, ok := r.get(key)
+	if !ok {
+		return
+	}
+	list, ok := v.([]interface{})
+	if !ok {
+		r.fail(key, "an array of objects")
+		return
+	}
+	for i, v := range list {
+		spec, ok := v.(map[string]interface{})
+		if !ok {
+			r.fail(key, "an array of objects")
+			return
+		}
+		item := &specReader{spec: spec}
+		read(item)
+		if err := item.done(); err != nil {
+			if r.err == nil {
+				r.err = fmt.Errorf("%q item %d: %v", key, i, err)
+			}
+			return
+		}
+	}
+}
+
+// currency reads an object with the snake case fields of Currency,
+// returning nil if key is missing
+func (r *specReader) currency(key string) *Currency {
+	v, ok := r.get(key)
+	if !ok {
+		return nil
+	}
+	spec, ok := v.(map[string]interface{})
+	if !ok {
+		r.fail(key, "an object")
+		return nil
+	}
+	c := &specReader{spec: spec}
+	currency := &Currency{
+		Symbol:      c.string("symbol"),
+		Grouping:    c.string("grouping"),
+		Decimal:     c.string("decimal"),
+		MinorDigits: c.int("minor_digits"),
+	}
+	if err := c.done(); err != nil && r.err == nil {
+		r.err = fmt.Errorf("%q: %v", key, err)
+	}
+	return currency
+}
+
+func (r *specReader) keyCase(key string) KeyCase {
+	switch s := r.string(key); s {
+	case "", "preserve":
+		return KeyCasePreserve
+	case "lower":
+		return KeyCaseLower
+	case "upper":
+		return KeyCaseUpper
+	}
+	r.fail(key, `"preserve", "lower" or "upper"`)
+	return KeyCasePreserve
+}
+
+// clean returns a function applying the Cleaners in key, then the
+// conditional ones in whenKey, a list of objects like:
+//
+//	{"field": "country", "equals": "US", "cleaners": {"phone": ["phone"]}}
+//
+// applied as CleanWhen cases with FieldEquals
+func (r *specReader) clean(key, whenKey string) func(f Fields) Fields {
+	fn, err := cleanersFunc(r.stringsMap(key))
+	if err != nil && r.err == nil {
+		r.err = err
+	}
+	v, ok := r.get(whenKey)
+	if !ok {
+		return fn
+	}
+	list, ok := v.([]interface{})
+	if !ok {
+		r.fail(whenKey, "an array of objects")
+		return fn
+	}
+	var cases []CleanCase
+	if fn != nil {
+		cases = append(cases, CleanCase{When: func(Fields) bool { return true }, Clean: fn})
+	}
+	for i, item := range list {
+		spec, ok := item.(map[string]interface{})
+		if !ok {
+			r.fail(whenKey, "an array of objects")
+			return fn
+		}
+		when := &specReader{spec: spec}
+		field, value := when.string("field"), when.string("equals")
+		clean, err := cleanersFunc(when.stringsMap("cleaners"))
+		if when.err == nil && field == "" {
+			when.fail("field", "a field name")
+		}
+		if when.err == nil {
+			when.err = err
+		}
+		if err := when.done(); err != nil {
+			if r.err == nil {
+				r.err = fmt.Errorf("%q item %d: %v", whenKey, i, err)
+			}
+			return fn
+		}
+		if clean != nil {
+			cases = append(cases, CleanCase{When: FieldEquals(field, value), Clean: clean})
+		}
+	}
+	if len(cases) == 0 {
+		return nil
+	}
+	return CleanWhen(cases...)
+}
+
+func buildGroup(spec map[string]interface{}) (Pattern, error) {
+	r := &specReader{spec: spec}
+	p := &PatternGroup{
+		Name:      r.string("name"),
+		Regex:     r.regex("regex", true),
+		TrimSpace: r.bool("trim_space"),
+		Optional:  r.bool("optional"),
+		Clean:     r.clean("cleaners", "clean_when"),
+		Line:      r.int("line"),
+
+		IncludeFullMatch: r.bool("include_full_match"),
+		FullMatchKey:     r.string("full_match_key"),
+		EnumFields:       r.stringsMap("enum_fields"),
+		SliceFields:      r.stringMap("slice_fields"),
+		LookupFields:     r.lookupMap("lookup_fields"),
+		StrictLookup:     r.bool("strict_lookup"),
+		NumberedGroups:   r.bool("numbered_groups"),
+		OmitUnmatched:    r.bool("omit_unmatched"),
+		WholeMatchKey:    r.string("whole_match_key"),
+		Occurrence:       r.int("occurrence"),
+		LabelValues:      r.bool("label_values"),
+	}
+	return p, r.done()
+}
+
+func buildList(spec map[string]interface{}) (Pattern, error) {
+	r := &specReader{spec: spec}
+	p := &PatternList{
+		Name:               r.string("name"),
+		ListRegex:          r.regex("list_regex", true),
+		SplitRegex:         r.regex("split_regex", false),
+		SplitBefore:        r.bool("split_before"),
+		ItemRegex:          r.regex("item_regex", true),
+		CleanItem:          r.clean("cleaners", "clean_when"),
+		Defaults:           r.stringMap("defaults"),
+		LookupFields:       r.lookupMap("lookup_fields"),
+		StrictLookup:       r.bool("strict_lookup"),
+		TrimItems:          r.bool("trim_items"),
+		IncludeFullMatch:   r.bool("include_full_match"),
+		FullMatchKey:       r.string("full_match_key"),
+		SkipUnmatchedItems: r.bool("skip_unmatched_items"),
+		MaxItems:           r.int("max_items"),
+		ErrorOnMaxItems:    r.bool("error_on_max_items"),
+		OmitUnmatched:      r.bool("omit_unmatched"),
+		Optional:           r.bool("optional"),
+	}
+	return p, r.done()
+}
+
+func buildTemplate(spec map[string]interface{}) (Pattern, error) {
+	r := &specReader{spec: spec}
+	ts := &TemplateSpec{
+		Name:            r.string("name"),
+		Template:        r.string("template"),
+		List:            r.string("list"),
+		ListHeader:      r.string("list_header"),
+		Cleaners:        r.stringsMap("cleaners"),
+		ExactWhitespace: r.bool("exact_whitespace"),
+		Optional:        r.bool("optional"),
+	}
+	if err := r.done(); err != nil {
+		return nil, err
+	}
+	return ts.pattern()
+}
+
+func buildJSON(spec map[string]interface{}) (Pattern, error) {
+	r := &specReader{spec: spec}
+	p := &PatternJSON{
+		Name:     r.string("name"),
+		Regex:    r.regex("regex", false),
+		Paths:    r.stringMap("paths"),
+		Optional: r.bool("optional"),
+	}
+	return p, r.done()
+}
+
+func buildKeyValue(spec map[string]interface{}) (Pattern, error) {
+	r := &specReader{spec: spec}
+	p := &PatternKeyValue{
+		Name:        r.string("name"),
+		Labels:      r.stringsMap("labels"),
+		Separator:   r.string("separator"),
+		MaxDistance: r.int("max_distance"),
+		KeyCase:     r.keyCase("key_case"),
+		Optional:    r.bool("optional"),
+	}
+	return p, r.done()
+}
+
+func buildTable(spec map[string]interface{}) (Pattern, error) {
+	r := &specReader{spec: spec}
+	p := &PatternTable{
+		Name:         r.string("name"),
+		Header:       r.regex("header", true),
+		MinColumnGap: r.int("min_column_gap"),
+		OutputKey:    r.string("output_key"),
+		Optional:     r.bool("optional"),
+	}
+	return p, r.done()
+}
+
+func buildAnyValue(spec map[string]interface{}) (Pattern, error) {
+	r := &specReader{spec: spec}
+	p := &PatternAnyValue{
+		Name:      r.string("name"),
+		FieldName: r.string("field_name"),
+		Regexes:   r.regexes("regexes"),
+		Optional:  r.bool("optional"),
+	}
+	if r.err == nil && len(p.Regexes) == 0 {
+		r.fail("regexes", "an array of regexes")
+	}
+	return p, r.done()
+}
+
+func buildBase64(spec map[string]interface{}) (Pattern, error) {
+	r := &specReader{spec: spec}
+	p := &PatternBase64{
+		Name:      r.string("name"),
+		Regex:     r.regex("regex", true),
+		Pattern:   r.pattern("pattern", false),
+		FieldName: r.string("field_name"),
+		Optional:  r.bool("optional"),
+	}
+	return p, r.done()
+}
+
+func buildBetween(spec map[string]interface{}) (Pattern, error) {
+	r := &specReader{spec: spec}
+	p := &PatternBetween{
+		Name:      r.string("name"),
+		Start:     r.regex("start", false),
+		End:       r.regex("end", false),
+		FieldName: r.string("field_name"),
+		TrimSpace: r.bool("trim_space"),
+		Optional:  r.bool("optional"),
+	}
+	return p, r.done()
+}
+
+func buildCheckboxes(spec map[string]interface{}) (Pattern, error) {
+	r := &specReader{spec: spec}
+	p := &PatternCheckboxes{
+		Name:      r.string("name"),
+		Regex:     r.regex("regex", false),
+		Checked:   r.strings("checked"),
+		OutputKey: r.string("output_key"),
+		All:       r.bool("all"),
+		Optional:  r.bool("optional"),
+	}
+	return p, r.done()
+}
+
+func buildConditional(spec map[string]interface{}) (Pattern, error) {
+	r := &specReader{spec: spec}
+	p := &PatternConditional{
+		Guard: r.regex("guard", true),
+		Then:  r.pattern("then", true),
+	}
+	return p, r.done()
+}
+
+func buildCount(spec map[string]interface{}) (Pattern, error) {
+	r := &specReader{spec: spec}
+	p := &PatternCount{
+		Name:      r.string("name"),
+		Regex:     r.regex("regex", true),
+		FieldName: r.string("field_name"),
+		Optional:  r.bool("optional"),
+	}
+	return p, r.done()
+}
+
+func buildFallback(spec map[string]interface{}) (Pattern, error) {
+	r := &specReader{spec: spec}
+	p := &PatternFallback{
+		Primary:      r.pattern("primary", true),
+		Secondary:    r.pattern("secondary", true),
+		NoConfidence: r.bool("no_confidence"),
+	}
+	return p, r.done()
+}
+
+func buildFieldMap(spec map[string]interface{}) (Pattern, error) {
+	r := &specReader{spec: spec}
+	p := &PatternFieldMap{
+		Name:     r.string("name"),
+		Regexes:  r.regexMap("regexes"),
+		Required: r.strings("required"),
+	}
+	return p, r.done()
+}
+
+func buildFixedWidth(spec map[string]interface{}) (Pattern, error) {
+	r := &specReader{spec: spec}
+	p := &PatternFixedWidth{
+		Name:      r.string("name"),
+		Runes:     r.bool("runes"),
+		OutputKey: r.string("output_key"),
+		Optional:  r.bool("optional"),
+	}
+	r.objects("fields", func(item *specReader) {
+		p.Fields = append(p.Fields, FixedField{
+			Name:   item.string("name"),
+			Start:  item.int("start"),
+			Length: item.int("length"),
+		})
+	})
+	return p, r.done()
+}
+
+func buildGeo(spec map[string]interface{}) (Pattern, error) {
+	r := &specReader{spec: spec}
+	p := &PatternGeo{
+		Name:     r.string("name"),
+		Regex:    r.regex("regex", false),
+		LatKey:   r.string("lat_key"),
+		LonKey:   r.string("lon_key"),
+		Optional: r.bool("optional"),
+	}
+	return p, r.done()
+}
+
+func buildHeader(spec map[string]interface{}) (Pattern, error) {
+	r := &specReader{spec: spec}
+	p := &PatternHeader{
+		Name:     r.string("name"),
+		Headers:  r.stringMap("headers"),
+		Body:     r.pattern("body", false),
+		Optional: r.bool("optional"),
+	}
+	return p, r.done()
+}
+
+func buildLimit(spec map[string]interface{}) (Pattern, error) {
+	r := &specReader{spec: spec}
+	p := &PatternLimit{
+		Name:             r.string("name"),
+		MaxContentLength: r.int("max_content_length"),
+		Pattern:          r.pattern("pattern", true),
+	}
+	return p, r.done()
+}
+
+func buildMarkdownTable(spec map[string]interface{}) (Pattern, error) {
+	r := &specReader{spec: spec}
+	p := &PatternMarkdownTable{
+		Name:      r.string("name"),
+		OutputKey: r.string("output_key"),
+		Optional:  r.bool("optional"),
+	}
+	return p, r.done()
+}
+
+func buildOutline(spec map[string]interface{}) (Pattern, error) {
+	r := &specReader{spec: spec}
+	p := &PatternOutline{
+		Name:        r.string("name"),
+		ItemRegex:   r.regex("item_regex", true),
+		OutputKey:   r.string("output_key"),
+		ChildrenKey: r.string("children_key"),
+		Optional:    r.bool("optional"),
+	}
+	return p, r.done()
+}
+
+func buildQA(spec map[string]interface{}) (Pattern, error) {
+	r := &specReader{spec: spec}
+	p := &PatternQA{
+		Name:          r.string("name"),
+		QuestionRegex: r.regex("question_regex", false),
+		AnswerRegex:   r.regex("answer_regex", false),
+		PairSplit:     r.regex("pair_split", false),
+		OutputKey:     r.string("output_key"),
+		Optional:      r.bool("optional"),
+	}
+	return p, r.done()
+}
+
+func buildQuery(spec map[string]interface{}) (Pattern, error) {
+	r := &specReader{spec: spec}
+	p := &PatternQuery{
+		Name:     r.string("name"),
+		Regex:    r.regex("regex", true),
+		Keys:     r.strings("keys"),
+		Flatten:  r.bool("flatten"),
+		Optional: r.bool("optional"),
+	}
+	return p, r.done()
+}
+
+func buildRange(spec map[string]interface{}) (Pattern, error) {
+	r := &specReader{spec: spec}
+	p := &PatternRange{
+		Name:     r.string("name"),
+		Regex:    r.regex("regex", false),
+		LowKey:   r.string("low_key"),
+		HighKey:  r.string("high_key"),
+		Currency: r.currency("currency"),
+		Optional: r.bool("optional"),
+	}
+	return p, r.done()
+}
+
+func buildScoped(spec map[string]interface{}) (Pattern, error) {
+	r := &specReader{spec: spec}
+	p := &PatternScoped{
+		Name:  r.string("name"),
+		Start: r.regex("start", false),
+		End:   r.regex("end", false),
+		Inner: r.pattern("inner", true),
+	}
+	return p, r.done()
+}
+
+func buildSections(spec map[string]interface{}) (Pattern, error) {
+	r := &specReader{spec: spec}
+	p := &PatternSections{
+		Name:                  r.string("name"),
+		SectionSplit:          r.regex("section_split", false),
+		SectionPattern:        r.pattern("section_pattern", true),
+		OutputKey:             r.string("output_key"),
+		SkipUnmatchedSections: r.bool("skip_unmatched_sections"),
+		Optional:              r.bool("optional"),
+	}
+	return p, r.done()
+}
+
+func buildSignature(spec map[string]interface{}) (Pattern, error) {
+	r := &specReader{spec: spec}
+	p := &PatternSignature{
+		Name:     r.string("name"),
+		Lines:    r.int("lines"),
+		Optional: r.bool("optional"),
+	}
+	return p, r.done()
+}
+
+// cleanersFunc returns a function applying the Cleaners listed for
+// each field, field by field in alphabetical order, or nil if there are
+// none
+func cleanersFunc(cleaners map[string][]string) (func(f Fields) Fields, error) {
+	keys := make([]string, 0, len(cleaners))
+	for key := range cleaners {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var fns []func(f Fields) Fields
+	for _, key := range keys {
+		for _, name := range cleaners[key] {
+			cleaner, ok := LookupCleaner(name)
+			if !ok {
+				return nil, fmt.Errorf("unknown cleaner %q for field %q", name, key)
+			}
+			fns = append(fns, cleaner(key))
+		}
+	}
+	if len(fns) == 0 {
+		return nil, nil
+	}
+	return func(f Fields) Fields {
+		for _, fn := range fns {
+			f = fn(f)
+		}
+		return f
+	}, nil
+}
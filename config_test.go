@@ -0,0 +1,235 @@
+package docparser_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+func ExampleNewDocumentFromConfig() {
+	config := `{
+  "name": "Lead",
+  "patterns": [
+    {"type": "group", "name": "Name", "regex": "Name: (?P<name>.*)", "trim_space": true},
+    {"type": "keyvalue", "labels": {"phone": ["Tel", "Cell"]}, "optional": true}
+  ]
+}`
+	document, err := docparser.NewDocumentFromConfig([]byte(config))
+	if err != nil {
+		panic(err)
+	}
+
+	fields, err := document.Search("Name: Mark \nCell: 221-1122\n")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(fields.GetString("name"))
+	fmt.Println(fields.GetString("phone"))
+	// Output:
+	// Mark
+	// 221-1122
+}
+
+func ExampleRegisterPatternBuilder() {
+	docparser.RegisterPatternBuilder("constant", func(spec map[string]interface{}) (docparser.Pattern, error) {
+		fields := docparser.Fields{}
+		for k, v := range spec {
+			if k != "type" {
+				fields[k] = v
+			}
+		}
+		return &constantPattern{fields}, nil
+	})
+
+	document, err := docparser.NewDocumentFromConfig([]byte(`{"patterns": [{"type": "constant", "source": "config"}]}`))
+	if err != nil {
+		panic(err)
+	}
+
+	fields, err := document.Search("anything")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(fields.GetString("source"))
+	// Output:
+	// config
+}
+
+type constantPattern struct {
+	fields docparser.Fields
+}
+
+func (cp *constantPattern) Search(content string) (docparser.Fields, error) {
+	return cp.fields.Clone(), nil
+}
+
+func TestNewDocumentFromConfig(t *testing.T) {
+	config := `{"patterns": [
+  {"type": "list", "name": "Listings", "list_regex": "(?s:Listings:\\n(?P<listings>.*))",
+   "split_regex": "\\n", "item_regex": "MLS (?P<mls>\\d+)", "skip_unmatched_items": true, "max_items": 2},
+  {"type": "template", "name": "Agent", "template": "Agent: {agent}"},
+  {"type": "json", "regex": "(?s:(\\{.*\\}))", "paths": {"lead.id": "lead_id"}},
+  {"type": "table", "header": "Beds  Baths", "output_key": "rooms", "optional": true}
+]}`
+	document, err := docparser.NewDocumentFromConfig([]byte(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields, err := document.Search("Agent: Jane\n{\"lead\": {\"id\": 7}}\nListings:\nMLS 1\nfooter\nMLS 2\nMLS 3\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fields.GetString("agent") != "Jane" || fields.GetString("lead_id") != "7" {
+		t.Errorf("invalid fields %v", fields)
+	}
+	want := []map[string]string{{"mls": "1"}, {"mls": "2"}}
+	if items := fields.GetMapSlice("listings"); !reflect.DeepEqual(items, want) {
+		t.Errorf("want %v got %v", want, items)
+	}
+}
+
+func TestNewDocumentFromConfigCleanWhen(t *testing.T) {
+	config := `{"patterns": [
+  {"type": "group", "regex": "(?P<country>\\w+): (?P<name>\\w+) (?P<city>\\w+)", "cleaners": {"name": ["test_upper"]},
+   "clean_when": [
+     {"field": "country", "equals": "US", "cleaners": {"city": ["test_upper"]}},
+     {"field": "country", "equals": "CA", "cleaners": {"country": ["test_upper"]}}
+   ]}
+]}`
+	document, err := docparser.NewDocumentFromConfig([]byte(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tests = []struct {
+		content string
+		want    docparser.Fields
+	}{
+		{"US: bob kailua", docparser.Fields{"country": "US", "name": "BOB", "city": "KAILUA"}},
+		{"CA: bob toronto", docparser.Fields{"country": "CA", "name": "BOB", "city": "toronto"}},
+		{"MX: bob cancun", docparser.Fields{"country": "MX", "name": "BOB", "city": "cancun"}},
+	}
+	for _, tt := range tests {
+		fields, err := document.Search(tt.content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(fields, tt.want) {
+			t.Errorf("%q: want %v got %v", tt.content, tt.want, fields)
+		}
+	}
+}
+
+func TestNewDocumentFromConfigOptions(t *testing.T) {
+	config := `{"patterns": [
+  {"type": "group", "regex": "Status: (?P<status>\\w+) Tags: (?P<tags>.*)\\n", "include_full_match": true,
+   "lookup_fields": {"status": {"A": "Active"}}, "strict_lookup": true, "slice_fields": {"tags": ","},
+   "enum_fields": {"status": ["Active"]}},
+  {"type": "list", "list_regex": "(?s:Listings:\\n(?P<listings>.*))", "split_regex": "\\n",
+   "item_regex": "MLS (?P<mls>\\d+) ?(?P<type>\\w*)", "defaults": {"type": "house"},
+   "lookup_fields": {"type": {"c": "condo"}}, "max_items": 2, "error_on_max_items": true}
+]}`
+	document, err := docparser.NewDocumentFromConfig([]byte(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields, err := document.Search("Status: A Tags: hot, new\nListings:\nMLS 1 c\nMLS 2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := docparser.Fields{
+		"status":   "Active",
+		"tags":     []string{"hot", "new"},
+		"_match":   "Status: A Tags: hot, new\n",
+		"listings": []docparser.Fields{{"mls": "1", "type": "condo"}, {"mls": "2", "type": "house"}},
+	}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("want %v got %v", want, fields)
+	}
+	if _, err := document.Search("Status: S Tags: hot\nListings:\nMLS 1"); err == nil {
+		t.Error("want error for a value not in lookup_fields")
+	}
+	if _, err := document.Search("Status: A Tags: hot\nListings:\nMLS 1\nMLS 2\nMLS 3"); err == nil {
+		t.Error("want error for more than max_items")
+	}
+}
+
+func TestNewDocumentFromConfigBuiltins(t *testing.T) {
+	types := []string{
+		"anyvalue", "base64", "between", "checkboxes", "conditional", "count", "fallback",
+		"fieldmap", "fixedwidth", "geo", "group", "header", "json", "keyvalue", "limit", "list",
+		"markdowntable", "outline", "qa", "query", "range", "scoped", "sections", "signature",
+		"table", "template",
+	}
+	for _, typeName := range types {
+		if _, ok := docparser.LookupPatternBuilder(typeName); !ok {
+			t.Errorf("no builder for %q", typeName)
+		}
+	}
+
+	config := `{"patterns": [
+  {"type": "conditional", "guard": "Zillow", "then": {"type": "group", "regex": "Lead: (?P<lead>\\d+)"}},
+  {"type": "fallback", "primary": {"type": "group", "regex": "Price: (?P<price>\\d+)"},
+   "secondary": {"type": "count", "regex": "\\$", "field_name": "price"}},
+  {"type": "fixedwidth", "fields": [{"name": "code", "start": 0, "length": 3}], "output_key": "rows", "optional": true},
+  {"type": "range", "low_key": "low", "high_key": "high", "optional": true,
+   "currency": {"symbol": "$", "grouping": ",", "decimal": ".", "minor_digits": 0}},
+  {"type": "keyvalue", "labels": {"city": ["City"]}, "key_case": "lower", "optional": true}
+]}`
+	document, err := docparser.NewDocumentFromConfig([]byte(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields, err := document.Search("Zillow Lead: 7 for $400,000+")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fields.GetString("lead") != "7" || fields.GetString("price") != "1" || fields.GetString("_price_confidence") != "low" {
+		t.Errorf("invalid fields %v", fields)
+	}
+	if low, ok := fields["low"].(int64); !ok || low != 400000 {
+		t.Errorf("want low 400000 got %v", fields["low"])
+	}
+}
+
+func TestNewDocumentFromConfigErrors(t *testing.T) {
+	var tests = []struct {
+		config, err string
+	}{
+		{`{"patterns": [`, "failed to parse config"},
+		{`{"patterns": [{"type": "xml"}]}`, `pattern 0: unknown type "xml"`},
+		{`{"patterns": [{"regex": "x"}]}`, `pattern 0: unknown type ""`},
+		{`{"patterns": [{"type": "group"}]}`, `pattern 0: "regex" must be a regex`},
+		{`{"patterns": [{"type": "group", "regex": "("}]}`, `pattern 0: invalid regex "regex"`},
+		{`{"patterns": [{"type": "group", "regex": "x", "optional": "yes"}]}`, `pattern 0: "optional" must be a boolean`},
+		{`{"patterns": [{"type": "group", "regex": "x", "line": 1.5}]}`, `pattern 0: "line" must be an integer`},
+		{`{"patterns": [{"type": "group", "regex": "x", "cleaners": {"n": ["nope"]}}]}`, `pattern 0: unknown cleaner "nope" for field "n"`},
+		{`{"patterns": [{"type": "group", "regex": "x", "clean_when": {}}]}`, `pattern 0: "clean_when" must be an array of objects`},
+		{`{"patterns": [{"type": "group", "regex": "x", "clean_when": [{"equals": "US"}]}]}`, `pattern 0: "clean_when" item 0: "field" must be a field name`},
+		{`{"patterns": [{"type": "list", "list_regex": "x", "item_regex": "x", "clean_when": [{"field": "c", "cleaners": {"n": ["nope"]}}]}]}`, `pattern 0: "clean_when" item 0: unknown cleaner "nope" for field "n"`},
+		{`{"patterns": [{"type": "json", "paths": {"a": 1}}]}`, `pattern 0: "paths" must be an object of strings`},
+		{`{"patterns": [{"type": "keyvalue", "labels": {"a": "b"}}]}`, `pattern 0: "labels" must be an object of string arrays`},
+		{`{"patterns": [{"type": "template", "template": "{a"}]}`, "pattern 0: "},
+		{`{"patterns": [{"type": "group", "regex": "x", "lookup_field": {}}]}`, `pattern 0: unknown key "lookup_field"`},
+		{`{"patterns": [{"type": "list", "list_regex": "x", "item_regex": "x", "default": {}}]}`, `pattern 0: unknown key "default"`},
+		{`{"patterns": [{"type": "group", "regex": "x", "clean_when": [{"field": "c", "equal": "US"}]}]}`, `pattern 0: "clean_when" item 0: unknown key "equal"`},
+		{`{"patterns": [{"type": "group", "regex": "x", "lookup_fields": {"a": "b"}}]}`, `pattern 0: "lookup_fields" must be an object of objects of strings`},
+		{`{"patterns": [{"type": "conditional", "guard": "x"}]}`, `pattern 0: "then" must be a pattern`},
+		{`{"patterns": [{"type": "conditional", "guard": "x", "then": {"type": "xml"}}]}`, `pattern 0: "then": unknown type "xml"`},
+		{`{"patterns": [{"type": "limit", "pattern": {"type": "group", "regex": "("}}]}`, `pattern 0: "pattern": invalid regex "regex"`},
+		{`{"patterns": [{"type": "fixedwidth", "fields": [{"name": "a", "size": 3}]}]}`, `pattern 0: "fields" item 0: unknown key "size"`},
+		{`{"patterns": [{"type": "range", "currency": {"symbol": 1}}]}`, `pattern 0: "currency": "symbol" must be a string`},
+		{`{"patterns": [{"type": "keyvalue", "key_case": "title"}]}`, `pattern 0: "key_case" must be "preserve", "lower" or "upper"`},
+		{`{"patterns": [{"type": "anyvalue", "field_name": "a"}]}`, `pattern 0: "regexes" must be an array of regexes`},
+	}
+	for _, tt := range tests {
+		_, err := docparser.NewDocumentFromConfig([]byte(tt.config))
+		if err == nil || !strings.HasPrefix(err.Error(), tt.err) {
+			t.Errorf("%s: want error %q got %v", tt.config, tt.err, err)
+		}
+	}
+}
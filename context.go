@@ -0,0 +1,124 @@
+package docparser
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode/utf8"
+)
+
+// Context returns the lines of content around the region pattern
+// searched, with n lines before and after, i.e. to show why a pattern
+// failed while authoring it. Content is preprocessed like in Search
+//
+// If the pattern matches, the region is the match. If it doesn't, the
+// region is approximated by the line where the longest part of the
+// literal text the regex starts with is found, at least 3 bytes, i.e.
+// for `Phone number: (?P<phone>.*)` the line with "Phone num" if that's
+// all that's found. Only PatternGroup and PatternList, by its
+// ListRegex, are supported. Return nil for other patterns or if no
+// region is found
+func (d *Document) Context(content string, pattern Pattern, n int) []string {
+	content, err := d.preprocess(content)
+	if err != nil {
+		return nil
+	}
+	var re *regexp.Regexp
+	switch p := pattern.(type) {
+	case *PatternGroup:
+		re = p.Regex
+	case *PatternList:
+		re = p.ListRegex
+	default:
+		return nil
+	}
+
+	start, end := -1, -1
+	if loc := re.FindStringIndex(content); loc != nil {
+		start, end = loc[0], loc[1]
+	} else if prefix, fold := regexLiteralPrefix(re); prefix != "" {
+		minLength := 3
+		if len(prefix) < minLength {
+			minLength = len(prefix)
+		}
+		for k := len(prefix); k >= minLength && start == -1; k-- {
+			if k < len(prefix) && !utf8.RuneStart(prefix[k]) {
+				continue
+			}
+			if loc := indexPrefix(content, prefix[:k], fold); loc != nil {
+				start, end = loc[0], loc[1]
+			}
+		}
+	}
+	if start == -1 {
+		return nil
+	}
+
+	lines := strings.Split(content, "\n")
+	first := strings.Count(content[:start], "\n")
+	last := strings.Count(content[:end], "\n")
+	if end > start && content[end-1] == '\n' {
+		last-- // match includes the line break
+	}
+	from, to := first-n, last+n+1
+	if from < 0 {
+		from = 0
+	}
+	if to > len(lines) {
+		to = len(lines)
+	}
+	context := make([]string, 0, to-from)
+	for _, line := range lines[from:to] {
+		context = append(context, strings.TrimSuffix(line, "\r"))
+	}
+	return context
+}
+
+// indexPrefix returns the location of the first occurrence of prefix in
+// content, ignoring case if fold. Case folding may change the length of
+// the text, so the location is in content, not in prefix
+func indexPrefix(content, prefix string, fold bool) []int {
+	if !fold {
+		if i := strings.Index(content, prefix); i != -1 {
+			return []int{i, i + len(prefix)}
+		}
+		return nil
+	}
+	return regexp.MustCompile(`(?i)` + regexp.QuoteMeta(prefix)).FindStringIndex(content)
+}
+
+// regexLiteralPrefix returns the literal text any match of re starts
+// with, looking inside groups and past anchors, and whether it's case
+// insensitive
+func regexLiteralPrefix(re *regexp.Regexp) (prefix string, fold bool) {
+	tree, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	var b strings.Builder
+	var walk func(re *syntax.Regexp) bool // returns false to stop
+	walk = func(re *syntax.Regexp) bool {
+		switch re.Op {
+		case syntax.OpLiteral:
+			if re.Flags&syntax.FoldCase != 0 {
+				fold = true
+			}
+			b.WriteString(string(re.Rune))
+			return true
+		case syntax.OpBeginLine, syntax.OpBeginText, syntax.OpWordBoundary, syntax.OpEmptyMatch:
+			return true
+		case syntax.OpCapture:
+			return walk(re.Sub[0])
+		case syntax.OpConcat:
+			for _, sub := range re.Sub {
+				if !walk(sub) {
+					return false
+				}
+			}
+			return true
+		}
+		return false
+	}
+	walk(tree)
+	return b.String(), fold
+}
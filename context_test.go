@@ -0,0 +1,73 @@
+package docparser_test
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+const contextContent = "Lead from website\r\n" +
+	"\r\n" +
+	"Name: Mark Stewart\r\n" +
+	"Phone num.: 221-1122\r\n" +
+	"Email: mark@site.com\r\n" +
+	"\r\n" +
+	"Thanks\r\n"
+
+func ExampleDocument_Context() {
+	document := &docparser.Document{}
+	pattern := &docparser.PatternGroup{
+		Name:  "Phone",
+		Regex: regexp.MustCompile(`Phone number: (?P<phone>.*)\n`),
+	}
+
+	for _, line := range document.Context(contextContent, pattern, 1) {
+		fmt.Printf("%q\n", line)
+	}
+	// Output:
+	// "Name: Mark Stewart"
+	// "Phone num.: 221-1122"
+	// "Email: mark@site.com"
+}
+
+func TestDocumentContext(t *testing.T) {
+	document := &docparser.Document{}
+	var tests = []struct {
+		regex string
+		n     int
+		want  []string
+	}{
+		{`(?s:Name: .*Email: .*?\n)`, 0, []string{"Name: Mark Stewart", "Phone num.: 221-1122", "Email: mark@site.com"}},
+		{`Name: (?P<name>.*)`, 1, []string{"", "Name: Mark Stewart", "Phone num.: 221-1122"}},
+		{`Thanks`, 2, []string{"Email: mark@site.com", "", "Thanks", ""}},
+		{`Lead`, 0, []string{"Lead from website"}},
+		{`(?mi:^(?P<all>EMAIL address): (.*))`, 0, []string{"Email: mark@site.com"}},
+		{`(?P<x>\d+) beds`, 1, nil},
+		{`Fax: (?P<fax>.*)`, 1, nil},
+		{`Mar(?P<x>y)`, 0, []string{"Name: Mark Stewart"}},
+		{`Mo(?P<x>y)`, 0, nil},
+	}
+	for _, tt := range tests {
+		pattern := &docparser.PatternGroup{Regex: regexp.MustCompile(tt.regex)}
+		if context := document.Context(contextContent, pattern, tt.n); !reflect.DeepEqual(context, tt.want) {
+			t.Errorf("regex %q n %d want %q got %q", tt.regex, tt.n, tt.want, context)
+		}
+	}
+
+	if context := document.Context(contextContent, &docparser.PatternCount{}, 1); context != nil {
+		t.Errorf("unsupported patterns should return nil, got %q", context)
+	}
+}
+
+func TestDocumentContextFoldNonASCII(t *testing.T) {
+	document := &docparser.Document{}
+	pattern := &docparser.PatternGroup{Regex: regexp.MustCompile(`(?i)phone number: (?P<phone>\d+)`)}
+	content := "ȺȺȺȺȺȺȺȺȺȺȺȺȺ\nfoo\nPhone number: none"
+	want := []string{"foo", "Phone number: none"}
+	if context := document.Context(content, pattern, 1); !reflect.DeepEqual(context, want) {
+		t.Errorf("want %q got %q", want, context)
+	}
+}
@@ -0,0 +1,39 @@
+package docparser
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// PatternCount is a Pattern implementation that counts how many times a
+// regex matches the content, i.e. how many properties were viewed,
+// without extracting each match like PatternList
+type PatternCount struct {
+	Name  string
+	Regex *regexp.Regexp
+
+	// FieldName is the field the count is stored in, as a string. Use
+	// Fields.GetIntOr() to read it as a number
+	FieldName string
+
+	// Optional stores a count of "0" when Regex doesn't match, instead
+	// of returning NoMatch
+	Optional bool
+}
+
+// Search counts the non-overlapping matches of Regex in content
+//
+// Return NoMatch error if there are no matches and the pattern isn't
+// Optional
+func (pc *PatternCount) Search(content string) (Fields, error) {
+	n := len(pc.Regex.FindAllStringIndex(content, -1))
+	if n == 0 && !pc.Optional {
+		return Fields{}, NewNoMatch(pc.Name, content)
+	}
+	return Fields{pc.FieldName: strconv.Itoa(n)}, nil
+}
+
+// FieldNames returns FieldName
+func (pc *PatternCount) FieldNames() []string {
+	return []string{pc.FieldName}
+}
@@ -0,0 +1,55 @@
+package docparser_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+func ExamplePatternCount() {
+	pattern := &docparser.PatternCount{
+		Name:      "Properties viewed",
+		Regex:     regexp.MustCompile(`(?m:^ - MLS #\d+)`),
+		FieldName: "viewed",
+	}
+
+	content := `Properties:
+ - MLS #2211 / 331 Kailua Rd, HI
+ - MLS #9090 / 990 Kaelepulu Dr, HI
+`
+
+	fields, err := pattern.Search(content)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(fields.GetIntOr("viewed", 0))
+	// Output:
+	// 2
+}
+
+func TestPatternCount(t *testing.T) {
+	pattern := &docparser.PatternCount{
+		Name:      "Count",
+		Regex:     regexp.MustCompile(`x`),
+		FieldName: "count",
+	}
+
+	if _, err := pattern.Search("none"); err == nil || err.Error() != `No match for "Count"` {
+		t.Errorf("want NoMatch got %v", err)
+	}
+
+	pattern.Optional = true
+	for content, want := range map[string]string{"none": "0", "x": "1", "xaxbx": "3"} {
+		fields, err := pattern.Search(content)
+		if err != nil {
+			t.Errorf("content %q failed: %s", content, err)
+			continue
+		}
+		if count := fields.GetString("count"); count != want {
+			t.Errorf("content %q want count %q got %q", content, want, count)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package docparser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Currency describes how monetary amounts are written, to parse them
+// into integer minor units (i.e. cents) without float rounding errors
+type Currency struct {
+	Symbol      string // currency symbol, optional in amounts, i.e. "$"
+	Grouping    string // digit grouping separator, i.e. ","
+	Decimal     string // decimal separator, i.e. "."
+	MinorDigits int    // number of minor unit digits, i.e. 2 for cents
+}
+
+// USD is the Currency for amounts like "$1,250,000.00"
+var USD = Currency{Symbol: "$", Grouping: ",", Decimal: ".", MinorDigits: 2}
+
+// DefaultCurrency is the Currency used by Fields.GetCents()
+var DefaultCurrency = USD
+
+// ParseMinor parses the amount s into minor units, i.e. "$1,250.50"
+// gives 125050
+//
+// The currency symbol, white space and grouping separators are ignored.
+// Negative amounts are written with a leading "-" or in parentheses,
+// like "($25.00)". Return an error if s isn't a valid amount or has
+// more decimals than MinorDigits
+func (c Currency) ParseMinor(s string) (int64, error) {
+	amount := strings.TrimSpace(s)
+	negative := false
+	if strings.HasPrefix(amount, "(") && strings.HasSuffix(amount, ")") {
+		negative = true
+		amount = amount[1 : len(amount)-1]
+	}
+	if c.Symbol != "" {
+		amount = strings.Replace(amount, c.Symbol, "", 1)
+	}
+	amount = strings.TrimSpace(amount)
+	if strings.HasPrefix(amount, "-") {
+		if negative {
+			return 0, fmt.Errorf("invalid amount %q", s)
+		}
+		negative = true
+		amount = strings.TrimSpace(amount[1:])
+	}
+	if c.Symbol != "" && strings.HasPrefix(amount, c.Symbol) {
+		amount = strings.TrimSpace(amount[len(c.Symbol):])
+	}
+	if c.Grouping != "" {
+		amount = strings.Replace(amount, c.Grouping, "", -1)
+	}
+
+	units, minor := amount, ""
+	if c.Decimal != "" {
+		if i := strings.Index(amount, c.Decimal); i != -1 {
+			units, minor = amount[:i], amount[i+len(c.Decimal):]
+		}
+	}
+	if units == "" || !isDigits(units) || !isDigits(minor) || len(minor) > c.MinorDigits {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	minor += strings.Repeat("0", c.MinorDigits-len(minor))
+
+	n, err := strconv.ParseInt(units+minor, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q: %v", s, err)
+	}
+	if negative {
+		n = -n
+	}
+	return n, nil
+}
+
+// GetCents parses the amount associated with key into integer cents
+// using DefaultCurrency, see Currency.ParseMinor()
+//
+// Return an error if key is not present or its value is not a valid
+// amount
+func (f *Fields) GetCents(key string) (int64, error) {
+	return DefaultCurrency.ParseMinor(f.GetString(key))
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,72 @@
+package docparser_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+func ExampleFields_GetCents() {
+	fields := docparser.Fields{"price": "$1,250,000.00"}
+
+	cents, err := fields.GetCents("price")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(cents)
+	// Output:
+	// 125000000
+}
+
+func TestCurrencyParseMinor(t *testing.T) {
+	euro := docparser.Currency{Symbol: "€", Grouping: ".", Decimal: ",", MinorDigits: 2}
+	var tests = []struct {
+		currency docparser.Currency
+		amount   string
+		want     int64
+		fails    bool
+	}{
+		{currency: docparser.USD, amount: "$1,250,000.00", want: 125000000},
+		{currency: docparser.USD, amount: "1250000", want: 125000000},
+		{currency: docparser.USD, amount: " $ 12.5 ", want: 1250},
+		{currency: docparser.USD, amount: "$0.07", want: 7},
+		{currency: docparser.USD, amount: "-$25.00", want: -2500},
+		{currency: docparser.USD, amount: "$-25", want: -2500},
+		{currency: docparser.USD, amount: "($25.00)", want: -2500},
+		{currency: docparser.USD, amount: "25$", want: 2500},
+		{currency: euro, amount: "1.250,50 €", want: 125050},
+		{currency: euro, amount: "€1,5", want: 150},
+		{currency: docparser.USD, amount: "", fails: true},
+		{currency: docparser.USD, amount: "$", fails: true},
+		{currency: docparser.USD, amount: "$1.005", fails: true},
+		{currency: docparser.USD, amount: "$1.2.3", fails: true},
+		{currency: docparser.USD, amount: "call for price", fails: true},
+		{currency: docparser.USD, amount: "(-$25)", fails: true},
+		{currency: docparser.USD, amount: "$999,999,999,999,999,999", fails: true},
+	}
+	for _, tt := range tests {
+		got, err := tt.currency.ParseMinor(tt.amount)
+		if tt.fails {
+			if err == nil {
+				t.Errorf("amount %q should fail, got %d", tt.amount, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("amount %q failed: %s", tt.amount, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("amount %q want %d got %d", tt.amount, tt.want, got)
+		}
+	}
+}
+
+func TestFieldsGetCentsMissing(t *testing.T) {
+	f := docparser.Fields{}
+	if _, err := f.GetCents("price"); err == nil {
+		t.Error("missing key should fail")
+	}
+}
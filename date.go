@@ -0,0 +1,56 @@
+package docparser
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CommonDateLayouts are the time layouts GetTimeAny tries by default,
+// for US and ISO 8601 dates with and without a time. Numeric dates are
+// month first, as in the US
+var CommonDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"1/2/2006 3:04:05 PM",
+	"1/2/2006 3:04 PM",
+	"1/2/2006 15:04",
+	"1/2/2006",
+	"1/2/06",
+	"1-2-2006",
+	"January 2, 2006 3:04 PM",
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"Monday, January 2, 2006",
+	"Mon, Jan 2, 2006",
+	"2 January 2006",
+	"2 Jan 2006",
+	time.RFC1123Z,
+	time.RFC1123,
+}
+
+// GetTimeAny parses the date associated with key with the first of
+// layouts that accepts it, or CommonDateLayouts if none is given, for
+// dates whose format isn't known in advance
+//
+// The value is trimmed before parsing. Dates without a time zone are
+// UTC. Return an error listing all layouts tried if none accepts the
+// value, or if key is not present
+func (f *Fields) GetTimeAny(key string, layouts ...string) (time.Time, error) {
+	if !f.Has(key) {
+		return time.Time{}, fmt.Errorf("field %q not present", key)
+	}
+	if len(layouts) == 0 {
+		layouts = CommonDateLayouts
+	}
+	value := strings.TrimSpace(f.GetString(key))
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("failed to parse %q as a date for %s, tried layouts %q", value, key, layouts)
+}
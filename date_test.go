@@ -0,0 +1,72 @@
+package docparser_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RealGeeks/docparser"
+)
+
+func ExampleFields_GetTimeAny() {
+	fields := docparser.Fields{"showing": "March 14, 2024 2:30 PM", "listed": "14/03/2024"}
+
+	showing, err := fields.GetTimeAny("showing")
+	if err != nil {
+		panic(err)
+	}
+	listed, err := fields.GetTimeAny("listed", "02/01/2006", "2006-01-02")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(showing.Format(time.RFC3339))
+	fmt.Println(listed.Format("2006-01-02"))
+	// Output:
+	// 2024-03-14T14:30:00Z
+	// 2024-03-14
+}
+
+func TestFieldsGetTimeAny(t *testing.T) {
+	var tests = []struct {
+		in   string
+		want string
+	}{
+		{"2024-03-14T14:30:00-10:00", "2024-03-14T14:30:00-10:00"},
+		{"2024-03-14 14:30", "2024-03-14T14:30:00Z"},
+		{" 2024-03-14 ", "2024-03-14T00:00:00Z"},
+		{"3/14/2024", "2024-03-14T00:00:00Z"},
+		{"03/04/2024 9:05 AM", "2024-03-04T09:05:00Z"},
+		{"3/14/24", "2024-03-14T00:00:00Z"},
+		{"Mar 14, 2024", "2024-03-14T00:00:00Z"},
+		{"Thursday, March 14, 2024", "2024-03-14T00:00:00Z"},
+		{"14 Mar 2024", "2024-03-14T00:00:00Z"},
+		{"Thu, 14 Mar 2024 14:30:00 -1000", "2024-03-14T14:30:00-10:00"},
+	}
+	for _, tt := range tests {
+		f := docparser.Fields{"date": tt.in}
+		got, err := f.GetTimeAny("date")
+		if err != nil {
+			t.Errorf("%q: %s", tt.in, err)
+			continue
+		}
+		if got.Format(time.RFC3339) != tt.want {
+			t.Errorf("%q: want %s got %s", tt.in, tt.want, got.Format(time.RFC3339))
+		}
+	}
+}
+
+func TestFieldsGetTimeAnyErrors(t *testing.T) {
+	f := docparser.Fields{"date": "next week"}
+	_, err := f.GetTimeAny("date", "2006-01-02", "1/2/2006")
+	if err == nil || err.Error() != `failed to parse "next week" as a date for date, tried layouts ["2006-01-02" "1/2/2006"]` {
+		t.Errorf("invalid error %v", err)
+	}
+	if _, err := f.GetTimeAny("date"); err == nil || !strings.Contains(err.Error(), "2006-01-02") {
+		t.Errorf("error doesn't list the default layouts: %v", err)
+	}
+	if _, err := f.GetTimeAny("missing"); err == nil || err.Error() != `field "missing" not present` {
+		t.Errorf("invalid error %v", err)
+	}
+}
@@ -9,8 +9,13 @@ package docparser
 
 import (
 	"fmt"
+	"reflect"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"unicode/utf8"
 )
 
 // Pattern extracts information from a text
@@ -29,9 +34,19 @@ type PatternWithFields interface {
 	GetFields() Fields
 }
 
+// FieldNamer is implemented by Patterns that know in advance the names
+// of the fields they can return
+type FieldNamer interface {
+	FieldNames() []string
+}
+
 // Fields is the return value of Pattern.Search()
 //
-// Values could be plain strings or a list of subfields ([]map[string]string)
+// Values could be plain strings, a list of strings ([]string) or a list
+// of subfields ([]Fields). Some Patterns store other values, like numbers
+// from PatternGeo, and the items of a []Fields may hold non-string
+// values too, like the "checked" bool of PatternCheckboxes or the nested
+// []Fields children of PatternOutline
 //
 // The functions GetString() and GetMapSlice() handle the type casting
 // and return primitive types
@@ -46,6 +61,12 @@ func (f *Fields) Update(other Fields) {
 	}
 }
 
+// Has reports whether key is present in f, even if its value is empty
+func (f *Fields) Has(key string) bool {
+	_, ok := (*f)[key]
+	return ok
+}
+
 func (f *Fields) Keys() []string {
 	keys := make([]string, 0, len(*f))
 	for k, _ := range *f {
@@ -70,10 +91,49 @@ func (f *Fields) GetString(key string) (value string) {
 	return vs
 }
 
+// GetStringOr is like GetString but returns def instead of an empty
+// string
+func (f *Fields) GetStringOr(key, def string) string {
+	if v := f.GetString(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// GetIntOr returns the string value associated with key parsed as an
+// integer
+//
+// Return def if key is not present or its value is not a string
+// holding an integer
+func (f *Fields) GetIntOr(key string, def int) int {
+	i, err := strconv.Atoi(strings.TrimSpace(f.GetString(key)))
+	if err != nil {
+		return def
+	}
+	return i
+}
+
+// GetBoolOr returns the string value associated with key parsed with
+// strconv.ParseBool
+//
+// Return def if key is not present or its value is not a string
+// holding a boolean
+func (f *Fields) GetBoolOr(key string, def bool) bool {
+	b, err := strconv.ParseBool(strings.TrimSpace(f.GetString(key)))
+	if err != nil {
+		return def
+	}
+	return b
+}
+
 // GetMapSlice return a slice of subfields associated with key
 //
 // Return empty slice if key is not present or if key
 // is present but the value is not a slice of Fields
+//
+// Subfields that aren't strings are formatted with fmt.Sprint, i.e. a
+// bool gives "true", and nested slices and maps, like the children of
+// PatternOutline items, are left out. Use Flatten to get them too
 func (f *Fields) GetMapSlice(key string) []map[string]string {
 	v, ok := (*f)[key]
 	if !ok {
@@ -87,23 +147,228 @@ func (f *Fields) GetMapSlice(key string) []map[string]string {
 	for i, item := range vf {
 		vs[i] = make(map[string]string)
 		for key, val := range item {
-			vs[i][key] = val.(string)
+			if s, ok := scalarString(val); ok {
+				vs[i][key] = s
+			}
 		}
 	}
 	return vs
 
 }
 
+// scalarString returns v if it's a string, or v formatted with
+// fmt.Sprint if it's another scalar like a bool or a number. ok is
+// false for nil, slices, maps and other composite values
+func scalarString(v interface{}) (s string, ok bool) {
+	if s, ok := v.(string); ok {
+		return s, true
+	}
+	if v == nil {
+		return "", false
+	}
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct, reflect.Ptr, reflect.Interface, reflect.Func, reflect.Chan:
+		return "", false
+	}
+	return fmt.Sprint(v), true
+}
+
+// Flatten returns all values in f, including nested Fields and slices,
+// as a flat map where keys are paths joined by sep, i.e. with sep "."
+// the first item of the list "properties" gives "properties.0.mls"
+//
+// Values that aren't strings are formatted with fmt.Sprint
+func (f *Fields) Flatten(sep string) map[string]string {
+	flat := make(map[string]string)
+	for k, v := range *f {
+		flattenValue(flat, k, sep, v)
+	}
+	return flat
+}
+
+func flattenValue(flat map[string]string, path, sep string, v interface{}) {
+	switch vv := v.(type) {
+	case string:
+		flat[path] = vv
+	case Fields:
+		for k, item := range vv {
+			flattenValue(flat, path+sep+k, sep, item)
+		}
+	case map[string]string:
+		for k, item := range vv {
+			flat[path+sep+k] = item
+		}
+	case []Fields:
+		for i, item := range vv {
+			flattenValue(flat, path+sep+strconv.Itoa(i), sep, item)
+		}
+	case []map[string]string:
+		for i, item := range vv {
+			flattenValue(flat, path+sep+strconv.Itoa(i), sep, item)
+		}
+	case []string:
+		for i, item := range vv {
+			flat[path+sep+strconv.Itoa(i)] = item
+		}
+	default:
+		flat[path] = fmt.Sprint(vv)
+	}
+}
+
+// TruncateRunes truncates the string value of key to at most n runes,
+// so the result is still valid UTF-8, i.e. to fit a fixed width column.
+// Values that aren't strings are left as is
+func (f *Fields) TruncateRunes(key string, n int) {
+	v, ok := (*f)[key].(string)
+	if !ok {
+		return
+	}
+	for i := range v {
+		if n == 0 {
+			(*f)[key] = v[:i]
+			return
+		}
+		n--
+	}
+}
+
+// Subset returns new Fields with only the keys of f that are present,
+// i.e. to forward a different projection of the results to each
+// consumer
+//
+// It's a shallow copy, nested []Fields and other slices and maps are
+// shared with f, see Clone
+func (f *Fields) Subset(keys ...string) Fields {
+	subset := make(Fields, len(keys))
+	for _, key := range keys {
+		if v, ok := (*f)[key]; ok {
+			subset[key] = v
+		}
+	}
+	return subset
+}
+
+// Clone returns a deep copy of f
+//
+// Nested Fields, maps and slices are copied recursively so the copy can
+// be modified without affecting f. Strings are immutable so they're
+// shared, as are values of other types
+func (f *Fields) Clone() Fields {
+	if *f == nil {
+		return nil
+	}
+	clone := make(Fields, len(*f))
+	for k, v := range *f {
+		clone[k] = cloneValue(v)
+	}
+	return clone
+}
+
+func cloneValue(v interface{}) interface{} {
+	switch vv := v.(type) {
+	case Fields:
+		return vv.Clone()
+	case map[string]string:
+		return cloneMap(vv)
+	case []Fields:
+		if vv == nil {
+			return vv
+		}
+		items := make([]Fields, len(vv))
+		for i, item := range vv {
+			items[i] = item.Clone()
+		}
+		return items
+	case []map[string]string:
+		if vv == nil {
+			return vv
+		}
+		items := make([]map[string]string, len(vv))
+		for i, item := range vv {
+			items[i] = cloneMap(item)
+		}
+		return items
+	case []string:
+		if vv == nil {
+			return vv
+		}
+		return append([]string{}, vv...)
+	}
+	return v
+}
+
+func cloneMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	clone := make(map[string]string, len(m))
+	for k, v := range m {
+		clone[k] = v
+	}
+	return clone
+}
+
 // NoMatch error returned when Pattern.Search() fails to match
 type NoMatch struct {
 	Name    string // pattern name that didn't match
-	Content string // content the pattern tried to match against
+	Content string // content the pattern tried to match against, see NoMatchContentLength
 }
 
 func (e *NoMatch) Error() string {
 	return fmt.Sprintf("No match for %q", e.Name)
 }
 
+// NoMatchContentLength is the maximum length in bytes of the Content
+// stored in NoMatch errors, so errors kept around or shipped to external
+// monitoring don't carry whole emails. Set it to 0 to keep the full
+// content, i.e. to inspect it in process
+var NoMatchContentLength = 256
+
+// NewNoMatch returns a NoMatch error for the pattern name, with content
+// passed through the redactor set with SetRedactor and truncated to
+// NoMatchContentLength
+func NewNoMatch(name, content string) *NoMatch {
+	return &NoMatch{Name: name, Content: truncate(redact(content), NoMatchContentLength)}
+}
+
+var (
+	redactorMu sync.RWMutex
+	redactor   func(s string) string
+)
+
+// SetRedactor sets the function used to mask PII, like emails and phone
+// numbers, in content kept by errors: NoMatch.Content and the messages
+// of InvalidField and ErrorList. Since errors can be nested the function
+// may be applied more than once to the same text, so it should leave
+// already masked text unchanged. A nil function, the default, leaves
+// content as is
+func SetRedactor(f func(s string) string) {
+	redactorMu.Lock()
+	defer redactorMu.Unlock()
+	redactor = f
+}
+
+func redact(s string) string {
+	redactorMu.RLock()
+	defer redactorMu.RUnlock()
+	if redactor == nil {
+		return s
+	}
+	return redactor(s)
+}
+
+// truncate returns the first n bytes of s, without splitting a UTF-8
+// encoded rune. If n is 0 s is returned as is
+func truncate(s string, n int) string {
+	if n <= 0 || len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
 // Document is a collection of Patterns
 //
 // Each Pattern extracts a subset of fields from the content
@@ -111,30 +376,486 @@ func (e *NoMatch) Error() string {
 // extractions
 //
 // Document also implements the Pattern interface
-type Document []Pattern
+type Document struct {
+	// Name is a user-friendly identification used for debugging.
+	Name string
+
+	// Patterns run in order against the content.
+	Patterns []Pattern
+
+	// Preprocess functions are applied in order to the content
+	// before any Pattern runs, i.e. to strip HTML tags. Optional, but
+	// NormalizeLineEndings is recommended.
+	Preprocess []Preprocessor
+
+	// AccumulateKeys lists the fields that are collected instead of
+	// overridden when more than one Pattern returns them. On the
+	// first collision the value becomes a []string with all values
+	// in Pattern order, or a []interface{} if any of them isn't a
+	// string, i.e. a number from PatternGeo. Fields found by a single
+	// Pattern keep their value as is. Optional.
+	AccumulateKeys []string
+
+	// Finalize is called once with the fields of all Patterns, after
+	// every Pattern ran and cleaned its own fields, to derive fields
+	// from several others, i.e. to join "first" and "last" into
+	// "name". Returning an error fails the Search. Optional.
+	Finalize func(f Fields) (Fields, error)
+
+	// RecordSplit splits the content into records for SearchAll, the
+	// same way PatternList.SplitRegex splits a list. Optional, without
+	// it the whole content is a single record.
+	RecordSplit *regexp.Regexp
+
+	// SkipIf rules out the Document when it matches the content, i.e.
+	// to skip a layout when a marker of another similar layout is
+	// present. It's checked after Preprocess and before any Pattern
+	// runs, returning a NoMatch error without running them. Optional.
+	SkipIf *regexp.Regexp
+
+	// QuickMatch is a cheap test that content must pass for the
+	// Document to be searched, i.e. a distinctive phrase of the layout,
+	// so Documents.Search can rule out most Documents of a large set
+	// without running their Patterns. It's checked on the raw content,
+	// before Preprocess, returning a NoMatch error if it doesn't match.
+	// Optional, without it the Document is always searched
+	QuickMatch *regexp.Regexp
 
+	// RequiredFields must be present and not empty after all Patterns
+	// matched and Finalize ran, otherwise Search returns a NoMatch
+	// error so Documents.Search moves on to the next Document. It's the
+	// way to require a field an Optional Pattern, or an optional group,
+	// may leave absent or empty. Values are empty if they're "" or an
+	// empty slice or map. Optional.
+	RequiredFields []string
+
+	// MinMatches makes Search lenient: Patterns that return NoMatch are
+	// skipped instead of failing the Search, which succeeds with the
+	// fields found if at least MinMatches Patterns returned some, i.e.
+	// 1 to grab whatever is there. Optional Patterns that found nothing
+	// don't count, and errors other than NoMatch still fail the Search.
+	// Finalize and RequiredFields apply as usual, so RequiredFields can
+	// still demand the fields that matter. Zero, the default, requires
+	// every Pattern to match
+	MinMatches int
+}
+
+// Preprocessor transforms the content before a Document searches it
+type Preprocessor func(content string) (string, error)
+
+// NewDocument returns a Document with patterns and no other options
+//
+// Document used to be a []Pattern, so NewDocument(p1, p2) replaces the
+// old Document{p1, p2} literal when migrating
+func NewDocument(patterns ...Pattern) *Document {
+	return &Document{Patterns: patterns}
+}
+
+// Search content with all Patterns, in order, and merge their fields
+//
+// Empty content isn't special: every Pattern searches it as usual, so
+// non-Optional Patterns return NoMatch and Optional ones empty Fields,
+// unless their regex matches the empty string. Content with only white
+// space isn't treated as empty either, use SkipIf with `^\s*$` to rule
+// out blank content before any Pattern runs
 func (d *Document) Search(content string) (Fields, error) {
+	return d.search(content, nil)
+}
+
+// SearchAll splits content into records with RecordSplit and searches
+// each one with the whole Document, for content carrying several
+// independent records, returning one Fields for each record in order
+//
+// Preprocess and SkipIf run once, before splitting. Records that are
+// empty or only white space are ignored. Return an error, prefixed with
+// the record index, if any record doesn't match
+func (d *Document) SearchAll(content string) ([]Fields, error) {
+	content, err := d.preprocess(content)
+	if err != nil {
+		return nil, err
+	}
+	records := []string{content}
+	if d.RecordSplit != nil {
+		records = d.RecordSplit.Split(content, -1)
+	}
+	all := []Fields{}
+	for i, record := range records {
+		if strings.TrimSpace(record) == "" {
+			continue
+		}
+		f, err := d.searchPatterns(record, nil)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %s", i, err.Error())
+		}
+		all = append(all, f)
+	}
+	return all, nil
+}
+
+// search runs the Document, calling observe if it's not nil with each
+// Pattern and the fields it returned, nil if MinMatches skipped it
+func (d *Document) search(content string, observe func(p Pattern, f Fields)) (Fields, error) {
+	content, err := d.preprocess(content)
+	if err != nil {
+		return Fields{}, err
+	}
+	return d.searchPatterns(content, observe)
+}
+
+// preprocess checks QuickMatch, applies Preprocess to content and
+// checks SkipIf
+func (d *Document) preprocess(content string) (string, error) {
+	if d.QuickMatch != nil && !d.QuickMatch.MatchString(content) {
+		return "", NewNoMatch(d.Name+" - quick match", content)
+	}
+	for _, pre := range d.Preprocess {
+		var err error
+		if content, err = pre(content); err != nil {
+			return "", err
+		}
+	}
+	if d.SkipIf != nil && d.SkipIf.MatchString(content) {
+		return "", NewNoMatch(d.Name+" - skipped", content)
+	}
+	return content, nil
+}
+
+// searchPatterns runs the Patterns on content that is already
+// preprocessed
+func (d *Document) searchPatterns(content string, observe func(p Pattern, f Fields)) (Fields, error) {
+	if len(d.Patterns) == 1 && d.MinMatches == 0 {
+		return d.searchOne(content, observe)
+	}
 	f := Fields{}
-	for _, p := range *d {
+	matched := 0
+	for _, p := range d.Patterns {
 		if withFields, ok := p.(PatternWithFields); ok {
 			withFields.SetFields(f)
 		}
 		pf, err := p.Search(content)
+		if _, ok := err.(*NoMatch); ok && d.MinMatches > 0 {
+			if observe != nil {
+				observe(p, nil)
+			}
+			continue
+		}
 		if err != nil {
 			return Fields{}, err
 		}
-		f.Update(pf)
+		if pf == nil {
+			pf = Fields{}
+		}
+		if len(pf) > 0 {
+			matched++
+		}
+		if observe != nil {
+			observe(p, pf)
+		}
+		d.merge(f, pf)
+	}
+	if matched < d.MinMatches {
+		return Fields{}, NewNoMatch(d.Name+" - min matches", content)
+	}
+	return d.finalize(f, content)
+}
+
+// searchOne is the fast path of search for a Document with a single
+// Pattern, returning its fields as is since there's nothing to merge
+func (d *Document) searchOne(content string, observe func(p Pattern, f Fields)) (Fields, error) {
+	p := d.Patterns[0]
+	if withFields, ok := p.(PatternWithFields); ok {
+		withFields.SetFields(Fields{})
+	}
+	f, err := p.Search(content)
+	if err != nil {
+		return Fields{}, err
+	}
+	if f == nil {
+		f = Fields{}
+	}
+	if observe != nil {
+		observe(p, f)
+	}
+	return d.finalize(f, content)
+}
+
+// finalize applies Finalize to the fields found in content and checks
+// RequiredFields
+func (d *Document) finalize(f Fields, content string) (Fields, error) {
+	if d.Finalize != nil {
+		var err error
+		if f, err = d.Finalize(f); err != nil {
+			return Fields{}, err
+		}
+	}
+	for _, key := range d.RequiredFields {
+		if isEmptyValue(f[key]) {
+			return Fields{}, NewNoMatch(d.Name+" - "+key, content)
+		}
 	}
 	return f, nil
 }
 
+// isEmptyValue reports whether v is nil, "" or an empty slice or map
+func isEmptyValue(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		return rv.Len() == 0
+	}
+	return false
+}
+
+// merge updates f with other, accumulating the values of AccumulateKeys
+// into new slices so other, returned by a Pattern, isn't modified
+func (d *Document) merge(f, other Fields) {
+	accumulated := Fields{}
+	for _, key := range d.AccumulateKeys {
+		prev, ok := f[key]
+		if !ok {
+			continue
+		}
+		v, ok := other[key]
+		if !ok {
+			continue
+		}
+		ps, pok := stringValues(prev)
+		vs, vok := stringValues(v)
+		if pok && vok {
+			accumulated[key] = append(ps, vs...)
+		} else {
+			accumulated[key] = appendValues(appendValues(nil, prev), v)
+		}
+	}
+	f.Update(other)
+	f.Update(accumulated)
+}
+
+// stringValues returns v as a new []string if it's a string or []string
+func stringValues(v interface{}) ([]string, bool) {
+	switch vs := v.(type) {
+	case string:
+		return []string{vs}, true
+	case []string:
+		return append([]string{}, vs...), true
+	}
+	return nil, false
+}
+
+// appendValues appends v to values, or the items of v if it's a
+// []string or []interface{} of values accumulated before
+func appendValues(values []interface{}, v interface{}) []interface{} {
+	switch vs := v.(type) {
+	case []string:
+		for _, item := range vs {
+			values = append(values, item)
+		}
+		return values
+	case []interface{}:
+		return append(values, vs...)
+	}
+	return append(values, v)
+}
+
+// FieldNames returns the names of all fields the Patterns can return,
+// for Patterns that implement FieldNamer
+func (d *Document) FieldNames() []string {
+	seen := map[string]bool{}
+	names := []string{}
+	for _, p := range d.Patterns {
+		if namer, ok := p.(FieldNamer); ok {
+			for _, name := range namer.FieldNames() {
+				if !seen[name] {
+					seen[name] = true
+					names = append(names, name)
+				}
+			}
+		}
+	}
+	return names
+}
+
+// Validate checks the Document definition for mistakes
+//
+// For now it reports the fields returned by more than one Pattern,
+// which would silently override each other, unless they're listed in
+// AccumulateKeys. Only Patterns that implement FieldNamer are checked.
+// Return nil or an ErrorList with one error for each duplicate field
+func (d *Document) Validate() error {
+	errList := &ErrorList{}
+	found := map[string]string{}
+	for i, p := range d.Patterns {
+		namer, ok := p.(FieldNamer)
+		if !ok {
+			continue
+		}
+		desc := describePattern(i, p)
+		for _, name := range namer.FieldNames() {
+			if prev, ok := found[name]; ok && !d.accumulates(name) {
+				errList.Add(fmt.Errorf("field %q returned by %s and %s", name, prev, desc))
+				continue
+			}
+			found[name] = desc
+		}
+	}
+	if len(*errList) > 0 {
+		return errList
+	}
+	return nil
+}
+
+func (d *Document) accumulates(key string) bool {
+	for _, k := range d.AccumulateKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// describePattern identifies the i-th pattern of a Document in errors,
+// including its Name if it has one
+func describePattern(i int, p Pattern) string {
+	v := reflect.ValueOf(p)
+	if v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Kind() == reflect.Struct {
+		if name := v.Elem().FieldByName("Name"); name.Kind() == reflect.String && name.String() != "" {
+			return fmt.Sprintf("pattern %d (%q)", i, name.String())
+		}
+	}
+	return fmt.Sprintf("pattern %d", i)
+}
+
+// splitFields splits the string value of each key in delimiters on its
+// delimiter, replacing it with a []string
+func splitFields(fields Fields, delimiters map[string]string) {
+	for key, delimiter := range delimiters {
+		value, ok := fields[key].(string)
+		if !ok {
+			continue
+		}
+		items := []string{}
+		for _, item := range strings.Split(value, delimiter) {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		fields[key] = items
+	}
+}
+
+// lookupFields translates the values of each key in tables, returning an
+// InvalidField error for unknown values if strict
+func lookupFields(name string, fields Fields, tables map[string]map[string]string, strict bool) error {
+	for key, table := range tables {
+		lookup := func(value string) (string, error) {
+			if label, ok := table[value]; ok || value == "" {
+				return label, nil
+			}
+			if strict {
+				return "", &InvalidField{name, key, value, fmt.Errorf("unknown value %q", value)}
+			}
+			return value, nil
+		}
+		var err error
+		switch v := fields[key].(type) {
+		case string:
+			fields[key], err = lookup(v)
+		case []string:
+			for i := range v {
+				if v[i], err = lookup(v[i]); err != nil {
+					break
+				}
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InvalidField error returned when a field value fails validation
+type InvalidField struct {
+	Name  string // pattern name
+	Field string // field name
+	Value string // invalid value
+	Err   error  // validation error
+}
+
+func (e *InvalidField) Error() string {
+	return redact(fmt.Sprintf("Invalid field %q for %q: %v", e.Field, e.Name, e.Err))
+}
+
+// validateFields checks fields against the allowed values in enums and
+// the validate functions, in alphabetical order of field names. Each
+// item of []string values is checked. Fields not present or empty, like
+// optional groups that didn't match, are not checked
+func validateFields(name string, fields Fields, enums map[string][]string, validate map[string]func(string) error) error {
+	keys := make([]string, 0, len(enums))
+	for key := range enums {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, value := range fieldValues(fields, key) {
+			allowed := false
+			for _, v := range enums[key] {
+				if v == value {
+					allowed = true
+					break
+				}
+			}
+			if !allowed {
+				return &InvalidField{name, key, value, fmt.Errorf("%q is not one of %q", value, enums[key])}
+			}
+		}
+	}
+
+	keys = keys[:0]
+	for key := range validate {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, value := range fieldValues(fields, key) {
+			if err := validate[key](value); err != nil {
+				return &InvalidField{name, key, value, err}
+			}
+		}
+	}
+	return nil
+}
+
+// fieldValues returns the non empty string values of key, which can be
+// a string or a []string
+func fieldValues(fields Fields, key string) []string {
+	var values []string
+	switch v := fields[key].(type) {
+	case string:
+		values = []string{v}
+	case []string:
+		values = v
+	}
+	nonEmpty := values[:0:0]
+	for _, v := range values {
+		if v != "" {
+			nonEmpty = append(nonEmpty, v)
+		}
+	}
+	return nonEmpty
+}
+
 // Documents ia a colletion of Document
 type Documents []*Document
 
 // Search each Document for content and return the first successfull return
 // value
 //
-// Will try all documents, if all failed return an ErrorList with all errors
+// Will try all documents, if all failed return an ErrorList with all errors.
+// That's the case for empty content unless a Document only has Optional
+// Patterns, see Document.Search
 func (ds *Documents) Search(content string) (Fields, error) {
 	errList := &ErrorList{}
 	for i, doc := range *ds {
@@ -147,20 +868,60 @@ func (ds *Documents) Search(content string) (Fields, error) {
 	return Fields{}, errList
 }
 
+// Preprocess adds pre before the Preprocess functions of every Document,
+// i.e. to decode content that is encoded the same way regardless of
+// which Document will match it
+func (ds *Documents) Preprocess(pre ...Preprocessor) {
+	for _, doc := range *ds {
+		doc.Preprocess = append(append([]Preprocessor{}, pre...), doc.Preprocess...)
+	}
+}
+
 type ErrorList []error
 
 func (el *ErrorList) Add(err error) {
 	(*el) = append((*el), err)
 }
 
+// Error joins the messages of all errors, passing each one through the
+// redactor set with SetRedactor
 func (el *ErrorList) Error() string {
 	s := make([]string, 0, len(*el))
 	for _, err := range *el {
-		s = append(s, err.Error())
+		s = append(s, redact(err.Error()))
 	}
 	return strings.Join(s, "; ")
 }
 
+// Cleaner builds a Clean function that operates on the field key
+//
+// Cleaners are registered by name with RegisterCleaner so documents
+// described by configuration can refer to them
+type Cleaner func(key string) func(f Fields) Fields
+
+var (
+	cleanersMu sync.RWMutex
+	cleaners   = map[string]Cleaner{}
+)
+
+// RegisterCleaner makes c available under name
+//
+// Registering a name twice replaces the previous Cleaner. The built-in
+// cleaners from the clean subpackage register themselves when imported
+func RegisterCleaner(name string, c Cleaner) {
+	cleanersMu.Lock()
+	defer cleanersMu.Unlock()
+	cleaners[name] = c
+}
+
+// LookupCleaner returns the Cleaner registered under name
+func LookupCleaner(name string) (Cleaner, bool) {
+	cleanersMu.RLock()
+	defer cleanersMu.RUnlock()
+	c, ok := cleaners[name]
+	return c, ok
+}
+
 //
 // Pattern implementations
 //
@@ -179,12 +940,107 @@ type PatternGroup struct {
 	// version. Optional.
 	Clean func(f Fields) Fields
 
+	// CleanCtx is like Clean but also receives the whole content
+	// given to Search(), for cleanups that need context from
+	// elsewhere in the document. If set Clean is ignored. Optional.
+	CleanCtx func(f Fields, content string) Fields
+
+	// TrimSpace removes leading and trailing white space from all
+	// captured values. It's applied before Clean or CleanCtx, so they
+	// receive trimmed values
+	TrimSpace bool
+
 	// Optional means that if the Regex doesn't match the content
 	// given to Search() no error will be returned, just an empty
 	// Fields
 	//
 	// This could simplify the regex
 	Optional bool
+
+	// IncludeFullMatch stores the whole text matched by Regex in
+	// Fields, under FullMatchKey or "_match" if it's empty. It's not
+	// affected by TrimSpace but Clean receives it, i.e. for auditing
+	IncludeFullMatch bool
+	FullMatchKey     string
+
+	// Fold makes Regex match against a folded copy of the content,
+	// while values are still taken from the original content, i.e.
+	// to ignore diacritics but keep them in the captured values.
+	// Optional, see Folder
+	Fold Folder
+
+	// EnumFields maps field names to their allowed values, i.e. to
+	// detect when a sender adds a new status. Search returns an
+	// InvalidField error when a value is not allowed. Empty values
+	// aren't checked. Optional.
+	EnumFields map[string][]string
+
+	// Validate maps field names to functions that check their value,
+	// returning an error if it's invalid, which Search returns as an
+	// InvalidField error. Optional.
+	//
+	// Validation happens after Clean and SliceFields, checking each
+	// item of sliced fields, and only rejects values, it never
+	// transforms them
+	Validate map[string]func(value string) error
+
+	// SliceFields maps field names to a delimiter, for groups whose
+	// value is itself a list, i.e. "Tags: hot, verified". The value is
+	// split on the delimiter after Clean and stored as a []string, with
+	// each item trimmed and empty items dropped. Optional.
+	SliceFields map[string]string
+
+	// LookupFields maps field names to a table that translates their
+	// value, i.e. "status": {"A": "Active", "S": "Sold"}. Lookup
+	// happens after Clean and SliceFields, translating each item of
+	// sliced fields. Values not in the table are left unchanged unless
+	// StrictLookup is set. Optional.
+	LookupFields map[string]map[string]string
+
+	// StrictLookup makes Search return an InvalidField error for values
+	// not in their LookupFields table. Empty values aren't looked up
+	StrictLookup bool
+
+	// Line restricts Regex to a single line of the content, 1-based,
+	// for rigidly formatted documents where a field is always on the
+	// same line. Negative values count from the end, -1 being the last
+	// line. If the line doesn't exist it's a NoMatch. Zero, the
+	// default, searches the whole content
+	Line int
+
+	// NumberedGroups also captures the unnamed groups of Regex, under
+	// their index prefixed with "_", i.e. "_1" and "_2", so legacy
+	// regexes with numbered groups can be used without naming them
+	NumberedGroups bool
+
+	// OmitUnmatched leaves out of Fields the named groups that didn't
+	// participate in the match, like an optional (?P<unit>.*)?, instead
+	// of storing "", so Fields.Has tells them apart from groups that
+	// matched an empty string
+	OmitUnmatched bool
+
+	// WholeMatchKey stores the whole text matched by Regex under this
+	// key when Regex has no named groups, i.e. to capture an order
+	// number with a plain `[A-Z]{2}-\d{6}`. It's ignored if Regex has
+	// any named group, use IncludeFullMatch to get both. Unlike
+	// IncludeFullMatch the value is a regular field, affected by
+	// TrimSpace, SliceFields and the other options. Optional.
+	WholeMatchKey string
+
+	// Occurrence selects which match of Regex is used, 1-based, i.e. 2
+	// for the second phone number of the content. If Regex matches
+	// fewer times it's a NoMatch. Zero, the default, is the same as 1
+	Occurrence int
+
+	// LabelValues finds all matches of Regex, which must have "label"
+	// and "value" groups, storing each value under its label, i.e.
+	// "Beds: 3  Baths: 2" gives "beds" and "baths" in a single match.
+	// Labels are lowercased, with each run of characters that aren't
+	// letters or digits replaced by "_", so "List Price" is stored as
+	// "list_price". The first value of each label wins, and Occurrence
+	// is ignored. FieldNames returns nothing since fields come from the
+	// content
+	LabelValues bool
 }
 
 // Search for all named groups from Regex in content
@@ -194,34 +1050,253 @@ type PatternGroup struct {
 //
 // Return empty fields and NoMatch error if regex doesn't match
 func (pg *PatternGroup) Search(content string) (Fields, error) {
-	fields, ok := regexGroups(pg.Regex, content)
+	if pg.LabelValues && (pg.Regex.SubexpIndex("label") < 0 || pg.Regex.SubexpIndex("value") < 0) {
+		return Fields{}, fmt.Errorf("missing label or value group for %s", pg.Name)
+	}
+	fields, full, ok := pg.match(content)
 	if !ok {
 		if pg.Optional {
 			return Fields{}, nil
 		} else {
-			return Fields{}, &NoMatch{pg.Name, content}
+			return Fields{}, NewNoMatch(pg.Name, content)
 		}
 	}
-	if pg.Clean != nil {
+	if pg.wholeMatch() {
+		fields[pg.WholeMatchKey] = full
+	}
+	if pg.TrimSpace {
+		trimFields(fields)
+	}
+	if pg.IncludeFullMatch {
+		fields[fullMatchKey(pg.FullMatchKey)] = full
+	}
+	if pg.CleanCtx != nil {
+		fields = pg.CleanCtx(fields, content)
+	} else if pg.Clean != nil {
 		fields = pg.Clean(fields)
 	}
+	splitFields(fields, pg.SliceFields)
+	if err := lookupFields(pg.Name, fields, pg.LookupFields, pg.StrictLookup); err != nil {
+		return Fields{}, err
+	}
+	if err := validateFields(pg.Name, fields, pg.EnumFields, pg.Validate); err != nil {
+		return Fields{}, err
+	}
 	return fields, nil
 }
 
+// match extracts all groups of Regex from content, and the whole text
+// matched, applying Fold if set
+func (pg *PatternGroup) match(content string) (fields Fields, full string, ok bool) {
+	if content, _, ok = selectLine(content, pg.Line); !ok {
+		return Fields{}, "", false
+	}
+	text, offsets := content, []int(nil)
+	if pg.Fold != nil {
+		text, offsets = pg.Fold(content)
+	}
+	if pg.LabelValues {
+		return pg.matchLabels(content, text, offsets)
+	}
+	loc := pg.find(text)
+	if loc == nil {
+		return Fields{}, "", false
+	}
+	if offsets != nil {
+		for i, offset := range loc {
+			if offset >= 0 {
+				loc[i] = offsets[offset]
+			}
+		}
+	}
+
+	fields = Fields{}
+	for i, groupName := range pg.Regex.SubexpNames() {
+		if i == 0 {
+			continue // first name is always ""
+		}
+		key := groupName
+		if key == "" && pg.NumberedGroups {
+			key = numberedGroup(i)
+		}
+		if loc[2*i] < 0 {
+			if !pg.OmitUnmatched {
+				fields[key] = ""
+			}
+			continue
+		}
+		if key == "" && pg.OmitUnmatched {
+			continue
+		}
+		fields[key] = content[loc[2*i]:loc[2*i+1]]
+	}
+	return fields, content[loc[0]:loc[1]], true
+}
+
+// matchLabels is match for LabelValues, where full is the text from
+// the first to the last match used
+func (pg *PatternGroup) matchLabels(content, text string, offsets []int) (fields Fields, full string, ok bool) {
+	label, value := pg.Regex.SubexpIndex("label"), pg.Regex.SubexpIndex("value")
+	fields = Fields{}
+	start, end := -1, -1
+	for _, loc := range pg.Regex.FindAllStringSubmatchIndex(text, -1) {
+		if offsets != nil {
+			for i, offset := range loc {
+				if offset >= 0 {
+					loc[i] = offsets[offset]
+				}
+			}
+		}
+		if loc[2*label] < 0 || loc[2*value] < 0 {
+			continue
+		}
+		key := strings.Replace(normalizeLabel(content[loc[2*label]:loc[2*label+1]]), " ", "_", -1)
+		if key == "" || fields.Has(key) {
+			continue
+		}
+		fields[key] = content[loc[2*value]:loc[2*value+1]]
+		if start == -1 {
+			start = loc[0]
+		}
+		end = loc[1]
+	}
+	if len(fields) == 0 {
+		return Fields{}, "", false
+	}
+	return fields, content[start:end], true
+}
+
+// find returns the submatch offsets of the match of Regex in text
+// selected by Occurrence, or nil if there's no such match
+func (pg *PatternGroup) find(text string) []int {
+	if pg.Occurrence <= 1 {
+		return pg.Regex.FindStringSubmatchIndex(text)
+	}
+	all := pg.Regex.FindAllStringSubmatchIndex(text, pg.Occurrence)
+	if len(all) < pg.Occurrence {
+		return nil
+	}
+	return all[pg.Occurrence-1]
+}
+
+// Raw is an advanced API that returns the submatches of Regex in
+// content, as regexp.Regexp.FindStringSubmatch, and the names of its
+// groups, as regexp.Regexp.SubexpNames, for callers that need to
+// process numbered groups themselves. ok is false if Regex doesn't
+// match
+//
+// Only Regex is applied: Line, Fold and all the processing Search does
+// on the fields are ignored
+func (pg *PatternGroup) Raw(content string) (matches, names []string, ok bool) {
+	matches = pg.Regex.FindStringSubmatch(content)
+	return matches, pg.Regex.SubexpNames(), matches != nil
+}
+
+// Folder returns a folded version of content to match a regex against,
+// and the offsets that map it back to content: offsets[i] is the byte
+// offset in content of the byte i of folded. It must have
+// len(folded)+1 items, the last one being len(content)
+//
+// Offsets of bytes where a value can start or end, i.e. the first byte
+// of each character, should point to the first byte in content of the
+// corresponding character, so text removed when folding is kept in the
+// value of the previous character
+type Folder func(content string) (folded string, offsets []int)
+
+// wholeMatch reports whether the whole match is stored under
+// WholeMatchKey
+func (pg *PatternGroup) wholeMatch() bool {
+	return pg.WholeMatchKey != "" && len(subexpNames(pg.Regex)) == 0
+}
+
+// FieldNames returns the names of Regex's named groups, the keys of its
+// unnamed groups if NumberedGroups is set, and WholeMatchKey if used.
+// With LabelValues it returns nothing
+func (pg *PatternGroup) FieldNames() []string {
+	if pg.LabelValues {
+		return []string{}
+	}
+	names := subexpNames(pg.Regex)
+	if pg.wholeMatch() {
+		names = append(names, pg.WholeMatchKey)
+	}
+	if pg.NumberedGroups {
+		for i, name := range pg.Regex.SubexpNames() {
+			if i > 0 && name == "" {
+				names = append(names, numberedGroup(i))
+			}
+		}
+	}
+	return names
+}
+
+// numberedGroup is the key of the unnamed group i with NumberedGroups
+func numberedGroup(i int) string {
+	return "_" + strconv.Itoa(i)
+}
+
+// SearchSpans is like Search but returns the byte offsets of each named
+// group in content instead of its value, i.e. to highlight where each
+// field was found
+//
+// Spans are [start, end) so content[span[0]:span[1]] is the matched
+// value, even when Fold is set. Groups that didn't participate in the
+// match are omitted. Clean is not called
+func (pg *PatternGroup) SearchSpans(content string) (map[string][2]int, error) {
+	var spans map[string][2]int
+	line, start, ok := selectLine(content, pg.Line)
+	if ok && pg.Fold != nil {
+		folded, offsets := pg.Fold(line)
+		if spans, ok = regexSpans(pg.Regex, pg.find(folded)); ok {
+			for k, span := range spans {
+				spans[k] = [2]int{offsets[span[0]], offsets[span[1]]}
+			}
+		}
+	} else if ok {
+		spans, ok = regexSpans(pg.Regex, pg.find(line))
+	}
+	for k, span := range spans {
+		spans[k] = [2]int{start + span[0], start + span[1]}
+	}
+	if !ok {
+		if pg.Optional {
+			return map[string][2]int{}, nil
+		}
+		return map[string][2]int{}, NewNoMatch(pg.Name, content)
+	}
+	return spans, nil
+}
+
 // TemplatePatternGroup is a Pattern exactly like PatternGroup but instead of
 // providing a regex you can provide a template to a regex with with variables
 // like {contact_name} that will be replaced with fields found up to this point
 // with other Patterns
 //
-// Everything else is the same as PatternGroup
+// Everything else is the same as PatternGroup, except that named groups
+// that didn't participate in the match, like an optional
+// (?P<unit>.*)?, are left out of the returned Fields while groups that
+// matched an empty string are stored as "". Use Fields.Has() to tell
+// them apart
 //
 // Note that Search() can now fail if the regex fails to compile
 type TemplatePatternGroup struct {
 	Name          string
 	RegexTemplate string
 	Clean         func(f Fields) Fields
+	CleanCtx      func(f Fields, content string) Fields
+	TrimSpace     bool
 	Optional      bool
 
+	IncludeFullMatch bool
+	FullMatchKey     string
+	Fold             Folder
+	EnumFields       map[string][]string
+	Validate         map[string]func(value string) error
+	SliceFields      map[string]string
+	LookupFields     map[string]map[string]string
+	StrictLookup     bool
+	Line             int
+
 	fields Fields
 }
 
@@ -234,10 +1309,23 @@ func (pg *TemplatePatternGroup) Search(content string) (Fields, error) {
 		return Fields{}, fmt.Errorf("failed to compile regex for %s: %s (%v)", pg.Name, reg, err)
 	}
 	p := &PatternGroup{
-		Name:     pg.Name,
-		Regex:    regex,
-		Clean:    pg.Clean,
-		Optional: pg.Optional,
+		Name:      pg.Name,
+		Regex:     regex,
+		Clean:     pg.Clean,
+		CleanCtx:  pg.CleanCtx,
+		TrimSpace: pg.TrimSpace,
+		Optional:  pg.Optional,
+
+		IncludeFullMatch: pg.IncludeFullMatch,
+		FullMatchKey:     pg.FullMatchKey,
+		Fold:             pg.Fold,
+		EnumFields:       pg.EnumFields,
+		Validate:         pg.Validate,
+		SliceFields:      pg.SliceFields,
+		LookupFields:     pg.LookupFields,
+		StrictLookup:     pg.StrictLookup,
+		Line:             pg.Line,
+		OmitUnmatched:    true,
 	}
 	return p.Search(content)
 }
@@ -253,22 +1341,99 @@ func (pg *TemplatePatternGroup) renderTemplate() string {
 	return templateVarRe.ReplaceAllString(reg, "")
 }
 
+// FieldNames returns the names of the named groups in RegexTemplate,
+// or nil if the template can't be compiled without its variables
+func (pg *TemplatePatternGroup) FieldNames() []string {
+	regex, err := regexp.Compile(templateVarRe.ReplaceAllString(pg.RegexTemplate, ""))
+	if err != nil {
+		return nil
+	}
+	return subexpNames(regex)
+}
+
 func (pg *TemplatePatternGroup) SetFields(f Fields) { pg.fields = f }
 func (pg *TemplatePatternGroup) GetFields() Fields  { return pg.fields }
 
 // PatternList is a Pattern implementation that finds a list of items
 // in the content
 type PatternList struct {
-	Name       string
-	ListRegex  *regexp.Regexp
+	Name      string
+	ListRegex *regexp.Regexp
+
+	// SplitRegex splits the list text into items. If it's nil each
+	// match of ItemRegex in the list text is an item instead, for lists
+	// without a delimiter whose items are recognizable on their own
 	SplitRegex *regexp.Regexp
-	ItemRegex  *regexp.Regexp
-	CleanItem  func(f Fields) Fields
-	Optional   bool
+
+	// SplitBefore splits the list text right before each SplitRegex
+	// match instead of removing it, for lists where the delimiter is
+	// the start of each item, i.e. "MLS #". The text before the first
+	// match is still an item, unless it's empty
+	SplitBefore bool
+
+	ItemRegex *regexp.Regexp
+	CleanItem func(f Fields) Fields
+
+	// Defaults maps field names to the value items get when ItemRegex
+	// doesn't capture them, or captures an empty value. It's applied
+	// after CleanItem. Optional.
+	Defaults map[string]string
+
+	// LookupFields and StrictLookup translate the values of each item
+	// after Defaults, like PatternGroup.LookupFields. Optional.
+	LookupFields map[string]map[string]string
+	StrictLookup bool
+
+	// FilterItem, if set, is called after CleanItem and only the items
+	// it returns true for are kept, i.e. to drop listings outside a
+	// price range
+	FilterItem func(f Fields) bool
+
+	Optional bool
+
+	// TrimItems removes leading and trailing white space from all
+	// values captured by ItemRegex, before CleanItem is called
+	TrimItems bool
+
+	// IncludeFullMatch stores the raw list text in the returned Fields
+	// and the raw text of each item in its Fields, under FullMatchKey
+	// or "_match" if it's empty
+	IncludeFullMatch bool
+	FullMatchKey     string
+
+	// SkipUnmatchedItems drops the items ItemRegex doesn't match, like
+	// separators or footer lines, instead of returning NoMatch for the
+	// whole list
+	SkipUnmatchedItems bool
+
+	// OmitUnmatched leaves out of each item the named groups of
+	// ItemRegex that didn't participate in the match, like
+	// PatternGroup.OmitUnmatched
+	OmitUnmatched bool
+
+	// MaxItems is a safety limit on the number of items, so a
+	// malformed list can't produce an unbounded number of them. Items
+	// after the first MaxItems are dropped silently, unless
+	// ErrorOnMaxItems is set and Search returns an error instead. Items
+	// dropped by SkipUnmatchedItems or FilterItem don't count. Zero, the
+	// default, means unlimited
+	MaxItems        int
+	ErrorOnMaxItems bool
+}
+
+// FieldNames returns the name of the list field
+func (pl *PatternList) FieldNames() []string {
+	if names := pl.ListRegex.SubexpNames(); len(names) > 1 && names[1] != "" {
+		return []string{names[1]}
+	}
+	return nil
 }
 
 // Search for a list of items in the content using all the regexes
 //
+// A trailing \r is removed from each item text, so lists with \r\n
+// line endings can be split on \n
+//
 // Return value will be a hash with only one key where the value
 // is a slice of Fields, i.e.:
 //
@@ -281,54 +1446,222 @@ type PatternList struct {
 //    }
 //
 func (pl *PatternList) Search(content string) (Fields, error) {
-	if !pl.ListRegex.MatchString(content) {
-		if pl.Optional {
-			return Fields{}, nil
-		} else {
-			return Fields{}, &NoMatch{pl.Name + " - list regex", content}
-		}
+	items := []Fields{}
+	matched, err := pl.searchEach(content, func(f Fields) error {
+		items = append(items, f)
+		return nil
+	})
+	if err != nil || !matched {
+		return Fields{}, err
 	}
 
 	listName := pl.ListRegex.SubexpNames()[1]
-	listText := pl.ListRegex.FindStringSubmatch(content)[1]
+	fields := Fields{listName: items}
+	if pl.IncludeFullMatch {
+		fields[fullMatchKey(pl.FullMatchKey)] = pl.ListRegex.FindStringSubmatch(content)[1]
+	}
+	return fields, nil
+}
 
-	itemsTexts := pl.SplitRegex.Split(listText, -1)
-	items := []Fields{}
+// SearchEach is like Search but instead of returning all items calls fn
+// with each one, in order, as soon as it's parsed
+//
+// Only the current item's Fields is kept in memory by SearchEach, so
+// very long lists can be streamed somewhere else. Note that item texts
+// are substrings of content, so content is kept in memory until
+// SearchEach returns
+//
+// If fn returns an error SearchEach stops and returns it. NoMatch
+// errors are returned as in Search, so fn may have been called with
+// some items before an item fails to match. If the list is Optional
+// and not found fn is never called and nil is returned
+func (pl *PatternList) SearchEach(content string, fn func(f Fields) error) error {
+	_, err := pl.searchEach(content, fn)
+	return err
+}
+
+// searchEach implements SearchEach and also reports if the list was
+// found in content
+func (pl *PatternList) searchEach(content string, fn func(f Fields) error) (matched bool, err error) {
+	matches := pl.ListRegex.FindStringSubmatch(content)
+	if matches == nil {
+		if pl.Optional {
+			return false, nil
+		}
+		return false, NewNoMatch(pl.Name+" - list regex", content)
+	}
 
+	listText := matches[1]
+	var itemsTexts []string
+	if pl.SplitRegex != nil && pl.SplitBefore {
+		itemsTexts = splitBefore(pl.SplitRegex, listText)
+	} else if pl.SplitRegex != nil {
+		itemsTexts = pl.SplitRegex.Split(listText, -1)
+	} else {
+		itemsTexts = pl.ItemRegex.FindAllString(listText, -1)
+	}
+
+	count := 0
 	for i, itemText := range itemsTexts {
+		itemText = strings.TrimSuffix(itemText, "\r")
 		if itemText == "" {
 			continue
 		}
-		fields, ok := regexGroups(pl.ItemRegex, itemText)
+		fields, ok := regexGroups(pl.ItemRegex, itemText, pl.OmitUnmatched)
 		if !ok {
-			return Fields{}, &NoMatch{fmt.Sprintf("%s - item %d", pl.Name, i), itemText}
+			if pl.SkipUnmatchedItems {
+				continue
+			}
+			return true, NewNoMatch(fmt.Sprintf("%s - item %d", pl.Name, i), itemText)
+		}
+		if pl.TrimItems {
+			trimFields(fields)
+		}
+		if pl.IncludeFullMatch {
+			fields[fullMatchKey(pl.FullMatchKey)] = itemText
 		}
 		if pl.CleanItem != nil {
 			fields = pl.CleanItem(fields)
 		}
-		items = append(items, fields)
+		for key, value := range pl.Defaults {
+			if v, ok := fields[key]; !ok || v == "" {
+				fields[key] = value
+			}
+		}
+		if err := lookupFields(fmt.Sprintf("%s - item %d", pl.Name, i), fields, pl.LookupFields, pl.StrictLookup); err != nil {
+			return true, err
+		}
+		if pl.FilterItem != nil && !pl.FilterItem(fields) {
+			continue
+		}
+		if count++; pl.MaxItems > 0 && count > pl.MaxItems {
+			if pl.ErrorOnMaxItems {
+				return true, fmt.Errorf("too many items for %s: more than %d", pl.Name, pl.MaxItems)
+			}
+			break
+		}
+		if err := fn(fields); err != nil {
+			return true, err
+		}
 	}
 
-	return Fields{listName: items}, nil
+	return true, nil
 }
 
-// regexGroups extracts all named groups of the regex re from content
+// splitBefore splits s into the substrings that start at each match of
+// re, and the one before the first match
+func splitBefore(re *regexp.Regexp, s string) []string {
+	texts := []string{}
+	start := 0
+	for _, loc := range re.FindAllStringIndex(s, -1) {
+		if loc[0] > start {
+			texts = append(texts, s[start:loc[0]])
+		}
+		start = loc[0]
+	}
+	return append(texts, s[start:])
+}
+
+// subexpNames returns the names of all named groups of re
+func subexpNames(re *regexp.Regexp) []string {
+	names := []string{}
+	for _, name := range re.SubexpNames() {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// selectLine returns line n of content, as defined by PatternGroup.Line,
+// and its offset in content. A trailing line break doesn't start a new
+// line and a trailing "\r" is not part of the line
+func selectLine(content string, n int) (line string, start int, ok bool) {
+	if n == 0 {
+		return content, 0, true
+	}
+	starts := []int{0}
+	for i := 0; i < len(content); i++ {
+		if content[i] == '\n' && i+1 < len(content) {
+			starts = append(starts, i+1)
+		}
+	}
+	if n < 0 {
+		n += len(starts) + 1
+	}
+	if n < 1 || n > len(starts) || content == "" {
+		return "", 0, false
+	}
+	start = starts[n-1]
+	line = content[start:]
+	if i := strings.IndexByte(line, '\n'); i != -1 {
+		line = line[:i]
+	}
+	return strings.TrimSuffix(line, "\r"), start, true
+}
+
+// fullMatchKey returns the key to store a full match under
+func fullMatchKey(key string) string {
+	if key == "" {
+		return "_match"
+	}
+	return key
+}
+
+// trimFields removes leading and trailing white space from all string
+// values in fields
+func trimFields(fields Fields) {
+	for k, v := range fields {
+		if vs, ok := v.(string); ok {
+			fields[k] = strings.TrimSpace(vs)
+		}
+	}
+}
+
+// regexGroups extracts all named groups of the regex re from content.
+// If omitUnmatched is set the groups that didn't participate in the
+// match are left out instead of stored as ""
 //
 // ok will be false if regex doesn't match
-func regexGroups(re *regexp.Regexp, content string) (fields Fields, ok bool) {
-	if !re.MatchString(content) {
+func regexGroups(re *regexp.Regexp, content string, omitUnmatched bool) (fields Fields, ok bool) {
+	loc := re.FindStringSubmatchIndex(content)
+	if loc == nil {
 		return Fields{}, false
 	}
 
 	fields = Fields{}
-	matches := re.FindStringSubmatch(content)
-
 	for i, groupName := range re.SubexpNames() {
 		if i == 0 {
 			continue // first name is always ""
 		}
-		fields[groupName] = matches[i]
+		if loc[2*i] < 0 {
+			if !omitUnmatched {
+				fields[groupName] = ""
+			}
+			continue
+		}
+		fields[groupName] = content[loc[2*i]:loc[2*i+1]]
 	}
 
 	return fields, true
 }
+
+// regexSpans returns the byte offsets of all named groups of the regex
+// re from loc, as returned by FindStringSubmatchIndex
+//
+// ok will be false if loc is nil, i.e. regex doesn't match
+func regexSpans(re *regexp.Regexp, loc []int) (spans map[string][2]int, ok bool) {
+	if loc == nil {
+		return map[string][2]int{}, false
+	}
+
+	spans = map[string][2]int{}
+	for i, groupName := range re.SubexpNames() {
+		if i == 0 || groupName == "" || loc[2*i] < 0 {
+			continue
+		}
+		spans[groupName] = [2]int{loc[2*i], loc[2*i+1]}
+	}
+
+	return spans, true
+}
@@ -1,9 +1,13 @@
 package docparser_test
 
 import (
+	"errors"
 	"fmt"
 	"net/url"
+	"reflect"
 	"regexp"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/RealGeeks/docparser"
@@ -57,6 +61,367 @@ func ExamplePatternGroup_clean() {
 	// Igor Sobreira
 }
 
+func ExamplePatternGroup_cleanCtx() {
+	pattern := &docparser.PatternGroup{
+		Name:  "Showing time",
+		Regex: regexp.MustCompile(`Showing at (?P<time>.*)\n`),
+
+		// CleanCtx receives the whole content, so values can be
+		// cleaned using information found elsewhere in it
+		CleanCtx: func(f docparser.Fields, content string) docparser.Fields {
+			if strings.Contains(content, "Timezone: HST") {
+				f["time"] = f.GetString("time") + " -1000"
+			}
+			return f
+		},
+	}
+
+	content := "Timezone: HST\nShowing at 10:00\n"
+
+	fields, err := pattern.Search(content)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(fields.GetString("time"))
+	// Output:
+	// 10:00 -1000
+}
+
+func TestPatternGroupCleanCtxPrecedence(t *testing.T) {
+	pattern := &docparser.PatternGroup{
+		Regex: regexp.MustCompile(`Name: (?P<name>.*)`),
+		Clean: func(f docparser.Fields) docparser.Fields {
+			f["name"] = "clean"
+			return f
+		},
+		CleanCtx: func(f docparser.Fields, content string) docparser.Fields {
+			f["name"] = "cleanctx " + content
+			return f
+		},
+	}
+	fields, err := pattern.Search("Name: bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name := fields.GetString("name"); name != "cleanctx Name: bob" {
+		t.Errorf("want CleanCtx result, got %q", name)
+	}
+}
+
+func ExamplePatternGroup_SearchSpans() {
+	pattern := &docparser.PatternGroup{
+		Name:  "Contact Info",
+		Regex: regexp.MustCompile(`Name (?P<name>.*)\nEmail (?P<email>.*)`),
+	}
+
+	content := "Name Igor Sobreira\nEmail igor@realgeeks.com"
+
+	spans, err := pattern.SearchSpans(content)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, key := range []string{"name", "email"} {
+		span := spans[key]
+		fmt.Printf("%s %v %q\n", key, span, content[span[0]:span[1]])
+	}
+	// Output:
+	// name [5 18] "Igor Sobreira"
+	// email [25 43] "igor@realgeeks.com"
+}
+
+func TestPatternGroupSearchSpans(t *testing.T) {
+	pattern := &docparser.PatternGroup{
+		Name:  "Phone",
+		Regex: regexp.MustCompile(`Phone: (?P<phone>\S+)(?: ext (?P<ext>\d+))?(\n)`),
+	}
+
+	spans, err := pattern.SearchSpans("Name: bob\nPhone: 221-1122\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][2]int{"phone": {17, 25}}
+	if !reflect.DeepEqual(spans, want) {
+		t.Errorf("want %v got %v", want, spans)
+	}
+
+	_, err = pattern.SearchSpans("Name: bob\n")
+	if _, ok := err.(*docparser.NoMatch); !ok {
+		t.Errorf("want NoMatch got %#v", err)
+	}
+
+	pattern.Optional = true
+	spans, err = pattern.SearchSpans("Name: bob\n")
+	if err != nil || len(spans) != 0 {
+		t.Errorf("optional want no spans, got %v (%v)", spans, err)
+	}
+}
+
+func TestPatternGroupTrimSpace(t *testing.T) {
+	var cleaned string
+	pattern := &docparser.PatternGroup{
+		Regex:     regexp.MustCompile(`Name:(?P<name>.*)\nEmail:(?P<email>.*)`),
+		TrimSpace: true,
+		Clean: func(f docparser.Fields) docparser.Fields {
+			cleaned = f.GetString("name")
+			return f
+		},
+	}
+	fields, err := pattern.Search("Name:  bob \t\nEmail: bob@site.com ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cleaned != "bob" {
+		t.Errorf("Clean should receive trimmed values, got %q", cleaned)
+	}
+	if email := fields.GetString("email"); email != "bob@site.com" {
+		t.Errorf("want email %q got %q", "bob@site.com", email)
+	}
+}
+
+func TestPatternListTrimItems(t *testing.T) {
+	pattern := &docparser.PatternList{
+		ListRegex:  regexp.MustCompile(`(?s:Items:(?P<items>.*))`),
+		SplitRegex: regexp.MustCompile(`,`),
+		ItemRegex:  regexp.MustCompile(`(?P<name>.*)`),
+		TrimItems:  true,
+	}
+	fields, err := pattern.Search("Items: one , two,three ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]string{{"name": "one"}, {"name": "two"}, {"name": "three"}}
+	if items := fields.GetMapSlice("items"); !reflect.DeepEqual(items, want) {
+		t.Errorf("want %v got %v", want, items)
+	}
+}
+
+func ExamplePatternGroup_enumFields() {
+	pattern := &docparser.PatternGroup{
+		Name:  "Status",
+		Regex: regexp.MustCompile(`Status: (?P<status>.*)`),
+		EnumFields: map[string][]string{
+			"status": {"new", "active", "closed"},
+		},
+	}
+
+	_, err := pattern.Search("Status: pending")
+	fmt.Println(err)
+	// Output:
+	// Invalid field "status" for "Status": "pending" is not one of ["new" "active" "closed"]
+}
+
+func TestPatternGroupValidate(t *testing.T) {
+	pattern := &docparser.PatternGroup{
+		Name:  "Listing",
+		Regex: regexp.MustCompile(`Status: (?P<status>\w*)(?: Beds: (?P<beds>\w+))?`),
+		Clean: func(f docparser.Fields) docparser.Fields {
+			f["status"] = strings.ToLower(f.GetString("status"))
+			return f
+		},
+		EnumFields: map[string][]string{"status": {"new", "active"}},
+		Validate: map[string]func(string) error{
+			"beds": func(v string) error {
+				if _, err := strconv.Atoi(v); err != nil {
+					return errors.New("not a number")
+				}
+				return nil
+			},
+		},
+	}
+	var tests = []struct {
+		content string
+		field   string
+	}{
+		{content: "Status: NEW Beds: 3"},
+		{content: "Status: Active"},
+		{content: "Status: sold Beds: 3", field: "status"},
+		{content: "Status: new Beds: three", field: "beds"},
+	}
+	for _, tt := range tests {
+		_, err := pattern.Search(tt.content)
+		if tt.field == "" {
+			if err != nil {
+				t.Errorf("content %q failed: %s", tt.content, err)
+			}
+			continue
+		}
+		invalid, ok := err.(*docparser.InvalidField)
+		if !ok || invalid.Field != tt.field {
+			t.Errorf("content %q want InvalidField %q got %#v", tt.content, tt.field, err)
+		}
+	}
+}
+
+func ExamplePatternGroup_sliceFields() {
+	pattern := &docparser.PatternGroup{
+		Name:        "Tags",
+		Regex:       regexp.MustCompile(`Tags: (?P<tags>.*)`),
+		SliceFields: map[string]string{"tags": ","},
+		EnumFields: map[string][]string{
+			"tags": {"hot", "verified", "relocation"},
+		},
+	}
+
+	fields, err := pattern.Search("Tags: hot, verified,, relocation")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("%q\n", fields["tags"])
+	// Output:
+	// ["hot" "verified" "relocation"]
+}
+
+func TestPatternGroupSliceFields(t *testing.T) {
+	pattern := &docparser.PatternGroup{
+		Regex:       regexp.MustCompile(`Tags: (?P<tags>[^\n]*)(?:\nAreas: (?P<areas>.*))?`),
+		SliceFields: map[string]string{"tags": ",", "areas": " / ", "missing": ","},
+		EnumFields:  map[string][]string{"tags": {"hot", "new"}},
+	}
+	var tests = []struct {
+		content string
+		want    docparser.Fields
+	}{
+		{"Tags: hot\nAreas: Kailua / Kaneohe", docparser.Fields{"tags": []string{"hot"}, "areas": []string{"Kailua", "Kaneohe"}}},
+		{"Tags: new,hot", docparser.Fields{"tags": []string{"new", "hot"}, "areas": []string{}}},
+		{"Tags: ", docparser.Fields{"tags": []string{}, "areas": []string{}}},
+	}
+	for _, tt := range tests {
+		fields, err := pattern.Search(tt.content)
+		if err != nil {
+			t.Errorf("content %q failed: %s", tt.content, err)
+			continue
+		}
+		if !reflect.DeepEqual(fields, tt.want) {
+			t.Errorf("content %q want %#v got %#v", tt.content, tt.want, fields)
+		}
+	}
+
+	_, err := pattern.Search("Tags: hot, cold")
+	if invalid, ok := err.(*docparser.InvalidField); !ok || invalid.Value != "cold" {
+		t.Errorf("want InvalidField for cold, got %#v", err)
+	}
+}
+
+func ExamplePatternGroup_lookupFields() {
+	pattern := &docparser.PatternGroup{
+		Name:  "Listing",
+		Regex: regexp.MustCompile(`status: (?P<status>\w+)`),
+		LookupFields: map[string]map[string]string{
+			"status": {"A": "Active", "S": "Sold"},
+		},
+	}
+
+	fields, err := pattern.Search("status: A")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(fields.GetString("status"))
+	// Output:
+	// Active
+}
+
+func TestPatternGroupLookupFields(t *testing.T) {
+	pattern := &docparser.PatternGroup{
+		Name:        "Listing",
+		Regex:       regexp.MustCompile(`status: (?P<status>\w*)(?: features: (?P<features>.*))?`),
+		SliceFields: map[string]string{"features": ","},
+		LookupFields: map[string]map[string]string{
+			"status":   {"A": "Active", "S": "Sold"},
+			"features": {"P": "Pool", "G": "Garage"},
+		},
+	}
+	var tests = []struct {
+		content string
+		want    docparser.Fields
+	}{
+		{"status: S features: P, G", docparser.Fields{"status": "Sold", "features": []string{"Pool", "Garage"}}},
+		{"status: X features: P, V", docparser.Fields{"status": "X", "features": []string{"Pool", "V"}}},
+		{"status: ", docparser.Fields{"status": "", "features": []string{}}},
+	}
+	for _, tt := range tests {
+		fields, err := pattern.Search(tt.content)
+		if err != nil {
+			t.Errorf("content %q failed: %s", tt.content, err)
+			continue
+		}
+		if !reflect.DeepEqual(fields, tt.want) {
+			t.Errorf("content %q want %v got %v", tt.content, tt.want, fields)
+		}
+	}
+
+	pattern.StrictLookup = true
+	for content, field := range map[string]string{"status: X": "status", "status: A features: P, V": "features"} {
+		_, err := pattern.Search(content)
+		if invalid, ok := err.(*docparser.InvalidField); !ok || invalid.Field != field {
+			t.Errorf("content %q want InvalidField %q got %#v", content, field, err)
+		}
+	}
+	if _, err := pattern.Search("status: "); err != nil {
+		t.Errorf("empty values should not be looked up: %s", err)
+	}
+}
+
+func TestPatternGroupLine(t *testing.T) {
+	content := "REPORT 2211\r\nMark Stewart\r\nKailua, HI\r\n"
+	var tests = []struct {
+		line int
+		want string
+		ok   bool
+	}{
+		{1, "REPORT 2211", true},
+		{2, "Mark Stewart", true},
+		{3, "Kailua, HI", true},
+		{-1, "Kailua, HI", true},
+		{-3, "REPORT 2211", true},
+		{4, "", false},
+		{-4, "", false},
+	}
+	for _, tt := range tests {
+		pattern := &docparser.PatternGroup{
+			Name:  "Line",
+			Regex: regexp.MustCompile(`^(?P<value>.+)$`),
+			Line:  tt.line,
+		}
+		fields, err := pattern.Search(content)
+		if !tt.ok {
+			if _, ok := err.(*docparser.NoMatch); !ok {
+				t.Errorf("line %d want NoMatch got %v %v", tt.line, fields, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("line %d failed: %s", tt.line, err)
+			continue
+		}
+		if value := fields.GetString("value"); value != tt.want {
+			t.Errorf("line %d want %q got %q", tt.line, tt.want, value)
+		}
+
+		spans, err := pattern.SearchSpans(content)
+		if err != nil {
+			t.Errorf("line %d spans failed: %s", tt.line, err)
+			continue
+		}
+		if span := spans["value"]; content[span[0]:span[1]] != tt.want {
+			t.Errorf("line %d invalid span %v", tt.line, span)
+		}
+	}
+}
+
+func TestPatternGroupLineOnlyThatLine(t *testing.T) {
+	pattern := &docparser.PatternGroup{
+		Regex: regexp.MustCompile(`MLS: (?P<mls>\d+)`),
+		Line:  2,
+	}
+	if _, err := pattern.Search("MLS: 1111\nnothing\nMLS: 2222"); err == nil {
+		t.Error("should only match line 2")
+	}
+}
+
 func ExamplePatternList() {
 
 	pattern := &docparser.PatternList{
@@ -103,18 +468,90 @@ func ExamplePatternList() {
 	// 3: Language name = "Go"
 }
 
+func ExamplePatternList_SearchEach() {
+	pattern := &docparser.PatternList{
+		Name:       "Languages",
+		ListRegex:  regexp.MustCompile(`(?s:Real Geeks languages:\n(?P<languages>.*))`),
+		SplitRegex: regexp.MustCompile(`\n`),
+		ItemRegex:  regexp.MustCompile(` - (?P<name>.*)`),
+	}
+
+	content := `Real Geeks languages:
+ - Python
+ - Ruby
+ - Javascript
+ - Go
+`
+
+	errDone := errors.New("done")
+	err := pattern.SearchEach(content, func(f docparser.Fields) error {
+		fmt.Println(f.GetString("name"))
+		if f.GetString("name") == "Ruby" {
+			return errDone
+		}
+		return nil
+	})
+	fmt.Println(err)
+	// Output:
+	// Python
+	// Ruby
+	// done
+}
+
+func TestPatternListSearchEach(t *testing.T) {
+	pattern := &docparser.PatternList{
+		Name:       "Items",
+		ListRegex:  regexp.MustCompile(`(?s:Items:\n(?P<items>.*))`),
+		SplitRegex: regexp.MustCompile(`\n`),
+		ItemRegex:  regexp.MustCompile(`- (?P<name>.*)`),
+	}
+
+	var names []string
+	err := pattern.SearchEach("Items:\n- a\n- b\nfooter\n", func(f docparser.Fields) error {
+		names = append(names, f.GetString("name"))
+		return nil
+	})
+	if _, ok := err.(*docparser.NoMatch); !ok {
+		t.Errorf("want NoMatch got %#v", err)
+	}
+	if !reflect.DeepEqual(names, []string{"a", "b"}) {
+		t.Errorf("items before the failure should be streamed, got %v", names)
+	}
+
+	called := false
+	pattern.Optional = true
+	err = pattern.SearchEach("no list", func(f docparser.Fields) error {
+		called = true
+		return nil
+	})
+	if err != nil || called {
+		t.Errorf("optional list not found should not call fn: %v", err)
+	}
+
+	fields, err := pattern.Search("Items:\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if items, ok := fields["items"].([]docparser.Fields); !ok || len(items) != 0 {
+		t.Errorf("empty list should return empty items, got %#v", fields)
+	}
+}
+
 func ExampleDocument() {
 
 	document := &docparser.Document{
-		&docparser.PatternGroup{
-			Name:  "Contact information",
-			Regex: regexp.MustCompile(`Name: (?P<name>.*)\nPhone: (?P<phone>.*)\n`),
-		},
-		&docparser.PatternList{
-			Name:       "Properties viewed",
-			ListRegex:  regexp.MustCompile(`(?s:Properties:\n(?P<properties>.*))`),
-			SplitRegex: regexp.MustCompile(`\n`),
-			ItemRegex:  regexp.MustCompile(` - MLS #(?P<mls>.*) / (?P<address>.*)`),
+		Name: "Lead",
+		Patterns: []docparser.Pattern{
+			&docparser.PatternGroup{
+				Name:  "Contact information",
+				Regex: regexp.MustCompile(`Name: (?P<name>.*)\nPhone: (?P<phone>.*)\n`),
+			},
+			&docparser.PatternList{
+				Name:       "Properties viewed",
+				ListRegex:  regexp.MustCompile(`(?s:Properties:\n(?P<properties>.*))`),
+				SplitRegex: regexp.MustCompile(`\n`),
+				ItemRegex:  regexp.MustCompile(` - MLS #(?P<mls>.*) / (?P<address>.*)`),
+			},
 		},
 	}
 
@@ -147,23 +584,27 @@ Properties:
 
 var testDocuments = docparser.Documents{
 	&docparser.Document{
-		&docparser.PatternGroup{
-			Name:  "Name",
-			Regex: regexp.MustCompile(`Name: (?P<name>.*)\n`),
-		},
-		&docparser.PatternGroup{
-			Name:  "Email",
-			Regex: regexp.MustCompile(`Email: (?P<email>.*)\n`),
+		Patterns: []docparser.Pattern{
+			&docparser.PatternGroup{
+				Name:  "Name",
+				Regex: regexp.MustCompile(`Name: (?P<name>.*)\n`),
+			},
+			&docparser.PatternGroup{
+				Name:  "Email",
+				Regex: regexp.MustCompile(`Email: (?P<email>.*)\n`),
+			},
 		},
 	},
 	&docparser.Document{
-		&docparser.PatternGroup{
-			Name:  "Name",
-			Regex: regexp.MustCompile(`My Name: (?P<name>.*)\n`),
-		},
-		&docparser.TemplatePatternGroup{
-			Name:          "Email",
-			RegexTemplate: `My name and email {name}(?P<email>.*)\n`,
+		Patterns: []docparser.Pattern{
+			&docparser.PatternGroup{
+				Name:  "Name",
+				Regex: regexp.MustCompile(`My Name: (?P<name>.*)\n`),
+			},
+			&docparser.TemplatePatternGroup{
+				Name:          "Email",
+				RegexTemplate: `My name and email {name}(?P<email>.*)\n`,
+			},
 		},
 	},
 }
@@ -209,3 +650,1298 @@ func TestDocumentsNoMatch(t *testing.T) {
 		t.Errorf("invalid error: %s", err)
 	}
 }
+
+func TestNoMatchContentLength(t *testing.T) {
+	defer func(n int) { docparser.NoMatchContentLength = n }(docparser.NoMatchContentLength)
+	pattern := &docparser.PatternGroup{Name: "Name", Regex: regexp.MustCompile(`Name: (?P<name>.*)`)}
+
+	var tests = []struct {
+		length  int
+		content string
+		want    string
+	}{
+		{10, "short", "short"},
+		{10, "0123456789abc", "0123456789"},
+		{5, "caf\u00e9\u00e9", "caf\u00e9"},
+		{0, "0123456789abc", "0123456789abc"},
+	}
+	for _, tt := range tests {
+		docparser.NoMatchContentLength = tt.length
+		_, err := pattern.Search(tt.content)
+		noMatch, ok := err.(*docparser.NoMatch)
+		if !ok {
+			t.Fatalf("want NoMatch got %#v", err)
+		}
+		if noMatch.Content != tt.want {
+			t.Errorf("length %d content %q want %q got %q", tt.length, tt.content, tt.want, noMatch.Content)
+		}
+		if noMatch.Error() != `No match for "Name"` {
+			t.Errorf("invalid error: %s", err)
+		}
+	}
+}
+
+func ExampleSetRedactor() {
+	emailRe := regexp.MustCompile(`[\w.+-]+@[\w-]+(?:\.[\w-]+)+`)
+	docparser.SetRedactor(func(s string) string {
+		return emailRe.ReplaceAllString(s, "<email>")
+	})
+	defer docparser.SetRedactor(nil)
+
+	pattern := &docparser.PatternGroup{
+		Name:       "Email",
+		Regex:      regexp.MustCompile(`Email: (?P<email>.*)`),
+		EnumFields: map[string][]string{"email": {"leads@site.com"}},
+	}
+	_, err := pattern.Search("Email: bob@site.com")
+	fmt.Println(err)
+
+	_, err = pattern.Search("Contact bob@site.com")
+	fmt.Println(err.(*docparser.NoMatch).Content)
+	// Output:
+	// Invalid field "email" for "Email": "<email>" is not one of ["<email>"]
+	// Contact <email>
+}
+
+func TestSetRedactorErrorList(t *testing.T) {
+	docparser.SetRedactor(func(s string) string { return strings.Replace(s, "secret", "***", -1) })
+	defer docparser.SetRedactor(nil)
+
+	errList := &docparser.ErrorList{}
+	errList.Add(errors.New("bad secret"))
+	errList.Add(errors.New("other"))
+	if msg := errList.Error(); msg != "bad ***; other" {
+		t.Errorf("invalid error: %s", msg)
+	}
+}
+
+func ExampleDocument_SearchAll() {
+	document := &docparser.Document{
+		RecordSplit: regexp.MustCompile(`(?m:^-{3,}$)`),
+		Patterns: []docparser.Pattern{
+			&docparser.PatternGroup{Regex: regexp.MustCompile(`MLS: (?P<mls>.*)\n`)},
+			&docparser.PatternGroup{Regex: regexp.MustCompile(`Price: (?P<price>.*)\n`)},
+		},
+	}
+
+	content := `---
+MLS: 2211
+Price: $450,000
+---
+MLS: 9090
+Price: $1,200,000
+---
+`
+
+	records, err := document.SearchAll(content)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, record := range records {
+		fmt.Println(record.GetString("mls"), record.GetString("price"))
+	}
+	// Output:
+	// 2211 $450,000
+	// 9090 $1,200,000
+}
+
+func TestDocumentSearchAllWithoutSplit(t *testing.T) {
+	document := &docparser.Document{
+		Patterns: testDocuments[0].Patterns,
+		Preprocess: []docparser.Preprocessor{
+			func(content string) (string, error) { return strings.Replace(content, ";", "\n", -1), nil },
+		},
+	}
+	records, err := document.SearchAll("Name: bob;Email: bob@site.com;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []docparser.Fields{{"name": "bob", "email": "bob@site.com"}}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("want %v got %v", want, records)
+	}
+	if records, err = document.SearchAll(" \n"); err != nil || len(records) != 0 {
+		t.Errorf("want no records got %v %v", records, err)
+	}
+
+	document.RecordSplit = regexp.MustCompile(`\n---\n`)
+	_, err = document.SearchAll("Name: bob;Email: bob@site.com;\n---\nName: mark;")
+	if err == nil || err.Error() != `record 1: No match for "Email"` {
+		t.Errorf("invalid error: %v", err)
+	}
+}
+
+func TestDocumentSkipIf(t *testing.T) {
+	documents := docparser.Documents{
+		{
+			Name:     "Resale",
+			SkipIf:   regexp.MustCompile(`(?i)new construction`),
+			Patterns: []docparser.Pattern{&docparser.PatternGroup{Regex: regexp.MustCompile(`MLS: (?P<mls>\d+)`)}},
+		},
+		{
+			Name: "New construction",
+			Patterns: []docparser.Pattern{
+				&docparser.PatternGroup{Regex: regexp.MustCompile(`MLS: (?P<mls>\d+)`)},
+				&docparser.PatternGroup{Regex: regexp.MustCompile(`New construction by (?P<builder>.*)`)},
+			},
+		},
+	}
+	fields, err := documents.Search("MLS: 2211\nNew construction by Acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (docparser.Fields{"mls": "2211", "builder": "Acme"}); !reflect.DeepEqual(fields, want) {
+		t.Errorf("want %v got %v", want, fields)
+	}
+
+	_, err = documents[0].Search("MLS: 2211\nNEW CONSTRUCTION")
+	if err == nil || err.Error() != `No match for "Resale - skipped"` {
+		t.Errorf("invalid error: %v", err)
+	}
+	if _, err := documents[0].Search("MLS: 2211"); err != nil {
+		t.Errorf("should not skip without the marker: %s", err)
+	}
+}
+
+func TestDocumentQuickMatch(t *testing.T) {
+	preprocessed := 0
+	documents := docparser.Documents{
+		{
+			Name:       "Zillow",
+			QuickMatch: regexp.MustCompile(`zillow\.com`),
+			Preprocess: []docparser.Preprocessor{func(content string) (string, error) {
+				preprocessed++
+				return content, nil
+			}},
+			Patterns: []docparser.Pattern{&docparser.PatternGroup{Regex: regexp.MustCompile(`Name: (?P<name>.*)`)}},
+		},
+		{
+			Name:     "Generic",
+			Patterns: []docparser.Pattern{&docparser.PatternGroup{Name: "Email", Regex: regexp.MustCompile(`Email: (?P<email>.*)`)}},
+		},
+	}
+	_, err := documents.Search("From: leads@example.com\nName: Mark")
+	want := `Document 0: No match for "Zillow - quick match"; Document 1: No match for "Email"`
+	if err == nil || err.Error() != want {
+		t.Errorf("want %q got %v", want, err)
+	}
+	if preprocessed != 0 {
+		t.Error("document ruled out by QuickMatch was preprocessed")
+	}
+
+	fields, err := documents.Search("From: leads@zillow.com\nName: Mark")
+	if err != nil || fields.GetString("name") != "Mark" || preprocessed != 1 {
+		t.Errorf("invalid result %v (%v)", fields, err)
+	}
+}
+
+func TestDocumentRequiredFields(t *testing.T) {
+	documents := docparser.Documents{
+		{
+			Name: "Lead",
+			Patterns: []docparser.Pattern{
+				&docparser.PatternGroup{Regex: regexp.MustCompile(`Name: (?P<name>.*)`)},
+				&docparser.PatternGroup{Regex: regexp.MustCompile(`Email: (?P<email>.*)`), Optional: true},
+				&docparser.PatternGroup{Regex: regexp.MustCompile(`Tags: (?P<tags>.*)`), SliceFields: map[string]string{"tags": ","}, Optional: true},
+			},
+			RequiredFields: []string{"name", "email", "tags"},
+		},
+		{
+			Name:     "Fallback",
+			Patterns: []docparser.Pattern{&docparser.PatternGroup{Regex: regexp.MustCompile(`Name: (?P<name>.*)`)}},
+		},
+	}
+	var tests = []struct {
+		content string
+		err     string
+	}{
+		{"Name: Mark\nEmail: mark@site.com\nTags: hot", ""},
+		{"Name: Mark\nTags: hot", `No match for "Lead - email"`},
+		{"Name: Mark\nEmail: \nTags: hot", `No match for "Lead - email"`},
+		{"Name: Mark\nEmail: mark@site.com\nTags: , ", `No match for "Lead - tags"`},
+	}
+	for _, tt := range tests {
+		_, err := documents[0].Search(tt.content)
+		if (tt.err == "" && err != nil) || (tt.err != "" && (err == nil || err.Error() != tt.err)) {
+			t.Errorf("%q: want error %q got %v", tt.content, tt.err, err)
+		}
+	}
+
+	fields, err := documents.Search("Name: Mark\nTags: hot")
+	if err != nil || !reflect.DeepEqual(fields, docparser.Fields{"name": "Mark"}) {
+		t.Errorf("want Fallback to match, got %v (%v)", fields, err)
+	}
+
+	documents[0].Finalize = func(f docparser.Fields) (docparser.Fields, error) {
+		f["email"] = "unknown@site.com"
+		return f, nil
+	}
+	if _, err := documents[0].Search("Name: Mark\nTags: hot"); err != nil {
+		t.Errorf("RequiredFields should be checked after Finalize: %v", err)
+	}
+}
+
+func TestDocumentMinMatches(t *testing.T) {
+	document := &docparser.Document{
+		Name: "Lead",
+		Patterns: []docparser.Pattern{
+			&docparser.PatternGroup{Regex: regexp.MustCompile(`Name: (?P<name>.*)`)},
+			&docparser.PatternGroup{Regex: regexp.MustCompile(`Phone: (?P<phone>.*)`)},
+			&docparser.PatternGroup{Regex: regexp.MustCompile(`Email: (?P<email>.*)`), Optional: true},
+			&docparser.PatternGroup{
+				Regex:      regexp.MustCompile(`Status: (?P<status>.*)`),
+				EnumFields: map[string][]string{"status": {"new"}},
+				Optional:   true,
+			},
+		},
+		MinMatches: 2,
+	}
+	var tests = []struct {
+		content string
+		want    docparser.Fields
+		err     string
+	}{
+		{"Name: Mark\nPhone: 221-1122", docparser.Fields{"name": "Mark", "phone": "221-1122"}, ""},
+		{"Name: Mark\nEmail: mark@site.com", docparser.Fields{"name": "Mark", "email": "mark@site.com"}, ""},
+		{"Name: Mark", nil, `No match for "Lead - min matches"`},
+		{"Email: mark@site.com\nStatus: old", nil, `Invalid field "status" for "": "old" is not one of ["new"]`},
+	}
+	for _, tt := range tests {
+		fields, err := document.Search(tt.content)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("%q: want error %q got %v", tt.content, tt.err, err)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(fields, tt.want) {
+			t.Errorf("%q: want %v got %v (%v)", tt.content, tt.want, fields, err)
+		}
+	}
+
+	document.RequiredFields = []string{"phone"}
+	if _, err := document.Search("Name: Mark\nEmail: mark@site.com"); err == nil || err.Error() != `No match for "Lead - phone"` {
+		t.Errorf("RequiredFields should still apply: %v", err)
+	}
+	document.RequiredFields = nil
+
+	_, meta, err := document.SearchWithMeta("Email: mark@site.com\nName: Mark")
+	if err != nil || !reflect.DeepEqual(meta.Patterns, []bool{true, false, true, false}) {
+		t.Errorf("invalid meta %v (%v)", meta.Patterns, err)
+	}
+	stats := &docparser.Stats{}
+	document.SearchWithStats("Email: mark@site.com\nName: Mark", stats)
+	want := []docparser.Counts{{Matched: 1}, {Failed: 1}, {Matched: 1}, {Matched: 1}}
+	if got := stats.Documents()["Lead"].Patterns; !reflect.DeepEqual(got, want) {
+		t.Errorf("stats want %v got %v", want, got)
+	}
+
+	single := &docparser.Document{
+		Name:       "Single",
+		Patterns:   []docparser.Pattern{&docparser.PatternGroup{Regex: regexp.MustCompile(`Name: (?P<name>.*)`)}},
+		MinMatches: 1,
+	}
+	if _, err := single.Search("Phone: 221-1122"); err == nil || err.Error() != `No match for "Single - min matches"` {
+		t.Errorf("single pattern want min matches error got %v", err)
+	}
+}
+
+func TestDocumentSinglePattern(t *testing.T) {
+	document := &docparser.Document{
+		Patterns: []docparser.Pattern{
+			&docparser.PatternGroup{Regex: regexp.MustCompile(`Name: (?P<name>.*)\n`)},
+		},
+		Finalize: func(f docparser.Fields) (docparser.Fields, error) {
+			f["greeting"] = "Hi " + f.GetString("name")
+			return f, nil
+		},
+	}
+	fields, err := document.Search("Name: bob\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := docparser.Fields{"name": "bob", "greeting": "Hi bob"}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("want %v got %v", want, fields)
+	}
+	if _, err := document.Search("nothing"); err == nil {
+		t.Error("did not return error")
+	}
+}
+
+func TestNewDocument(t *testing.T) {
+	document := docparser.NewDocument(testDocuments[0].Patterns...)
+	fields, err := document.Search("Name: bob\nEmail: bob@site.com\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := docparser.Fields{"name": "bob", "email": "bob@site.com"}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("want %v got %v", want, fields)
+	}
+}
+
+func TestDocumentPreprocess(t *testing.T) {
+	document := &docparser.Document{
+		Patterns: testDocuments[0].Patterns,
+		Preprocess: []docparser.Preprocessor{
+			func(content string) (string, error) {
+				return strings.Replace(content, "E-mail", "Email", -1), nil
+			},
+			func(content string) (string, error) {
+				return content + "Email: second@site.com\n", nil
+			},
+		},
+	}
+
+	fields, err := document.Search("Name: Bob\nE-mail: bob@site.com\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if email := fields.GetString("email"); email != "bob@site.com" {
+		t.Errorf("want email %q got %q", "bob@site.com", email)
+	}
+
+	fields, err = document.Search("Name: Bob\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if email := fields.GetString("email"); email != "second@site.com" {
+		t.Errorf("want email %q got %q", "second@site.com", email)
+	}
+}
+
+func TestDocumentPreprocessError(t *testing.T) {
+	document := &docparser.Document{
+		Patterns: testDocuments[0].Patterns,
+		Preprocess: []docparser.Preprocessor{
+			func(content string) (string, error) {
+				return "", errors.New("bad content")
+			},
+		},
+	}
+
+	_, err := document.Search("Name: Bob\nEmail: bob@site.com\n")
+	if err == nil || err.Error() != "bad content" {
+		t.Errorf("want preprocessor error, got %v", err)
+	}
+}
+
+func TestTemplatePatternGroupUnmatchedGroups(t *testing.T) {
+	pattern := &docparser.TemplatePatternGroup{
+		Name:          "Address",
+		RegexTemplate: `Street: (?P<street>[^\n]*)\n(?:Unit: (?P<unit>[^\n]*)\n)?`,
+	}
+	var tests = []struct {
+		content string
+		hasUnit bool
+		unit    string
+	}{
+		{"Street: 331 Kailua Rd\nUnit: 2B\n", true, "2B"},
+		{"Street: 331 Kailua Rd\nUnit: \n", true, ""},
+		{"Street: 331 Kailua Rd\n", false, ""},
+	}
+	for _, tt := range tests {
+		fields, err := pattern.Search(tt.content)
+		if err != nil {
+			t.Errorf("content %q failed: %s", tt.content, err)
+			continue
+		}
+		if !fields.Has("street") {
+			t.Errorf("content %q should have street", tt.content)
+		}
+		if fields.Has("unit") != tt.hasUnit || fields.GetString("unit") != tt.unit {
+			t.Errorf("content %q want unit %v %q got %v", tt.content, tt.hasUnit, tt.unit, fields)
+		}
+	}
+}
+
+func TestFieldsHas(t *testing.T) {
+	f := docparser.Fields{"empty": "", "name": "bob"}
+	for key, want := range map[string]bool{"empty": true, "name": true, "missing": false} {
+		if got := f.Has(key); got != want {
+			t.Errorf("Has(%q) want %v got %v", key, want, got)
+		}
+	}
+}
+
+func TestDocumentAccumulateKeys(t *testing.T) {
+	phone := func(label string) docparser.Pattern {
+		return &docparser.PatternGroup{
+			Name:     label,
+			Regex:    regexp.MustCompile(label + `: (?P<phone>.*)\n`),
+			Optional: true,
+		}
+	}
+	document := &docparser.Document{
+		Patterns: []docparser.Pattern{
+			&docparser.PatternGroup{Regex: regexp.MustCompile(`Name: (?P<name>.*)\n`)},
+			phone("Home"),
+			&docparser.PatternGroup{Regex: regexp.MustCompile(`Alias: (?P<name>.*)\n`)},
+			phone("Work"),
+			phone("Cell"),
+		},
+		AccumulateKeys: []string{"phone"},
+	}
+	var tests = []struct {
+		content string
+		want    docparser.Fields
+	}{
+		{
+			"Name: bob\nHome: 111\nAlias: rob\nWork: 222\nCell: 333\n",
+			docparser.Fields{"name": "rob", "phone": []string{"111", "222", "333"}},
+		},
+		{
+			"Name: bob\nAlias: rob\nWork: 222\n",
+			docparser.Fields{"name": "rob", "phone": "222"},
+		},
+		{
+			"Name: bob\nAlias: rob\n",
+			docparser.Fields{"name": "rob"},
+		},
+	}
+	for _, tt := range tests {
+		fields, err := document.Search(tt.content)
+		if err != nil {
+			t.Errorf("content %q failed: %s", tt.content, err)
+			continue
+		}
+		if !reflect.DeepEqual(fields, tt.want) {
+			t.Errorf("content %q want %v got %v", tt.content, tt.want, fields)
+		}
+	}
+}
+
+func TestDocumentAccumulateKeysMixedTypes(t *testing.T) {
+	value := func(v interface{}) docparser.Pattern {
+		return &constantPattern{docparser.Fields{"k": v}}
+	}
+	document := &docparser.Document{
+		Patterns:       []docparser.Pattern{value(1.5), value("abc"), value([]string{"d", "e"}), value(2)},
+		AccumulateKeys: []string{"k"},
+	}
+	fields, err := document.Search("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{1.5, "abc", "d", "e", 2}
+	if !reflect.DeepEqual(fields["k"], want) {
+		t.Errorf("want %#v got %#v", want, fields["k"])
+	}
+
+	// Patterns may return Fields they keep, merge must not change them
+	kept := docparser.Fields{"k": "abc"}
+	document.Patterns[1] = &sharedPattern{kept}
+	if _, err := document.Search(""); err != nil {
+		t.Fatal(err)
+	}
+	if v := kept["k"]; v != "abc" {
+		t.Errorf("pattern fields modified: %#v", v)
+	}
+}
+
+// sharedPattern returns its fields without copying them
+type sharedPattern struct {
+	fields docparser.Fields
+}
+
+func (sp *sharedPattern) Search(content string) (docparser.Fields, error) {
+	return sp.fields, nil
+}
+
+func TestFieldsFlatten(t *testing.T) {
+	f := docparser.Fields{
+		"name":   "bob",
+		"phones": []string{"111", "222"},
+		"properties": []docparser.Fields{
+			{"mls": "2211", "agent": docparser.Fields{"name": "jane"}},
+			{"mls": "9090"},
+		},
+		"beds":  3,
+		"empty": []docparser.Fields{},
+	}
+	want := map[string]string{
+		"name":                    "bob",
+		"phones.0":                "111",
+		"phones.1":                "222",
+		"properties.0.mls":        "2211",
+		"properties.0.agent.name": "jane",
+		"properties.1.mls":        "9090",
+		"beds":                    "3",
+	}
+	if flat := f.Flatten("."); !reflect.DeepEqual(flat, want) {
+		t.Errorf("want %v got %v", want, flat)
+	}
+	if flat := f.Flatten("_"); flat["properties_0_agent_name"] != "jane" {
+		t.Errorf("custom separator not used: %v", flat)
+	}
+}
+
+func TestFieldsTruncateRunes(t *testing.T) {
+	var tests = []struct {
+		value interface{}
+		n     int
+		want  interface{}
+	}{
+		{"Kailua", 3, "Kai"},
+		{"Kailua", 6, "Kailua"},
+		{"Kailua", 10, "Kailua"},
+		{"Kailua", 0, ""},
+		{"Ha\u02bbik\u016b Rd", 4, "Ha\u02bbi"},
+		{"Jos\u00e9", 4, "Jos\u00e9"},
+		{"", 2, ""},
+		{[]string{"Kailua"}, 1, []string{"Kailua"}},
+	}
+	for _, tt := range tests {
+		f := docparser.Fields{"city": tt.value}
+		f.TruncateRunes("city", tt.n)
+		if !reflect.DeepEqual(f["city"], tt.want) {
+			t.Errorf("value %q n %d want %q got %q", tt.value, tt.n, tt.want, f["city"])
+		}
+	}
+
+	f := docparser.Fields{}
+	f.TruncateRunes("missing", 1)
+	if f.Has("missing") {
+		t.Errorf("missing key should not be added: %v", f)
+	}
+}
+
+func TestFieldsClone(t *testing.T) {
+	f := docparser.Fields{
+		"name":   "bob",
+		"phones": []string{"111", "222"},
+		"agent":  docparser.Fields{"name": "jane"},
+		"properties": []docparser.Fields{
+			{"mls": "2211", "agent": docparser.Fields{"name": "jane"}},
+		},
+		"rooms": []map[string]string{{"type": "bed"}},
+		"beds":  3,
+	}
+	clone := f.Clone()
+	if !reflect.DeepEqual(clone, f) {
+		t.Fatalf("want %v got %v", f, clone)
+	}
+
+	clone["name"] = "mark"
+	clone["phones"].([]string)[0] = "999"
+	clone["agent"].(docparser.Fields)["name"] = "mark"
+	clone["properties"].([]docparser.Fields)[0]["agent"].(docparser.Fields)["name"] = "mark"
+	clone["rooms"].([]map[string]string)[0]["type"] = "bath"
+
+	want := docparser.Fields{
+		"name":   "bob",
+		"phones": []string{"111", "222"},
+		"agent":  docparser.Fields{"name": "jane"},
+		"properties": []docparser.Fields{
+			{"mls": "2211", "agent": docparser.Fields{"name": "jane"}},
+		},
+		"rooms": []map[string]string{{"type": "bed"}},
+		"beds":  3,
+	}
+	if !reflect.DeepEqual(f, want) {
+		t.Errorf("original modified: %v", f)
+	}
+}
+
+func TestFieldsSubset(t *testing.T) {
+	f := docparser.Fields{
+		"name":       "bob",
+		"phone":      "221-1122",
+		"properties": []docparser.Fields{{"mls": "2211"}},
+	}
+	subset := f.Subset("name", "properties", "missing")
+	want := docparser.Fields{"name": "bob", "properties": []docparser.Fields{{"mls": "2211"}}}
+	if !reflect.DeepEqual(subset, want) {
+		t.Fatalf("want %v got %v", want, subset)
+	}
+
+	subset["name"] = "mark"
+	subset["properties"].([]docparser.Fields)[0]["mls"] = "1122"
+	if f.GetString("name") != "bob" {
+		t.Errorf("original key modified: %v", f)
+	}
+	if f["properties"].([]docparser.Fields)[0]["mls"] != "1122" {
+		t.Errorf("nested fields not shared: %v", f)
+	}
+}
+
+func ExampleDocument_finalize() {
+	document := &docparser.Document{
+		Patterns: []docparser.Pattern{
+			&docparser.PatternGroup{Regex: regexp.MustCompile(`First: (?P<first>.*)\n`)},
+			&docparser.PatternGroup{Regex: regexp.MustCompile(`Last: (?P<last>.*)\n`)},
+		},
+		Finalize: func(f docparser.Fields) (docparser.Fields, error) {
+			f["name"] = f.GetString("first") + " " + f.GetString("last")
+			delete(f, "first")
+			delete(f, "last")
+			return f, nil
+		},
+	}
+
+	fields, err := document.Search("First: Mark\nLast: Stewart\n")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(fields.Keys())
+	fmt.Println(fields.GetString("name"))
+	// Output:
+	// [name]
+	// Mark Stewart
+}
+
+func TestDocumentFinalizeError(t *testing.T) {
+	document := &docparser.Document{
+		Patterns: testDocuments[0].Patterns,
+		Finalize: func(f docparser.Fields) (docparser.Fields, error) {
+			if !strings.HasSuffix(f.GetString("email"), ".com") {
+				return docparser.Fields{}, errors.New("invalid email")
+			}
+			return f, nil
+		},
+	}
+	if _, err := document.Search("Name: bob\nEmail: bob@site.com\n"); err != nil {
+		t.Errorf("valid email failed: %s", err)
+	}
+	if _, err := document.Search("Name: bob\nEmail: bob@site\n"); err == nil || err.Error() != "invalid email" {
+		t.Errorf("want Finalize error, got %v", err)
+	}
+}
+
+func TestDocumentValidate(t *testing.T) {
+	document := &docparser.Document{
+		Patterns: []docparser.Pattern{
+			&docparser.PatternGroup{
+				Name:  "Contact",
+				Regex: regexp.MustCompile(`Name: (?P<name>.*)\nPhone: (?P<phone>.*)\n`),
+			},
+			&docparser.PatternGroup{
+				Regex: regexp.MustCompile(`Cell: (?P<phone>.*)\n`),
+			},
+			&docparser.TemplatePatternGroup{
+				Name:          "Greeting",
+				RegexTemplate: `Hi {name}, (?P<name>.*)\n`,
+			},
+			&docparser.PatternList{
+				Name:       "Phones",
+				ListRegex:  regexp.MustCompile(`(?s:Phones:\n(?P<phone>.*))`),
+				SplitRegex: regexp.MustCompile(`\n`),
+				ItemRegex:  regexp.MustCompile(`(?P<number>.*)`),
+			},
+		},
+	}
+
+	err := document.Validate()
+	if err == nil {
+		t.Fatal("duplicate fields not reported")
+	}
+	want := `field "phone" returned by pattern 0 ("Contact") and pattern 1; ` +
+		`field "name" returned by pattern 0 ("Contact") and pattern 2 ("Greeting"); ` +
+		`field "phone" returned by pattern 0 ("Contact") and pattern 3 ("Phones")`
+	if err.Error() != want {
+		t.Errorf("invalid error:\n%s\nwant:\n%s", err, want)
+	}
+
+	document.AccumulateKeys = []string{"phone", "name"}
+	if err := document.Validate(); err != nil {
+		t.Errorf("AccumulateKeys should be allowed to collide: %s", err)
+	}
+
+	for _, doc := range testDocuments {
+		if err := doc.Validate(); err != nil {
+			t.Errorf("valid document failed: %s", err)
+		}
+	}
+}
+
+func TestDocumentFieldNames(t *testing.T) {
+	document := &docparser.Document{
+		Patterns: []docparser.Pattern{
+			testDocuments[0],
+			&docparser.PatternList{
+				ListRegex:  regexp.MustCompile(`(?s:Properties:\n(?P<properties>.*))`),
+				SplitRegex: regexp.MustCompile(`\n`),
+				ItemRegex:  regexp.MustCompile(`(?P<mls>.*)`),
+			},
+			&docparser.PatternConditional{
+				Guard: regexp.MustCompile(`Phone`),
+				Then:  &docparser.PatternGroup{Regex: regexp.MustCompile(`Phone: (?P<phone>.*)(?P<name>)`)},
+			},
+		},
+	}
+	want := []string{"name", "email", "properties", "phone"}
+	if names := document.FieldNames(); !reflect.DeepEqual(names, want) {
+		t.Errorf("want %v got %v", want, names)
+	}
+}
+
+func TestPatternGroupIncludeFullMatch(t *testing.T) {
+	pattern := &docparser.PatternGroup{
+		Regex:            regexp.MustCompile(`Name: (?P<name>.*) \n`),
+		TrimSpace:        true,
+		IncludeFullMatch: true,
+	}
+	fields, err := pattern.Search("Lead\nName: bob  \nEmail: bob@site.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := docparser.Fields{"name": "bob", "_match": "Name: bob  \n"}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("want %v got %v", want, fields)
+	}
+
+	pattern.FullMatchKey = "raw"
+	fields, err = pattern.Search("Name: bob \n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw := fields.GetString("raw"); raw != "Name: bob \n" || fields.Has("_match") {
+		t.Errorf("want full match in %q, got %v", "raw", fields)
+	}
+}
+
+func TestPatternListIncludeFullMatch(t *testing.T) {
+	pattern := &docparser.PatternList{
+		ListRegex:        regexp.MustCompile(`(?s:Items:\n(?P<items>.*))`),
+		SplitRegex:       regexp.MustCompile(`\n`),
+		ItemRegex:        regexp.MustCompile(`- (?P<name>.*)`),
+		IncludeFullMatch: true,
+	}
+	fields, err := pattern.Search("Items:\n- a\n- b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := docparser.Fields{
+		"_match": "- a\n- b",
+		"items": []docparser.Fields{
+			{"name": "a", "_match": "- a"},
+			{"name": "b", "_match": "- b"},
+		},
+	}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("want %v got %v", want, fields)
+	}
+}
+
+func TestFieldsGetOr(t *testing.T) {
+	f := docparser.Fields{
+		"name":   "bob",
+		"empty":  "",
+		"beds":   " 3 ",
+		"baths":  "2.5",
+		"active": "true",
+		"pool":   "maybe",
+		"list":   []docparser.Fields{},
+	}
+
+	for key, want := range map[string]string{"name": "bob", "empty": "N/A", "missing": "N/A", "list": "N/A"} {
+		if got := f.GetStringOr(key, "N/A"); got != want {
+			t.Errorf("GetStringOr(%q) want %q got %q", key, want, got)
+		}
+	}
+	for key, want := range map[string]int{"beds": 3, "baths": -1, "name": -1, "missing": -1} {
+		if got := f.GetIntOr(key, -1); got != want {
+			t.Errorf("GetIntOr(%q) want %d got %d", key, want, got)
+		}
+	}
+	if !f.GetBoolOr("active", false) || !f.GetBoolOr("pool", true) || f.GetBoolOr("missing", false) {
+		t.Errorf("GetBoolOr returned invalid values")
+	}
+}
+
+func TestPatternListSkipUnmatchedItems(t *testing.T) {
+	pattern := &docparser.PatternList{
+		Name:       "Items",
+		ListRegex:  regexp.MustCompile(`(?s:Items:\n(?P<items>.*))`),
+		SplitRegex: regexp.MustCompile(`\n`),
+		ItemRegex:  regexp.MustCompile(`^- (?P<name>.*)`),
+	}
+	content := "Items:\n- a\n---\n- b\n\nUnsubscribe\n"
+
+	if _, err := pattern.Search(content); err == nil || err.Error() != `No match for "Items - item 1"` {
+		t.Errorf("strict list want NoMatch, got %v", err)
+	}
+
+	pattern.SkipUnmatchedItems = true
+	fields, err := pattern.Search(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]string{{"name": "a"}, {"name": "b"}}
+	if items := fields.GetMapSlice("items"); !reflect.DeepEqual(items, want) {
+		t.Errorf("want %v got %v", want, items)
+	}
+}
+
+func TestPatternListTrailingCarriageReturn(t *testing.T) {
+	pattern := &docparser.PatternList{
+		ListRegex:  regexp.MustCompile(`(?s:Items:\r\n(?P<items>.*))`),
+		SplitRegex: regexp.MustCompile(`\n`),
+		ItemRegex:  regexp.MustCompile(`- (?P<name>.*)`),
+	}
+	fields, err := pattern.Search("Items:\r\n- a\r\n- b\r\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]string{{"name": "a"}, {"name": "b"}}
+	if items := fields.GetMapSlice("items"); !reflect.DeepEqual(items, want) {
+		t.Errorf("want %v got %v", want, items)
+	}
+}
+
+func TestPatternListWithoutSplitRegex(t *testing.T) {
+	pattern := &docparser.PatternList{
+		ListRegex:        regexp.MustCompile(`(?s:Listings: (?P<listings>.*))`),
+		ItemRegex:        regexp.MustCompile(`MLS #(?P<mls>\d+) \$(?P<price>[\d,]+)`),
+		IncludeFullMatch: true,
+	}
+	fields, err := pattern.Search("Listings: MLS #221 $450,000 MLS #1122 $1,200,000 (new)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]string{
+		{"mls": "221", "price": "450,000", "_match": "MLS #221 $450,000"},
+		{"mls": "1122", "price": "1,200,000", "_match": "MLS #1122 $1,200,000"},
+	}
+	if items := fields.GetMapSlice("listings"); !reflect.DeepEqual(items, want) {
+		t.Errorf("want %v got %v", want, items)
+	}
+}
+
+func TestPatternListFilterItem(t *testing.T) {
+	pattern := &docparser.PatternList{
+		ListRegex:  regexp.MustCompile(`(?s:Items:\n(?P<items>.*))`),
+		SplitRegex: regexp.MustCompile(`\n`),
+		ItemRegex:  regexp.MustCompile(`(?P<name>\w+) (?P<price>\d+)`),
+		CleanItem: func(f docparser.Fields) docparser.Fields {
+			f["name"] = strings.ToUpper(f.GetString("name"))
+			return f
+		},
+		FilterItem: func(f docparser.Fields) bool {
+			return f.GetString("name") != "FOOTER" && len(f.GetString("price")) > 2
+		},
+	}
+	fields, err := pattern.Search("Items:\na 100\nb 20\nc 300\nfooter 100\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]string{{"name": "A", "price": "100"}, {"name": "C", "price": "300"}}
+	if items := fields.GetMapSlice("items"); !reflect.DeepEqual(items, want) {
+		t.Errorf("want %v got %v", want, items)
+	}
+}
+
+func TestPatternGroupNumberedGroups(t *testing.T) {
+	pattern := &docparser.PatternGroup{
+		Regex:          regexp.MustCompile(`(\w+) (?P<last>\w+)(?:, (Jr|Sr))?(?: \((\d+)\))?`),
+		NumberedGroups: true,
+	}
+	fields, err := pattern.Search("Mark Stewart, Jr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := docparser.Fields{"_1": "Mark", "last": "Stewart", "_3": "Jr", "_4": ""}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("want %v got %v", want, fields)
+	}
+	wantNames := []string{"last", "_1", "_3", "_4"}
+	if names := pattern.FieldNames(); !reflect.DeepEqual(names, wantNames) {
+		t.Errorf("want %v got %v", wantNames, names)
+	}
+}
+
+func TestPatternListMaxItems(t *testing.T) {
+	pattern := &docparser.PatternList{
+		Name:               "Items",
+		ListRegex:          regexp.MustCompile(`(?s:Items:\n(?P<items>.*))`),
+		SplitRegex:         regexp.MustCompile(`\n`),
+		ItemRegex:          regexp.MustCompile(`^- (?P<name>.*)`),
+		SkipUnmatchedItems: true,
+		MaxItems:           2,
+	}
+	content := "Items:\n- a\n---\n- b\n- c\n"
+
+	fields, err := pattern.Search(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]string{{"name": "a"}, {"name": "b"}}
+	if items := fields.GetMapSlice("items"); !reflect.DeepEqual(items, want) {
+		t.Errorf("want %v got %v", want, items)
+	}
+
+	pattern.ErrorOnMaxItems = true
+	if _, err := pattern.Search(content); err == nil || err.Error() != "too many items for Items: more than 2" {
+		t.Errorf("invalid error %v", err)
+	}
+	if _, err := pattern.Search("Items:\n- a\n- b\n"); err != nil {
+		t.Errorf("list at the limit failed: %v", err)
+	}
+}
+
+func TestPatternListSplitBefore(t *testing.T) {
+	pattern := &docparser.PatternList{
+		Name:        "Listings",
+		ListRegex:   regexp.MustCompile(`(?s:Listings:\s*(?P<listings>.*))`),
+		SplitRegex:  regexp.MustCompile(`MLS #`),
+		SplitBefore: true,
+		ItemRegex:   regexp.MustCompile(`(?s:^MLS #(?P<mls>\d+)\s+(?P<address>.*?)\s*$)`),
+	}
+	fields, err := pattern.Search("Listings: MLS #221 1 Main St\nMLS #1122 2 Oak Ave MLS #3 x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]string{
+		{"mls": "221", "address": "1 Main St"},
+		{"mls": "1122", "address": "2 Oak Ave"},
+		{"mls": "3", "address": "x"},
+	}
+	if items := fields.GetMapSlice("listings"); !reflect.DeepEqual(items, want) {
+		t.Errorf("want %v got %v", want, items)
+	}
+
+	if _, err := pattern.Search("Listings: new MLS #221 1 Main St"); err == nil || err.Error() != `No match for "Listings - item 0"` {
+		t.Errorf("text before the first match want NoMatch, got %v", err)
+	}
+}
+
+func TestEmptyContent(t *testing.T) {
+	required := &docparser.PatternGroup{Name: "Name", Regex: regexp.MustCompile(`Name: (?P<name>.*)`)}
+	optional := &docparser.PatternGroup{Regex: regexp.MustCompile(`Phone: (?P<phone>.*)`), Optional: true}
+	list := &docparser.PatternList{
+		Name:       "Items",
+		ListRegex:  regexp.MustCompile(`(?s:Items:\n(?P<items>.*))`),
+		SplitRegex: regexp.MustCompile(`\n`),
+		ItemRegex:  regexp.MustCompile(`- (?P<name>.*)`),
+	}
+
+	for _, content := range []string{"", " \n\t\n"} {
+		if _, err := required.Search(content); err == nil || err.Error() != `No match for "Name"` {
+			t.Errorf("%q: required group want NoMatch, got %v", content, err)
+		}
+		if _, err := list.Search(content); err == nil || err.Error() != `No match for "Items - list regex"` {
+			t.Errorf("%q: required list want NoMatch, got %v", content, err)
+		}
+		if fields, err := optional.Search(content); err != nil || len(fields) != 0 {
+			t.Errorf("%q: optional group want empty fields, got %v (%v)", content, fields, err)
+		}
+
+		optionalDoc := &docparser.Document{Patterns: []docparser.Pattern{optional}}
+		if fields, err := optionalDoc.Search(content); err != nil || len(fields) != 0 {
+			t.Errorf("%q: optional document want empty fields, got %v (%v)", content, fields, err)
+		}
+		documents := docparser.Documents{
+			{Patterns: []docparser.Pattern{required}},
+			{Patterns: []docparser.Pattern{optional, list}},
+		}
+		_, err := documents.Search(content)
+		if errList, ok := err.(*docparser.ErrorList); !ok || len(*errList) != 2 {
+			t.Errorf("%q: documents want ErrorList with 2 errors, got %#v", content, err)
+		}
+
+		blank := &docparser.Document{Name: "Lead", Patterns: []docparser.Pattern{optional}, SkipIf: regexp.MustCompile(`^\s*$`)}
+		if _, err := blank.Search(content); err == nil || err.Error() != `No match for "Lead - skipped"` {
+			t.Errorf("%q: SkipIf want NoMatch, got %v", content, err)
+		}
+	}
+}
+
+func ExamplePatternGroup_Raw() {
+	pattern := &docparser.PatternGroup{
+		Regex: regexp.MustCompile(`(\d+) beds?, (?P<baths>\d+) baths?`),
+	}
+
+	matches, names, ok := pattern.Raw("Listing: 3 beds, 2 baths")
+	if !ok {
+		panic("no match")
+	}
+
+	for i := range matches {
+		fmt.Printf("%d %q %q\n", i, names[i], matches[i])
+	}
+	// Output:
+	// 0 "" "3 beds, 2 baths"
+	// 1 "" "3"
+	// 2 "baths" "2"
+}
+
+func TestPatternGroupRaw(t *testing.T) {
+	pattern := &docparser.PatternGroup{Regex: regexp.MustCompile(`(?P<a>x)(y)?`), Line: 2}
+	matches, names, ok := pattern.Raw("x\nz")
+	if !ok || !reflect.DeepEqual(matches, []string{"x", "x", ""}) || !reflect.DeepEqual(names, []string{"", "a", ""}) {
+		t.Errorf("invalid result %q %q %v", matches, names, ok)
+	}
+	if matches, _, ok := pattern.Raw("z"); ok || matches != nil {
+		t.Errorf("want no match got %q", matches)
+	}
+}
+
+func TestPatternListDefaultsAndLookupFields(t *testing.T) {
+	pattern := &docparser.PatternList{
+		Name:       "Listings",
+		ListRegex:  regexp.MustCompile(`(?s:Listings:\n(?P<listings>.*))`),
+		SplitRegex: regexp.MustCompile(`\n`),
+		ItemRegex:  regexp.MustCompile(`^(?P<mls>\d+)(?: (?P<status>\w))?`),
+		CleanItem: func(f docparser.Fields) docparser.Fields {
+			if f.GetString("mls") == "3" {
+				delete(f, "status")
+			}
+			return f
+		},
+		Defaults:     map[string]string{"status": "A", "source": "mls"},
+		LookupFields: map[string]map[string]string{"status": {"A": "Active", "S": "Sold"}},
+	}
+	fields, err := pattern.Search("Listings:\n1 S\n2\n3 S\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]string{
+		{"mls": "1", "status": "Sold", "source": "mls"},
+		{"mls": "2", "status": "Active", "source": "mls"},
+		{"mls": "3", "status": "Active", "source": "mls"},
+	}
+	if items := fields.GetMapSlice("listings"); !reflect.DeepEqual(items, want) {
+		t.Errorf("want %v got %v", want, items)
+	}
+
+	pattern.StrictLookup = true
+	_, err = pattern.Search("Listings:\n1 S\n2 X\n")
+	if err == nil || err.Error() != `Invalid field "status" for "Listings - item 1": unknown value "X"` {
+		t.Errorf("invalid error %v", err)
+	}
+}
+
+// lowerASCII is a Folder for ASCII content that lowercases it
+func lowerASCII(content string) (string, []int) {
+	offsets := make([]int, len(content)+1)
+	for i := range offsets {
+		offsets[i] = i
+	}
+	return strings.ToLower(content), offsets
+}
+
+// Matching case insensitively must never change the case of the
+// captured values, which are always taken from the original content
+func TestCaseInsensitiveMatchKeepsValues(t *testing.T) {
+	content := "NAME: Mark STEWART\nItems:\n- Blue HOUSE\nEMAIL: Bob@Site.com\n"
+	tmpl, err := docparser.TemplateRegex("email: {email}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	patterns := []docparser.Pattern{
+		&docparser.PatternGroup{Regex: regexp.MustCompile(`(?i)name: (?P<name>.*)`)},
+		&docparser.PatternGroup{Regex: regexp.MustCompile(`name: (?P<folded>.*)`), Fold: lowerASCII},
+		&docparser.PatternList{
+			ListRegex:  regexp.MustCompile(`(?is:items:\n(?P<items>.*?)\n[a-z]+:)`),
+			SplitRegex: regexp.MustCompile(`\n`),
+			ItemRegex:  regexp.MustCompile(`(?i)- (?P<item>.*)`),
+		},
+		&docparser.PatternGroup{Regex: regexp.MustCompile(`(?i)` + tmpl.String())},
+		&docparser.PatternKeyValue{Labels: map[string][]string{"kv_name": {"name"}}},
+	}
+	want := map[string]string{
+		"name":         "Mark STEWART",
+		"folded":       "Mark STEWART",
+		"items.0.item": "Blue HOUSE",
+		"email":        "Bob@Site.com",
+		"kv_name":      "Mark STEWART",
+	}
+	document := &docparser.Document{Patterns: patterns}
+	fields, err := document.Search(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	flat := fields.Flatten(".")
+	for key, value := range want {
+		if flat[key] != value {
+			t.Errorf("%s: want %q got %q", key, value, flat[key])
+		}
+	}
+}
+
+func TestPatternGroupOmitUnmatched(t *testing.T) {
+	pattern := &docparser.PatternGroup{
+		Regex: regexp.MustCompile(`Address: (?P<street>[^,\n]*)(?:, Unit (?P<unit>\w*))?(?:, (?P<city>\w+))?`),
+	}
+	content := "Address: 1 Main St, Unit , Kailua"
+	fields, err := pattern.Search(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (docparser.Fields{"street": "1 Main St", "unit": "", "city": "Kailua"}); !reflect.DeepEqual(fields, want) {
+		t.Errorf("want %v got %v", want, fields)
+	}
+
+	pattern.OmitUnmatched = true
+	for _, tt := range []struct {
+		content string
+		want    docparser.Fields
+	}{
+		{content, docparser.Fields{"street": "1 Main St", "unit": "", "city": "Kailua"}},
+		{"Address: 1 Main St", docparser.Fields{"street": "1 Main St"}},
+	} {
+		fields, err := pattern.Search(tt.content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(fields, tt.want) {
+			t.Errorf("%q: want %v got %v", tt.content, tt.want, fields)
+		}
+	}
+}
+
+func TestPatternGroupWholeMatchKey(t *testing.T) {
+	var tests = []struct {
+		regex string
+		want  docparser.Fields
+	}{
+		{`[A-Z]{2}-\d{4}`, docparser.Fields{"order": "AB-1234"}},
+		{`(?i)order (?:\w+)`, docparser.Fields{"order": "Order AB"}},
+		{`Order (?P<prefix>[A-Z]+)-\d+`, docparser.Fields{"prefix": "AB"}},
+	}
+	for _, tt := range tests {
+		pattern := &docparser.PatternGroup{
+			Regex:         regexp.MustCompile(tt.regex),
+			WholeMatchKey: "order",
+		}
+		fields, err := pattern.Search("Order AB-1234 shipped")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(fields, tt.want) {
+			t.Errorf("%s: want %v got %v", tt.regex, tt.want, fields)
+		}
+		if names := pattern.FieldNames(); len(names) != 1 || fields[names[0]] == nil {
+			t.Errorf("%s: invalid field names %v", tt.regex, names)
+		}
+	}
+
+	pattern := &docparser.PatternGroup{
+		Regex:         regexp.MustCompile(`#\s*\d+\s*`),
+		WholeMatchKey: "ticket",
+		TrimSpace:     true,
+	}
+	fields, err := pattern.Search("Ticket # 42 \n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fields.GetString("ticket"); got != "# 42" {
+		t.Errorf("want trimmed ticket got %q", got)
+	}
+}
+
+func TestPatternGroupOccurrence(t *testing.T) {
+	content := "Office: 221-1122\nCell: 221-3344\nFax: 221-5566\n"
+	var tests = []struct {
+		occurrence int
+		want       string
+	}{
+		{0, "221-1122"},
+		{1, "221-1122"},
+		{2, "221-3344"},
+		{3, "221-5566"},
+		{4, ""},
+	}
+	for _, tt := range tests {
+		pattern := &docparser.PatternGroup{
+			Name:       "Phone",
+			Regex:      regexp.MustCompile(`\w+: (?P<phone>[\d-]+)`),
+			Occurrence: tt.occurrence,
+		}
+		fields, err := pattern.Search(content)
+		spans, spanErr := pattern.SearchSpans(content)
+		if tt.want == "" {
+			if err == nil || err.Error() != `No match for "Phone"` || spanErr == nil {
+				t.Errorf("occurrence %d: want NoMatch got %v / %v", tt.occurrence, err, spanErr)
+			}
+			continue
+		}
+		if err != nil || spanErr != nil {
+			t.Fatal(err, spanErr)
+		}
+		if got := fields.GetString("phone"); got != tt.want {
+			t.Errorf("occurrence %d: want %q got %q", tt.occurrence, tt.want, got)
+		}
+		if span := spans["phone"]; content[span[0]:span[1]] != tt.want {
+			t.Errorf("occurrence %d: want span of %q got %v", tt.occurrence, tt.want, span)
+		}
+	}
+}
+
+func ExamplePatternGroup_labelValues() {
+	pattern := &docparser.PatternGroup{
+		Name:        "Features",
+		Regex:       regexp.MustCompile(`(?P<label>[A-Za-z][A-Za-z ]*):\s*(?P<value>[\d,]+)`),
+		LabelValues: true,
+	}
+
+	fields, err := pattern.Search("Beds: 3  Baths: 2  Living Sqft: 1,500")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(fields.GetString("beds"))
+	fmt.Println(fields.GetString("baths"))
+	fmt.Println(fields.GetString("living_sqft"))
+	// Output:
+	// 3
+	// 2
+	// 1,500
+}
+
+func TestPatternGroupLabelValues(t *testing.T) {
+	pattern := &docparser.PatternGroup{
+		Name:             "Features",
+		Regex:            regexp.MustCompile(`(?:(?P<label>[^:;]+)|\?):\s*(?P<value>[^;]*)`),
+		LabelValues:      true,
+		Line:             2,
+		TrimSpace:        true,
+		IncludeFullMatch: true,
+		Fold:             lowerASCII,
+	}
+	fields, err := pattern.Search("Beds: 1\nBEDS: 3; Half-Baths : 1; beds: 4; ?: x; HOA ($/mo): 250\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := docparser.Fields{
+		"beds":       "3",
+		"half_baths": "1",
+		"hoa_mo":     "250",
+		"_match":     "BEDS: 3; Half-Baths : 1; beds: 4; ?: x; HOA ($/mo): 250",
+	}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("want %v got %v", want, fields)
+	}
+	if names := pattern.FieldNames(); len(names) != 0 {
+		t.Errorf("want no field names got %v", names)
+	}
+
+	if _, err := pattern.Search("Beds 3\nBaths 2\n"); err == nil || err.Error() != `No match for "Features"` {
+		t.Errorf("want NoMatch got %v", err)
+	}
+
+	pattern = &docparser.PatternGroup{Name: "Bad", Regex: regexp.MustCompile(`(?P<key>\w+): (?P<value>\w+)`), LabelValues: true}
+	if _, err := pattern.Search("Beds: 3"); err == nil || err.Error() != "missing label or value group for Bad" {
+		t.Errorf("want missing group error got %v", err)
+	}
+}
+
+func TestPatternListOmitUnmatched(t *testing.T) {
+	pattern := &docparser.PatternList{
+		ListRegex:     regexp.MustCompile(`(?s:Items:\n(?P<items>.*))`),
+		SplitRegex:    regexp.MustCompile(`\n`),
+		ItemRegex:     regexp.MustCompile(`(?P<name>\w+)(?: \((?P<note>.*)\))?`),
+		OmitUnmatched: true,
+	}
+	fields, err := pattern.Search("Items:\na (new)\nb\nc ()\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []docparser.Fields{{"name": "a", "note": "new"}, {"name": "b"}, {"name": "c", "note": ""}}
+	if !reflect.DeepEqual(fields["items"], want) {
+		t.Errorf("want %v got %v", want, fields["items"])
+	}
+}
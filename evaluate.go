@@ -0,0 +1,167 @@
+package docparser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// EvalCase is a sample content with the fields Documents should find
+// in it, see Evaluate
+type EvalCase struct {
+	Name    string
+	Content string
+
+	// Expected fields, compared flattened with Fields.Flatten(".") so
+	// nested values can be checked one by one, i.e. "items.0.mls". Only
+	// the expected fields are checked, others are ignored
+	Expected Fields
+}
+
+// EvalResult is the outcome of an EvalCase
+type EvalResult struct {
+	Case string
+
+	// Document is the index of the Document that matched, or -1 if none
+	// did, in which case Err is the ErrorList of all Documents
+	Document     int
+	DocumentName string
+	Err          error
+
+	// Mismatches are the expected fields not found or with a different
+	// value, sorted by field
+	Mismatches []FieldMismatch
+}
+
+// FieldMismatch is an expected field that wasn't found as expected
+type FieldMismatch struct {
+	Field, Want, Got string
+	Missing          bool
+}
+
+func (m FieldMismatch) String() string {
+	if m.Missing {
+		return fmt.Sprintf("field %q: want %q, missing", m.Field, m.Want)
+	}
+	return fmt.Sprintf("field %q: want %q got %q", m.Field, m.Want, m.Got)
+}
+
+// FieldAccuracy counts how many times a field was expected and how
+// many of those it was found with the expected value
+type FieldAccuracy struct {
+	Correct, Total int
+}
+
+// EvalReport summarizes Evaluate over a corpus
+type EvalReport struct {
+	Results []EvalResult
+
+	// Matched is the number of cases some Document matched, and Correct
+	// the number of those where all expected fields were right
+	Matched, Correct int
+
+	// Fields is the accuracy of each flattened expected field
+	Fields map[string]FieldAccuracy
+}
+
+// Evaluate searches the content of each case with ds, like
+// Documents.Search, and compares the fields found with the expected
+// ones, to measure the quality of a whole set of layouts against a
+// corpus of samples, i.e. in CI. Cases no Document matches count as
+// wrong for all their expected fields
+func Evaluate(ds Documents, cases []EvalCase) EvalReport {
+	report := EvalReport{Fields: map[string]FieldAccuracy{}}
+	for _, c := range cases {
+		result := EvalResult{Case: c.Name, Document: -1}
+		found := map[string]string{}
+		errList := &ErrorList{}
+		for i, doc := range ds {
+			fields, err := doc.Search(c.Content)
+			if err != nil {
+				errList.Add(fmt.Errorf("Document %d: %s", i, err.Error()))
+				continue
+			}
+			result.Document, result.DocumentName = i, doc.Name
+			found = fields.Flatten(".")
+			break
+		}
+		if result.Document == -1 {
+			result.Err = errList
+		} else {
+			report.Matched++
+		}
+
+		expected := c.Expected.Flatten(".")
+		keys := make([]string, 0, len(expected))
+		for key := range expected {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			accuracy := report.Fields[key]
+			accuracy.Total++
+			got, ok := found[key]
+			if ok && got == expected[key] {
+				accuracy.Correct++
+			} else {
+				result.Mismatches = append(result.Mismatches, FieldMismatch{key, expected[key], got, !ok})
+			}
+			report.Fields[key] = accuracy
+		}
+		if result.Document != -1 && len(result.Mismatches) == 0 {
+			report.Correct++
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report
+}
+
+// MatchRate returns the fraction of cases some Document matched
+func (r EvalReport) MatchRate() float64 {
+	return ratio(r.Matched, len(r.Results))
+}
+
+// Accuracy returns the fraction of cases where all expected fields
+// were right
+func (r EvalReport) Accuracy() float64 {
+	return ratio(r.Correct, len(r.Results))
+}
+
+// String renders the report as plain text, with the overall rates, the
+// accuracy of each field and the details of each wrong case
+func (r EvalReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "cases: %d, matched: %d (%.1f%%), correct: %d (%.1f%%)\n",
+		len(r.Results), r.Matched, 100*r.MatchRate(), r.Correct, 100*r.Accuracy())
+
+	keys := make([]string, 0, len(r.Fields))
+	for key := range r.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	for _, key := range keys {
+		a := r.Fields[key]
+		fmt.Fprintf(w, "  %s\t%d/%d\t%.1f%%\n", key, a.Correct, a.Total, 100*ratio(a.Correct, a.Total))
+	}
+	w.Flush()
+
+	for _, result := range r.Results {
+		if result.Document == -1 {
+			fmt.Fprintf(&b, "case %q: no document matched: %v\n", result.Case, result.Err)
+			continue
+		}
+		for _, m := range result.Mismatches {
+			fmt.Fprintf(&b, "case %q (document %d %q): %s\n", result.Case, result.Document, result.DocumentName, m)
+		}
+	}
+	return b.String()
+}
+
+func ratio(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total)
+}
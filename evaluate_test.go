@@ -0,0 +1,74 @@
+package docparser_test
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+var evalDocuments = docparser.Documents{
+	{
+		Name: "Zillow",
+		Patterns: []docparser.Pattern{
+			&docparser.PatternGroup{Name: "Zillow", Regex: regexp.MustCompile(`Zillow lead: (?P<name>.*)`)},
+		},
+	},
+	{
+		Name: "Generic",
+		Patterns: []docparser.Pattern{
+			&docparser.PatternGroup{Name: "Name", Regex: regexp.MustCompile(`Name: (?P<name>\w+)`)},
+			&docparser.PatternGroup{Regex: regexp.MustCompile(`Phone: (?P<phone>.*)`), Optional: true},
+		},
+	},
+}
+
+var evalCases = []docparser.EvalCase{
+	{Name: "zillow", Content: "Zillow lead: Mark", Expected: docparser.Fields{"name": "Mark"}},
+	{Name: "generic", Content: "Name: Jane\nPhone: 221-1122", Expected: docparser.Fields{"name": "Jane", "phone": "221-1122"}},
+	{Name: "wrong phone", Content: "Name: Bob Smith", Expected: docparser.Fields{"name": "Bob Smith", "phone": "221-3344"}},
+	{Name: "unknown", Content: "Hello", Expected: docparser.Fields{"name": "Ann"}},
+}
+
+func ExampleEvaluate() {
+	report := docparser.Evaluate(evalDocuments, evalCases)
+	fmt.Print(report)
+	// Output:
+	// cases: 4, matched: 3 (75.0%), correct: 2 (50.0%)
+	//   name   2/4  50.0%
+	//   phone  1/2  50.0%
+	// case "wrong phone" (document 1 "Generic"): field "name": want "Bob Smith" got "Bob"
+	// case "wrong phone" (document 1 "Generic"): field "phone": want "221-3344", missing
+	// case "unknown": no document matched: Document 0: No match for "Zillow"; Document 1: No match for "Name"
+}
+
+func TestEvaluate(t *testing.T) {
+	report := docparser.Evaluate(evalDocuments, evalCases)
+	if report.MatchRate() != 0.75 || report.Accuracy() != 0.5 {
+		t.Errorf("invalid rates %v %v", report.MatchRate(), report.Accuracy())
+	}
+	documents := []int{}
+	for _, result := range report.Results {
+		documents = append(documents, result.Document)
+	}
+	if want := []int{0, 1, 1, -1}; !reflect.DeepEqual(documents, want) {
+		t.Errorf("want documents %v got %v", want, documents)
+	}
+	want := []docparser.FieldMismatch{
+		{Field: "name", Want: "Bob Smith", Got: "Bob"},
+		{Field: "phone", Want: "221-3344", Missing: true},
+	}
+	if !reflect.DeepEqual(report.Results[2].Mismatches, want) {
+		t.Errorf("want %v got %v", want, report.Results[2].Mismatches)
+	}
+	if report.Results[3].Err == nil || len(report.Results[3].Mismatches) != 1 {
+		t.Errorf("unmatched case: invalid result %+v", report.Results[3])
+	}
+
+	empty := docparser.Evaluate(evalDocuments, nil)
+	if empty.MatchRate() != 0 || empty.String() != "cases: 0, matched: 0 (0.0%), correct: 0 (0.0%)\n" {
+		t.Errorf("invalid empty report %q", empty.String())
+	}
+}
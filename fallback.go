@@ -0,0 +1,82 @@
+package docparser
+
+// PatternFallback is a Pattern that prefers a strict Primary pattern and
+// falls back to a looser Secondary one when Primary doesn't match
+//
+// Fields found by Secondary are tagged as low confidence so consumers
+// can tell clean matches from best-effort ones: each field "key" gets a
+// "_key_confidence" field set to "low"
+type PatternFallback struct {
+	// Primary is tried first
+	Primary Pattern
+
+	// Secondary is tried if Primary returns a NoMatch error
+	Secondary Pattern
+
+	// NoConfidence disables tagging the fields found by Secondary
+	NoConfidence bool
+}
+
+// Search returns the result of Primary.Search(), or of Secondary.Search()
+// if Primary returns a NoMatch error. Other errors are returned as is
+//
+// If both patterns don't match, returns a NoMatch error named after
+// both, i.e. "Strict or Loose", so callers can skip it like any other
+// NoMatch. If Secondary fails with another error, returns an ErrorList
+// with both errors
+func (pf *PatternFallback) Search(content string) (Fields, error) {
+	fields, err := pf.Primary.Search(content)
+	primaryErr, ok := err.(*NoMatch)
+	if !ok {
+		return fields, err
+	}
+	fields, err = pf.Secondary.Search(content)
+	if secondaryErr, ok := err.(*NoMatch); ok {
+		return Fields{}, NewNoMatch(primaryErr.Name+" or "+secondaryErr.Name, content)
+	}
+	if err != nil {
+		return Fields{}, &ErrorList{primaryErr, err}
+	}
+	if !pf.NoConfidence {
+		for _, key := range fields.Keys() {
+			fields[confidenceKey(key)] = "low"
+		}
+	}
+	return fields, nil
+}
+
+func confidenceKey(key string) string {
+	return "_" + key + "_confidence"
+}
+
+// FieldNames returns the field names of Primary and Secondary, for the
+// ones that are FieldNamers
+func (pf *PatternFallback) FieldNames() []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, p := range []Pattern{pf.Primary, pf.Secondary} {
+		for _, name := range (wrappedPattern{p}).FieldNames() {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// SetFields forwards the fields to Primary and Secondary, for the ones
+// that are PatternWithFields
+func (pf *PatternFallback) SetFields(f Fields) {
+	wrappedPattern{pf.Primary}.SetFields(f)
+	wrappedPattern{pf.Secondary}.SetFields(f)
+}
+
+// GetFields returns the fields from Primary if it's a PatternWithFields,
+// otherwise from Secondary
+func (pf *PatternFallback) GetFields() Fields {
+	if _, ok := pf.Primary.(PatternWithFields); ok {
+		return wrappedPattern{pf.Primary}.GetFields()
+	}
+	return wrappedPattern{pf.Secondary}.GetFields()
+}
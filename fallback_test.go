@@ -0,0 +1,94 @@
+package docparser_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+func ExamplePatternFallback() {
+	pattern := &docparser.PatternFallback{
+		Primary: &docparser.PatternGroup{
+			Name:  "Phone",
+			Regex: regexp.MustCompile(`Phone: (?P<phone>\(\d{3}\) \d{3}-\d{4})`),
+		},
+		Secondary: &docparser.PatternGroup{
+			Name:  "Any phone",
+			Regex: regexp.MustCompile(`(?P<phone>\d[\d -]{6,}\d)`),
+		},
+	}
+
+	for _, content := range []string{"Phone: (808) 221-1122", "call 808 221 1122"} {
+		fields, err := pattern.Search(content)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Printf("%q %q\n", fields.GetString("phone"), fields.GetString("_phone_confidence"))
+	}
+	// Output:
+	// "(808) 221-1122" ""
+	// "808 221 1122" "low"
+}
+
+func TestPatternFallback(t *testing.T) {
+	pattern := &docparser.PatternFallback{
+		Primary:      &docparser.PatternGroup{Name: "Strict", Regex: regexp.MustCompile(`Name: (?P<name>\w+)$`)},
+		Secondary:    &docparser.PatternGroup{Name: "Loose", Regex: regexp.MustCompile(`(?i)name\W+(?P<name>\w+)`)},
+		NoConfidence: true,
+	}
+	fields, err := pattern.Search("NAME - bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (docparser.Fields{"name": "bob"}); !reflect.DeepEqual(fields, want) {
+		t.Errorf("want %v got %v", want, fields)
+	}
+
+	_, err = pattern.Search("nothing")
+	if _, ok := err.(*docparser.NoMatch); !ok || err.Error() != `No match for "Strict or Loose"` {
+		t.Errorf("invalid error: %v", err)
+	}
+
+	if names := pattern.FieldNames(); !reflect.DeepEqual(names, []string{"name"}) {
+		t.Errorf("invalid field names: %v", names)
+	}
+}
+
+func TestPatternFallbackError(t *testing.T) {
+	pattern := &docparser.PatternFallback{
+		Primary: &docparser.PatternGroup{
+			Regex: regexp.MustCompile(`Name: (?P<name>\w+)`),
+			Validate: map[string]func(string) error{
+				"name": func(string) error { return errors.New("invalid") },
+			},
+		},
+		Secondary: &docparser.PatternGroup{Regex: regexp.MustCompile(`(?P<name>\w+)`)},
+	}
+	if _, err := pattern.Search("Name: bob"); err == nil {
+		t.Error("Primary errors other than NoMatch should not fall back")
+	}
+}
+
+func TestPatternFallbackMinMatches(t *testing.T) {
+	document := &docparser.Document{
+		Patterns: []docparser.Pattern{
+			&docparser.PatternFallback{
+				Primary:   &docparser.PatternGroup{Name: "a", Regex: regexp.MustCompile(`A: (?P<a>\w+)`)},
+				Secondary: &docparser.PatternGroup{Name: "b", Regex: regexp.MustCompile(`B: (?P<a>\w+)`)},
+			},
+			&docparser.PatternGroup{Name: "c", Regex: regexp.MustCompile(`C: (?P<c>\w+)`)},
+		},
+		MinMatches: 1,
+	}
+	fields, err := document.Search("C: yes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (docparser.Fields{"c": "yes"}); !reflect.DeepEqual(fields, want) {
+		t.Errorf("want %v got %v", want, fields)
+	}
+}
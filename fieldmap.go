@@ -0,0 +1,72 @@
+package docparser
+
+import (
+	"regexp"
+	"sort"
+)
+
+// PatternFieldMap is a Pattern implementation that finds each field with
+// its own regex, matched independently against the content
+//
+// This allows replacing a big regex with many small ones that are
+// easier to maintain and test
+type PatternFieldMap struct {
+	Name string
+
+	// Regexes maps field names to the regex that finds its value. The
+	// value is the first group of the regex, or the whole match if
+	// the regex has no groups
+	Regexes map[string]*regexp.Regexp
+
+	// Required lists the fields that must be found. Fields whose
+	// regex doesn't match are skipped otherwise
+	Required []string
+}
+
+// FieldNames returns the keys of Regexes
+func (pf *PatternFieldMap) FieldNames() []string {
+	names := make([]string, 0, len(pf.Regexes))
+	for key := range pf.Regexes {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Search all Regexes in content
+//
+// Return NoMatch error for the first required field, in alphabetical
+// order, whose regex doesn't match
+func (pf *PatternFieldMap) Search(content string) (Fields, error) {
+	keys := make([]string, 0, len(pf.Regexes))
+	for key := range pf.Regexes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fields := Fields{}
+	for _, key := range keys {
+		matches := pf.Regexes[key].FindStringSubmatch(content)
+		if matches == nil {
+			if pf.isRequired(key) {
+				return Fields{}, NewNoMatch(pf.Name+" - "+key, content)
+			}
+			continue
+		}
+		if len(matches) > 1 {
+			fields[key] = matches[1]
+		} else {
+			fields[key] = matches[0]
+		}
+	}
+	return fields, nil
+}
+
+func (pf *PatternFieldMap) isRequired(key string) bool {
+	for _, k := range pf.Required {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package docparser_test
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+func ExamplePatternFieldMap() {
+	pattern := &docparser.PatternFieldMap{
+		Name: "Contact",
+		Regexes: map[string]*regexp.Regexp{
+			"name":  regexp.MustCompile(`Name: (?P<name>.*)`),
+			"email": regexp.MustCompile(`[\w.]+@[\w.]+`),
+			"phone": regexp.MustCompile(`Phone: (.*)`),
+		},
+		Required: []string{"name", "email"},
+	}
+
+	fields, err := pattern.Search("Email bob@site.com to reach\nName: Bob Smith")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(fields.GetString("name"))
+	fmt.Println(fields.GetString("email"))
+	fmt.Println(fields.Has("phone"))
+	// Output:
+	// Bob Smith
+	// bob@site.com
+	// false
+}
+
+func TestPatternFieldMap(t *testing.T) {
+	pattern := &docparser.PatternFieldMap{
+		Name: "Contact",
+		Regexes: map[string]*regexp.Regexp{
+			"name":  regexp.MustCompile(`Name: (.*)`),
+			"email": regexp.MustCompile(`Email: (.*)`),
+			"phone": regexp.MustCompile(`Phone: (.*)`),
+		},
+		Required: []string{"name", "email"},
+	}
+	var tests = []struct {
+		content string
+		want    docparser.Fields
+		err     string
+	}{
+		{
+			content: "Name: bob\nEmail: bob@site.com\nPhone: 111",
+			want:    docparser.Fields{"name": "bob", "email": "bob@site.com", "phone": "111"},
+		},
+		{
+			content: "Email: bob@site.com\nName: bob",
+			want:    docparser.Fields{"name": "bob", "email": "bob@site.com"},
+		},
+		{content: "Phone: 111", err: `No match for "Contact - email"`},
+		{content: "Email: bob@site.com", err: `No match for "Contact - name"`},
+	}
+	for _, tt := range tests {
+		fields, err := pattern.Search(tt.content)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("content %q want error %q got %v", tt.content, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("content %q failed: %s", tt.content, err)
+			continue
+		}
+		if !reflect.DeepEqual(fields, tt.want) {
+			t.Errorf("content %q want %v got %v", tt.content, tt.want, fields)
+		}
+	}
+}
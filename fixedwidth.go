@@ -0,0 +1,96 @@
+package docparser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FixedField is a field of a fixed width record, Length bytes or runes
+// from Start, 0-based. Zero Length means up to the end of the line.
+// Start can't be negative
+type FixedField struct {
+	Name          string
+	Start, Length int
+}
+
+// PatternFixedWidth is a Pattern implementation for column-positional
+// records, like some MLS exports, where each line is a record and each
+// field is always at the same offset:
+//
+//	2211  331 Kailua Rd     450000
+//	9090  990 Kaelepulu Dr  1200000
+//
+// Unlike PatternTable there's no header, the offsets are given
+type PatternFixedWidth struct {
+	Name   string
+	Fields []FixedField
+
+	// Runes makes Start and Length count runes instead of bytes, for
+	// multibyte content
+	Runes bool
+
+	// OutputKey is the field that holds the []Fields of all records
+	OutputKey string
+
+	Optional bool
+}
+
+// Search slices each line of content into Fields
+//
+// Blank lines are skipped and values are trimmed. Lines shorter than
+// expected give empty values for the fields past their end, or the
+// part they have. Return NoMatch error if there are no lines, and an
+// error if a field has a negative Start
+func (pf *PatternFixedWidth) Search(content string) (Fields, error) {
+	for _, field := range pf.Fields {
+		if field.Start < 0 {
+			return Fields{}, fmt.Errorf("negative start for field %q of %s", field.Name, pf.Name)
+		}
+	}
+	records := []Fields{}
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		records = append(records, pf.record(line))
+	}
+	if len(records) == 0 {
+		if pf.Optional {
+			return Fields{}, nil
+		}
+		return Fields{}, NewNoMatch(pf.Name, content)
+	}
+	return Fields{pf.OutputKey: records}, nil
+}
+
+// FieldNames returns OutputKey
+func (pf *PatternFixedWidth) FieldNames() []string {
+	return []string{pf.OutputKey}
+}
+
+func (pf *PatternFixedWidth) record(line string) Fields {
+	var runes []rune
+	size := len(line)
+	if pf.Runes {
+		runes = []rune(line)
+		size = len(runes)
+	}
+	record := Fields{}
+	for _, field := range pf.Fields {
+		start, end := field.Start, field.Start+field.Length
+		if field.Length <= 0 || end > size {
+			end = size
+		}
+		value := ""
+		if start < end {
+			if pf.Runes {
+				value = string(runes[start:end])
+			} else {
+				value = line[start:end]
+			}
+		}
+		record[field.Name] = strings.TrimSpace(value)
+	}
+	return record
+}
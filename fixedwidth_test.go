@@ -0,0 +1,98 @@
+package docparser_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+func ExamplePatternFixedWidth() {
+	pattern := &docparser.PatternFixedWidth{
+		Name: "MLS export",
+		Fields: []docparser.FixedField{
+			{Name: "mls", Start: 0, Length: 6},
+			{Name: "address", Start: 6, Length: 18},
+			{Name: "price", Start: 24},
+		},
+		OutputKey: "listings",
+	}
+
+	content := "2211  331 Kailua Rd     450000\n" +
+		"9090  990 Kaelepulu Dr  1200000\n"
+
+	fields, err := pattern.Search(content)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, listing := range fields.GetMapSlice("listings") {
+		fmt.Printf("%s: %s (%s)\n", listing["mls"], listing["address"], listing["price"])
+	}
+	// Output:
+	// 2211: 331 Kailua Rd (450000)
+	// 9090: 990 Kaelepulu Dr (1200000)
+}
+
+func TestPatternFixedWidth(t *testing.T) {
+	fields := []docparser.FixedField{
+		{Name: "id", Start: 0, Length: 3},
+		{Name: "city", Start: 3, Length: 8},
+		{Name: "state", Start: 11, Length: 2},
+	}
+	content := "001Kailua  HI\r\n\n002Hal\u0113iwa HI\n003Hilo\n004\n"
+	var tests = []struct {
+		runes bool
+		want  []map[string]string
+	}{
+		{false, []map[string]string{
+			{"id": "001", "city": "Kailua", "state": "HI"},
+			{"id": "002", "city": "Hal\u0113iwa", "state": "H"},
+			{"id": "003", "city": "Hilo", "state": ""},
+			{"id": "004", "city": "", "state": ""},
+		}},
+		{true, []map[string]string{
+			{"id": "001", "city": "Kailua", "state": "HI"},
+			{"id": "002", "city": "Hal\u0113iwa", "state": "HI"},
+			{"id": "003", "city": "Hilo", "state": ""},
+			{"id": "004", "city": "", "state": ""},
+		}},
+	}
+	for _, tt := range tests {
+		pattern := &docparser.PatternFixedWidth{Fields: fields, Runes: tt.runes, OutputKey: "records"}
+		f, err := pattern.Search(content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := f.GetMapSlice("records"); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("runes %v: want %v got %v", tt.runes, tt.want, got)
+		}
+	}
+}
+
+func TestPatternFixedWidthNoMatch(t *testing.T) {
+	pattern := &docparser.PatternFixedWidth{Name: "Export", OutputKey: "records"}
+	if _, err := pattern.Search(" \n\n"); err == nil || err.Error() != `No match for "Export"` {
+		t.Errorf("want NoMatch got %v", err)
+	}
+	pattern.Optional = true
+	if f, err := pattern.Search(""); err != nil || len(f) != 0 {
+		t.Errorf("optional want empty fields got %v (%v)", f, err)
+	}
+}
+
+func TestPatternFixedWidthNegativeStart(t *testing.T) {
+	for _, runes := range []bool{false, true} {
+		pattern := &docparser.PatternFixedWidth{
+			Name:      "Export",
+			Fields:    []docparser.FixedField{{Name: "mls", Start: -2, Length: 4}},
+			Runes:     runes,
+			OutputKey: "records",
+		}
+		_, err := pattern.Search("2211  331 Kailua Rd\n")
+		if err == nil || err.Error() != `negative start for field "mls" of Export` {
+			t.Errorf("runes %v: want negative start error got %v", runes, err)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package docparser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// GeoRegex is the default regex of PatternGeo, for decimal coordinates
+// like "21.3069, -157.8583". Both coordinates need a fraction and the
+// latitude can't follow a digit, ".", "," or "$", so prices like
+// "$400,000" and counts like "3, 2" aren't taken for coordinates
+var GeoRegex = regexp.MustCompile(`(?:^|[^\d.,$])(?P<lat>[-+]?\d{1,3}\.\d+)\s*,\s*(?P<lon>[-+]?\d{1,3}\.\d+)\b`)
+
+// PatternGeo is a Pattern implementation that extracts a latitude and
+// longitude pair into two float64 fields. Read them with Get[float64]
+type PatternGeo struct {
+	Name string
+
+	// Regex matches the coordinates, the group "lat" capturing the
+	// latitude and "lon" the longitude. Defaults to GeoRegex
+	Regex *regexp.Regexp
+
+	// LatKey and LonKey are the fields of the latitude and longitude
+	LatKey, LonKey string
+
+	Optional bool
+}
+
+// Search for the coordinates in content and parse them
+//
+// Every match of Regex is tried, in order, and the first one with both
+// coordinates in range is returned: latitudes must be within -90 and 90
+// and longitudes within -180 and 180, which catches most transposed
+// pairs. Return NoMatch error if Regex doesn't match, and the error of
+// the first match if none is in range
+func (pg *PatternGeo) Search(content string) (Fields, error) {
+	regex := pg.Regex
+	if regex == nil {
+		regex = GeoRegex
+	}
+
+	var firstErr error
+	for _, match := range regex.FindAllStringSubmatch(content, -1) {
+		fields, err := pg.parse(regex, match)
+		if err == nil {
+			return fields, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr != nil {
+		return Fields{}, firstErr
+	}
+	if pg.Optional {
+		return Fields{}, nil
+	}
+	return Fields{}, NewNoMatch(pg.Name, content)
+}
+
+// parse returns the coordinates of a match of regex
+func (pg *PatternGeo) parse(regex *regexp.Regexp, match []string) (Fields, error) {
+	lat, err := parseCoordinate(regex, match, "lat", 90)
+	if err != nil {
+		return Fields{}, fmt.Errorf("failed to parse latitude for %s: %v", pg.Name, err)
+	}
+	lon, err := parseCoordinate(regex, match, "lon", 180)
+	if err != nil {
+		return Fields{}, fmt.Errorf("failed to parse longitude for %s: %v", pg.Name, err)
+	}
+	return Fields{pg.LatKey: lat, pg.LonKey: lon}, nil
+}
+
+// FieldNames returns LatKey and LonKey
+func (pg *PatternGeo) FieldNames() []string {
+	return []string{pg.LatKey, pg.LonKey}
+}
+
+// parseCoordinate parses the group name of match, which must be within
+// -limit and limit
+func parseCoordinate(regex *regexp.Regexp, match []string, name string, limit float64) (float64, error) {
+	i := regex.SubexpIndex(name)
+	if i == -1 {
+		return 0, fmt.Errorf("no %q group", name)
+	}
+	value, err := strconv.ParseFloat(match[i], 64)
+	if err != nil {
+		return 0, err
+	}
+	if value < -limit || value > limit {
+		return 0, fmt.Errorf("%v is out of range", value)
+	}
+	return value, nil
+}
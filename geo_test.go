@@ -0,0 +1,82 @@
+package docparser_test
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+func ExamplePatternGeo() {
+	pattern := &docparser.PatternGeo{
+		Name:   "Location",
+		LatKey: "lat",
+		LonKey: "lon",
+	}
+
+	fields, err := pattern.Search("Location: 21.3069, -157.8583")
+	if err != nil {
+		panic(err)
+	}
+
+	lat, _ := docparser.Get[float64](fields, "lat")
+	lon, _ := docparser.Get[float64](fields, "lon")
+	fmt.Println(lat, lon)
+	// Output:
+	// 21.3069 -157.8583
+}
+
+func TestPatternGeo(t *testing.T) {
+	pattern := &docparser.PatternGeo{LatKey: "lat", LonKey: "lon"}
+	var tests = []struct {
+		content string
+		want    docparser.Fields
+	}{
+		{"21.3069,-157.8583", docparser.Fields{"lat": 21.3069, "lon": -157.8583}},
+		{"at +90.0 , 180.0 exactly", docparser.Fields{"lat": 90.0, "lon": 180.0}},
+		{"-33.8688, 151.2093", docparser.Fields{"lat": -33.8688, "lon": 151.2093}},
+		{"Price: $400,000\nLocation: 21.3069, -157.8583", docparser.Fields{"lat": 21.3069, "lon": -157.8583}},
+		{"Price: $400,000.00\nLocation: 21.3069, -157.8583", docparser.Fields{"lat": 21.3069, "lon": -157.8583}},
+		{"Area: 100.5, 200.5\nLocation: 21.3069, -157.8583", docparser.Fields{"lat": 21.3069, "lon": -157.8583}},
+	}
+	for _, tt := range tests {
+		fields, err := pattern.Search(tt.content)
+		if err != nil {
+			t.Errorf("%q: %s", tt.content, err)
+			continue
+		}
+		if !reflect.DeepEqual(fields, tt.want) {
+			t.Errorf("%q: want %v got %v", tt.content, tt.want, fields)
+		}
+	}
+}
+
+func TestPatternGeoErrors(t *testing.T) {
+	pattern := &docparser.PatternGeo{Name: "Location", LatKey: "lat", LonKey: "lon"}
+	if _, err := pattern.Search("-157.8583, 21.3069"); err == nil || err.Error() != "failed to parse latitude for Location: -157.8583 is out of range" {
+		t.Errorf("transposed pair: invalid error %v", err)
+	}
+	if _, err := pattern.Search("45.1, 200.5"); err == nil || err.Error() != "failed to parse longitude for Location: 200.5 is out of range" {
+		t.Errorf("invalid longitude: invalid error %v", err)
+	}
+	for _, content := range []string{"no coordinates", "Beds, baths: 3, 2", "Price: $400,000"} {
+		if _, err := pattern.Search(content); err == nil {
+			t.Errorf("%q: did not return NoMatch", content)
+		} else if _, ok := err.(*docparser.NoMatch); !ok {
+			t.Errorf("%q: want NoMatch got %v", content, err)
+		}
+	}
+
+	pattern.Regex = regexp.MustCompile(`(?P<lat>\d+) (?P<longitude>\d+)`)
+	if _, err := pattern.Search("1 2"); err == nil {
+		t.Error("missing group did not return error")
+	}
+
+	pattern.Optional = true
+	fields, err := pattern.Search("no coordinates")
+	if err != nil || len(fields) != 0 {
+		t.Errorf("optional want empty fields got %v (%v)", fields, err)
+	}
+}
@@ -0,0 +1,12 @@
+package docparser
+
+// Get returns the value associated with key asserted to type T, i.e.
+// Get[[]Fields](f, "properties")
+//
+// Return the zero value of T and false if key is not present or if its
+// value is not a T. This generalizes GetString and the other getters to
+// any stored type. The module requires Go 1.26 or later, see go.mod
+func Get[T any](f Fields, key string) (T, bool) {
+	v, ok := f[key].(T)
+	return v, ok
+}
@@ -0,0 +1,45 @@
+package docparser_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+func ExampleGet() {
+	f := docparser.Fields{
+		"name":       "Mark",
+		"properties": []docparser.Fields{{"mls": "2211"}},
+	}
+
+	properties, ok := docparser.Get[[]docparser.Fields](f, "properties")
+	fmt.Println(properties[0].GetString("mls"), ok)
+
+	_, ok = docparser.Get[[]docparser.Fields](f, "name")
+	fmt.Println(ok)
+	// Output:
+	// 2211 true
+	// false
+}
+
+func TestGet(t *testing.T) {
+	f := docparser.Fields{"name": "Mark", "tags": []string{"hot"}, "beds": 3}
+
+	if v, ok := docparser.Get[string](f, "name"); !ok || v != "Mark" {
+		t.Errorf("want Mark got %q %v", v, ok)
+	}
+	if v, ok := docparser.Get[[]string](f, "tags"); !ok || !reflect.DeepEqual(v, []string{"hot"}) {
+		t.Errorf("want [hot] got %v %v", v, ok)
+	}
+	if v, ok := docparser.Get[int](f, "beds"); !ok || v != 3 {
+		t.Errorf("want 3 got %v %v", v, ok)
+	}
+	if v, ok := docparser.Get[int](f, "name"); ok || v != 0 {
+		t.Errorf("wrong type should return zero value, got %v %v", v, ok)
+	}
+	if v, ok := docparser.Get[string](f, "missing"); ok || v != "" {
+		t.Errorf("missing key should return zero value, got %q %v", v, ok)
+	}
+}
@@ -0,0 +1,99 @@
+package docparser
+
+import (
+	"io"
+	"mime"
+	"net/mail"
+	"net/textproto"
+	"sort"
+	"strings"
+)
+
+// PatternHeader is a Pattern implementation that reads fields from the
+// RFC 5322 headers of a raw email, so "Subject:" or "From:" lines
+// quoted in the body can't be mistaken for the real headers
+type PatternHeader struct {
+	Name string
+
+	// Headers maps header names, case insensitive, to the name of the
+	// field that gets their value, i.e. "Reply-To": "email". Encoded
+	// words like "=?UTF-8?Q?...?=" are decoded
+	Headers map[string]string
+
+	// Body is an optional Pattern searched only in the body of the
+	// email, after the headers, and its fields are added to the
+	// header fields
+	Body Pattern
+
+	// Optional makes missing headers be left out of Fields instead of
+	// returning NoMatch. Errors from Body are always returned
+	Optional bool
+}
+
+// Search parses content as an email and extracts Headers from it
+//
+// Return NoMatch error if content doesn't start with headers or a
+// header is missing and the pattern isn't Optional
+func (ph *PatternHeader) Search(content string) (Fields, error) {
+	msg, err := mail.ReadMessage(strings.NewReader(content))
+	if err != nil {
+		if ph.Optional && ph.Body == nil {
+			return Fields{}, nil
+		}
+		return Fields{}, NewNoMatch(ph.Name+" - headers", content)
+	}
+	fields := Fields{}
+	decoder := &mime.WordDecoder{}
+	for _, header := range ph.headerNames() {
+		key := ph.Headers[header]
+		values, ok := msg.Header[textproto.CanonicalMIMEHeaderKey(header)]
+		if !ok {
+			if ph.Optional {
+				continue
+			}
+			return Fields{}, NewNoMatch(ph.Name+" - "+header, content)
+		}
+		value, err := decoder.DecodeHeader(values[0])
+		if err != nil {
+			value = values[0]
+		}
+		fields[key] = value
+	}
+	if ph.Body != nil {
+		body, err := io.ReadAll(msg.Body)
+		if err != nil {
+			return Fields{}, err
+		}
+		bodyFields, err := ph.Body.Search(string(body))
+		if err != nil {
+			return Fields{}, err
+		}
+		fields.Update(bodyFields)
+	}
+	return fields, nil
+}
+
+// headerNames returns the keys of Headers sorted, so the first missing
+// header reported is always the same
+func (ph *PatternHeader) headerNames() []string {
+	names := make([]string, 0, len(ph.Headers))
+	for header := range ph.Headers {
+		names = append(names, header)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FieldNames returns the fields Headers are stored in, and the field
+// names of Body if it's a FieldNamer
+func (ph *PatternHeader) FieldNames() []string {
+	names := []string{}
+	for _, header := range ph.headerNames() {
+		names = append(names, ph.Headers[header])
+	}
+	return append(names, wrappedPattern{ph.Body}.FieldNames()...)
+}
+
+// SetFields and GetFields forward to Body, see wrappedPattern
+func (ph *PatternHeader) SetFields(f Fields) { wrappedPattern{ph.Body}.SetFields(f) }
+func (ph *PatternHeader) GetFields() Fields  { return wrappedPattern{ph.Body}.GetFields() }
@@ -0,0 +1,75 @@
+package docparser_test
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+const rawEmail = "From: Lead Router <leads@site.example.com>\r\n" +
+	"Subject: =?UTF-8?Q?New_lead_=E2=80=93_Mark?=\r\n" +
+	"Reply-To: mark@example.com\r\n" +
+	"\r\n" +
+	"Forwarded message\r\n" +
+	"From: someone@else.example.com\r\n" +
+	"Name: Mark\r\n"
+
+func ExamplePatternHeader() {
+	pattern := &docparser.PatternHeader{
+		Name:    "Lead email",
+		Headers: map[string]string{"subject": "subject", "Reply-To": "email"},
+		Body: &docparser.PatternGroup{
+			Regex: regexp.MustCompile(`Name: (?P<name>\w+)`),
+		},
+	}
+
+	fields, err := pattern.Search(rawEmail)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(fields.GetString("subject"))
+	fmt.Println(fields.GetString("email"))
+	fmt.Println(fields.GetString("name"))
+	// Output:
+	// New lead – Mark
+	// mark@example.com
+	// Mark
+}
+
+func TestPatternHeader(t *testing.T) {
+	pattern := &docparser.PatternHeader{
+		Name:    "Lead email",
+		Headers: map[string]string{"From": "from", "Cc": "cc"},
+		Body: &docparser.PatternGroup{
+			Regex: regexp.MustCompile(`From: (?P<body_from>.*)\r`),
+		},
+	}
+	if _, err := pattern.Search(rawEmail); err == nil || err.Error() != `No match for "Lead email - Cc"` {
+		t.Errorf("invalid error %v", err)
+	}
+
+	pattern.Optional = true
+	fields, err := pattern.Search(rawEmail)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := docparser.Fields{
+		"from":      "Lead Router <leads@site.example.com>",
+		"body_from": "someone@else.example.com",
+	}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("want %v got %v", want, fields)
+	}
+	wantNames := []string{"cc", "from", "body_from"}
+	if names := pattern.FieldNames(); !reflect.DeepEqual(names, wantNames) {
+		t.Errorf("want %v got %v", wantNames, names)
+	}
+
+	if _, err := pattern.Search("not an email"); err == nil {
+		t.Error("content without headers did not return error")
+	}
+}
@@ -0,0 +1,154 @@
+// Package htmltext extracts text from HTML documents so docparser
+// patterns written for plain text can run against HTML emails, and
+// provides patterns that extract fields from specific HTML elements
+//
+// It's a separate package to keep golang.org/x/net/html out of
+// docparser's dependencies
+package htmltext
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// blockElements start and end on their own line in the extracted text
+var blockElements = map[atom.Atom]bool{
+	atom.Address: true, atom.Article: true, atom.Aside: true,
+	atom.Blockquote: true, atom.Dd: true, atom.Div: true, atom.Dl: true,
+	atom.Dt: true, atom.Fieldset: true, atom.Figure: true, atom.Footer: true,
+	atom.Form: true, atom.H1: true, atom.H2: true, atom.H3: true,
+	atom.H4: true, atom.H5: true, atom.H6: true, atom.Header: true,
+	atom.Hr: true, atom.Li: true, atom.Main: true, atom.Nav: true,
+	atom.Ol: true, atom.P: true, atom.Pre: true, atom.Section: true,
+	atom.Table: true, atom.Tr: true, atom.Ul: true,
+}
+
+// skipElements have no visible text
+var skipElements = map[atom.Atom]bool{
+	atom.Head: true, atom.Script: true, atom.Style: true,
+	atom.Noscript: true, atom.Template: true,
+}
+
+// Text returns the visible text of the HTML content
+//
+// Tags are removed and entities decoded. Runs of whitespace are
+// collapsed to a single space, except inside <pre>, while <br> and
+// block elements like <p>, <div> and <tr> produce line breaks so
+// line-anchored regexes keep working. Table cells are separated by a
+// space
+//
+// Text has the docparser.Preprocessor signature, so it can be used in
+// Document.Preprocess. Malformed markup is handled like a browser
+// would, so an error is only returned if the content can't be read
+func Text(content string) (string, error) {
+	doc, err := html.Parse(strings.NewReader(content))
+	if err != nil {
+		return "", err
+	}
+	w := &textWriter{}
+	w.walk(doc)
+	return w.String(), nil
+}
+
+type textWriter struct {
+	lines []string
+	line  strings.Builder
+	pre   int
+}
+
+func (w *textWriter) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		w.text(n.Data)
+		return
+	case html.ElementNode:
+		if skipElements[n.DataAtom] {
+			return
+		}
+		switch n.DataAtom {
+		case atom.Br:
+			w.newline()
+			return
+		case atom.Td, atom.Th:
+			w.space()
+		case atom.Pre:
+			w.pre++
+			defer func() { w.pre-- }()
+		}
+		if blockElements[n.DataAtom] {
+			w.endLine()
+			defer w.endLine()
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		w.walk(c)
+	}
+}
+
+func (w *textWriter) text(s string) {
+	if w.pre > 0 {
+		for i, part := range strings.Split(s, "\n") {
+			if i > 0 {
+				w.newline()
+			}
+			w.line.WriteString(part)
+		}
+		return
+	}
+	for i, word := range strings.FieldsFunc(s, isHTMLSpace) {
+		if i > 0 || isHTMLSpace(rune(s[0])) {
+			w.space()
+		}
+		w.line.WriteString(word)
+	}
+	if s != "" && isHTMLSpace(rune(s[len(s)-1])) {
+		w.space()
+	}
+}
+
+// space adds a single space to the current line, unless it's empty or
+// already ends with one
+func (w *textWriter) space() {
+	if cur := w.line.String(); cur != "" && !strings.HasSuffix(cur, " ") {
+		w.line.WriteByte(' ')
+	}
+}
+
+// newline ends the current line, even if it's empty
+func (w *textWriter) newline() {
+	line := w.line.String()
+	if w.pre == 0 {
+		line = strings.TrimRight(line, " ")
+	}
+	w.lines = append(w.lines, line)
+	w.line.Reset()
+}
+
+// endLine ends the current line unless it's empty
+func (w *textWriter) endLine() {
+	if strings.TrimSpace(w.line.String()) != "" {
+		w.newline()
+	} else if w.pre == 0 {
+		w.line.Reset()
+	}
+}
+
+func (w *textWriter) String() string {
+	w.endLine()
+	if len(w.lines) == 0 {
+		return ""
+	}
+	return strings.Join(w.lines, "\n") + "\n"
+}
+
+// isHTMLSpace reports whether r is whitespace as defined by HTML. Note
+// that non-breaking spaces are not
+func isHTMLSpace(r rune) bool {
+	switch r {
+	case ' ', '\t', '\n', '\f', '\r':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package htmltext_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+	"github.com/RealGeeks/docparser/htmltext"
+)
+
+func ExampleText() {
+	document := &docparser.Document{
+		Name:       "HTML lead",
+		Preprocess: []docparser.Preprocessor{htmltext.Text},
+		Patterns: []docparser.Pattern{
+			&docparser.PatternGroup{
+				Name:  "Contact information",
+				Regex: regexp.MustCompile(`(?m:^Name: (?P<name>.*)\nPhone: (?P<phone>.*)$)`),
+			},
+		},
+	}
+
+	content := `<html><body>
+<p><b>Name:</b> Mark &amp; Jane Stewart<br>
+<b>Phone:</b>   (123) 221-1122</p>
+</body></html>`
+
+	fields, err := document.Search(content)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(fields.GetString("name"))
+	fmt.Println(fields.GetString("phone"))
+	// Output:
+	// Mark & Jane Stewart
+	// (123) 221-1122
+}
+
+func TestText(t *testing.T) {
+	var tests = []struct {
+		html, text string
+	}{
+		{"", ""},
+		{"plain text", "plain text\n"},
+		{"<p>one</p><p>two</p>", "one\ntwo\n"},
+		{"<div>a <span>b</span>\n\n   c</div>", "a b c\n"},
+		{"line 1<br>line 2<br/><br>line 4", "line 1\nline 2\n\nline 4\n"},
+		{"<table><tr><td>Beds</td><td>3</td></tr><tr><th>Baths</th><td>2</td></tr></table>", "Beds 3\nBaths 2\n"},
+		{"<ul><li>one</li><li>two</li></ul>", "one\ntwo\n"},
+		{"<head><title>t</title><style>p {}</style></head><p>x<script>var y</script></p>", "x\n"},
+		{"<pre>a\n  b</pre>", "a\n  b\n"},
+		{"&lt;tag&gt; &quot;q&quot; caf&eacute;&nbsp;!", "<tag> \"q\" caf\u00e9\u00a0!\n"},
+		{"<p>unclosed <b>bold<p>next", "unclosed bold\nnext\n"},
+	}
+	for _, tt := range tests {
+		text, err := htmltext.Text(tt.html)
+		if err != nil {
+			t.Errorf("html %q failed: %s", tt.html, err)
+			continue
+		}
+		if text != tt.text {
+			t.Errorf("html %q want %q got %q", tt.html, tt.text, text)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package htmltext
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/RealGeeks/docparser"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var bareURL = regexp.MustCompile(`https?://[^\s<>"']+[^\s<>"'.,;:!?)]`)
+
+// PatternLinks is a docparser.Pattern that extracts the hyperlinks of
+// HTML content, returning a list of Fields with the keys "url" and
+// "text", the visible text of the link
+type PatternLinks struct {
+	Name string
+
+	// OutputKey is the field the list of links is stored in, defaults
+	// to "links"
+	OutputKey string
+
+	// URLContains keeps only the links whose URL contains it, i.e.
+	// "mls.example.com". Optional
+	URLContains string
+
+	// BareURLs also extracts the URLs in the text that aren't inside
+	// an <a> element, with the URL as their text
+	BareURLs bool
+
+	Optional bool
+}
+
+// Search for links in the HTML content, in document order
+//
+// <a> elements without href are ignored. Return NoMatch error if no
+// link is found and the pattern isn't Optional
+func (pl *PatternLinks) Search(content string) (docparser.Fields, error) {
+	doc, err := html.Parse(strings.NewReader(content))
+	if err != nil {
+		return docparser.Fields{}, err
+	}
+	links := []docparser.Fields{}
+	add := func(url, text string) {
+		if strings.Contains(url, pl.URLContains) {
+			links = append(links, docparser.Fields{"url": url, "text": text})
+		}
+	}
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch {
+		case n.Type == html.ElementNode && skipElements[n.DataAtom]:
+			return
+		case n.Type == html.ElementNode && n.DataAtom == atom.A:
+			if url, ok := lookupAttr(n, "href"); ok {
+				w := &textWriter{}
+				w.walk(n)
+				add(strings.TrimSpace(url), strings.TrimSpace(w.String()))
+				return
+			}
+		case n.Type == html.TextNode && pl.BareURLs:
+			for _, url := range bareURL.FindAllString(n.Data, -1) {
+				add(url, url)
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	if len(links) == 0 && !pl.Optional {
+		return docparser.Fields{}, docparser.NewNoMatch(pl.Name, content)
+	}
+	return docparser.Fields{pl.outputKey(): links}, nil
+}
+
+// FieldNames returns the name of the list field
+func (pl *PatternLinks) FieldNames() []string {
+	return []string{pl.outputKey()}
+}
+
+// FieldSchemas describes the list field for docparser.JSONSchema, an
+// array of objects with "url" and "text"
+func (pl *PatternLinks) FieldSchemas() map[string]interface{} {
+	str := map[string]interface{}{"type": "string"}
+	return map[string]interface{}{
+		pl.outputKey(): map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{"url": str, "text": str},
+			},
+		},
+	}
+}
+
+func (pl *PatternLinks) outputKey() string {
+	if pl.OutputKey == "" {
+		return "links"
+	}
+	return pl.OutputKey
+}
@@ -0,0 +1,109 @@
+package htmltext_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+	"github.com/RealGeeks/docparser/htmltext"
+)
+
+func ExamplePatternLinks() {
+	pattern := &htmltext.PatternLinks{
+		Name:        "Listing links",
+		URLContains: "mls.example.com",
+	}
+
+	content := `<p>New listing: <a href="https://mls.example.com/2211">View <b>Listing</b></a></p>
+<p><a href="https://example.com/unsubscribe">Unsubscribe</a></p>`
+
+	fields, err := pattern.Search(content)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, link := range fields.GetMapSlice("links") {
+		fmt.Println(link["url"], link["text"])
+	}
+	// Output:
+	// https://mls.example.com/2211 View Listing
+}
+
+func TestPatternLinks(t *testing.T) {
+	content := `<html><head><style>a { color: red }</style></head><body>
+<a name="top">no href</a>
+<p>See <a href=" /listing/1 ">listing 1</a> or https://site.example.com/listing/2.</p>
+<a href="mailto:bob@example.com">Email https://ignored.example.com</a>
+<script>var u = "https://script.example.com"</script>
+</body></html>`
+
+	pattern := &htmltext.PatternLinks{OutputKey: "urls"}
+	fields, err := pattern.Search(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]string{
+		{"url": "/listing/1", "text": "listing 1"},
+		{"url": "mailto:bob@example.com", "text": "Email https://ignored.example.com"},
+	}
+	if links := fields.GetMapSlice("urls"); !reflect.DeepEqual(links, want) {
+		t.Errorf("want %v got %v", want, links)
+	}
+
+	pattern.BareURLs = true
+	fields, err = pattern.Search(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []map[string]string{
+		{"url": "/listing/1", "text": "listing 1"},
+		{"url": "https://site.example.com/listing/2", "text": "https://site.example.com/listing/2"},
+		{"url": "mailto:bob@example.com", "text": "Email https://ignored.example.com"},
+	}
+	if links := fields.GetMapSlice("urls"); !reflect.DeepEqual(links, want) {
+		t.Errorf("want %v got %v", want, links)
+	}
+}
+
+func TestPatternLinksNoMatch(t *testing.T) {
+	pattern := &htmltext.PatternLinks{Name: "Links", URLContains: "mls"}
+	_, err := pattern.Search(`<a href="https://example.com">x</a>`)
+	if _, ok := err.(*docparser.NoMatch); !ok {
+		t.Errorf("want NoMatch got %#v", err)
+	}
+
+	pattern.Optional = true
+	fields, err := pattern.Search("no links")
+	if err != nil || len(fields.GetMapSlice("links")) != 0 {
+		t.Errorf("optional want no links got %v (%v)", fields, err)
+	}
+}
+
+func TestPatternLinksJSONSchema(t *testing.T) {
+	ds := docparser.Documents{
+		&docparser.Document{Patterns: []docparser.Pattern{&htmltext.PatternLinks{}}},
+	}
+	schema, err := docparser.JSONSchema(ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `"links": {
+      "items": {
+        "properties": {
+          "text": {
+            "type": "string"
+          },
+          "url": {
+            "type": "string"
+          }
+        },
+        "type": "object"
+      },
+      "type": "array"
+    }`
+	if !strings.Contains(string(schema), want) {
+		t.Errorf("want links schema %s got %s", want, schema)
+	}
+}
@@ -0,0 +1,237 @@
+package htmltext
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/RealGeeks/docparser"
+	"golang.org/x/net/html"
+)
+
+// PatternSelector is a docparser.Pattern that extracts the text of HTML
+// elements found with CSS selectors
+//
+// Supported selectors are type (div), id (#details), class (.price),
+// universal (*) and attribute ([name] and [name=value]) selectors,
+// which can be compounded (td.price) and combined with the descendant
+// (table td) and child (tr > td) combinators
+type PatternSelector struct {
+	Name string
+
+	// Selectors maps field names to the selector of the element
+	// holding its value. If several elements match the first one is
+	// used
+	Selectors map[string]string
+
+	// OptionalFields lists the fields whose selector may find nothing.
+	// All other selectors are required
+	OptionalFields []string
+}
+
+// Search for all Selectors in the HTML content
+//
+// Return NoMatch error if a required selector finds no element, and an
+// error if a selector is invalid
+func (ps *PatternSelector) Search(content string) (docparser.Fields, error) {
+	doc, err := html.Parse(strings.NewReader(content))
+	if err != nil {
+		return docparser.Fields{}, err
+	}
+	fields := docparser.Fields{}
+	for key, selector := range ps.Selectors {
+		sel, err := parseSelector(selector)
+		if err != nil {
+			return docparser.Fields{}, fmt.Errorf("invalid selector for %s: %q (%v)", ps.Name, selector, err)
+		}
+		n := sel.first(doc)
+		if n == nil {
+			if ps.isOptional(key) {
+				continue
+			}
+			return docparser.Fields{}, docparser.NewNoMatch(ps.Name+" - "+key, content)
+		}
+		w := &textWriter{}
+		w.walk(n)
+		fields[key] = strings.TrimSpace(w.String())
+	}
+	return fields, nil
+}
+
+func (ps *PatternSelector) isOptional(key string) bool {
+	for _, k := range ps.OptionalFields {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// selector is a list of compound selectors, each one applying to a
+// descendant (or child) of the element matched by the previous one
+type selector []compound
+
+type compound struct {
+	child   bool // must be a direct child of the previous compound
+	tag     string
+	id      string
+	classes []string
+	attrs   []attrSelector
+}
+
+type attrSelector struct {
+	name, value string
+	hasValue    bool
+}
+
+func parseSelector(s string) (selector, error) {
+	var sel selector
+	child := false
+	for _, tok := range strings.Fields(strings.Replace(s, ">", " > ", -1)) {
+		if tok == ">" {
+			if len(sel) == 0 || child {
+				return nil, fmt.Errorf("unexpected >")
+			}
+			child = true
+			continue
+		}
+		c, err := parseCompound(tok)
+		if err != nil {
+			return nil, err
+		}
+		c.child = child
+		child = false
+		sel = append(sel, c)
+	}
+	if len(sel) == 0 || child {
+		return nil, fmt.Errorf("incomplete selector")
+	}
+	return sel, nil
+}
+
+func parseCompound(s string) (compound, error) {
+	c := compound{}
+	i := strings.IndexAny(s, "#.[")
+	if i == -1 {
+		i = len(s)
+	}
+	if tag := s[:i]; tag != "*" {
+		c.tag = strings.ToLower(tag)
+	}
+	s = s[i:]
+	for s != "" {
+		switch s[0] {
+		case '#', '.':
+			end := strings.IndexAny(s[1:], "#.[")
+			if end == -1 {
+				end = len(s) - 1
+			}
+			name := s[1 : end+1]
+			if name == "" {
+				return c, fmt.Errorf("empty name after %q", s[0])
+			}
+			if s[0] == '#' {
+				c.id = name
+			} else {
+				c.classes = append(c.classes, name)
+			}
+			s = s[end+1:]
+		case '[':
+			end := strings.IndexByte(s, ']')
+			if end == -1 {
+				return c, fmt.Errorf("unclosed [")
+			}
+			attr := attrSelector{name: s[1:end]}
+			if eq := strings.IndexByte(attr.name, '='); eq != -1 {
+				attr.name, attr.value = attr.name[:eq], strings.Trim(attr.name[eq+1:], `"'`)
+				attr.hasValue = true
+			}
+			if attr.name == "" {
+				return c, fmt.Errorf("empty attribute name")
+			}
+			c.attrs = append(c.attrs, attr)
+			s = s[end+1:]
+		default:
+			return c, fmt.Errorf("unexpected %q", s[0])
+		}
+	}
+	return c, nil
+}
+
+// first returns the first element in document order matching sel
+func (sel selector) first(n *html.Node) *html.Node {
+	if n.Type == html.ElementNode && sel.matches(n, len(sel)-1) {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := sel.first(c); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// matches reports whether n matches the selector up to compound i
+func (sel selector) matches(n *html.Node, i int) bool {
+	if !sel[i].matches(n) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+	for p := n.Parent; p != nil && p.Type == html.ElementNode; p = p.Parent {
+		if sel.matches(p, i-1) {
+			return true
+		}
+		if sel[i].child {
+			break
+		}
+	}
+	return false
+}
+
+func (c compound) matches(n *html.Node) bool {
+	if c.tag != "" && n.Data != c.tag {
+		return false
+	}
+	if c.id != "" && attr(n, "id") != c.id {
+		return false
+	}
+	if len(c.classes) > 0 {
+		classes := strings.Fields(attr(n, "class"))
+		for _, want := range c.classes {
+			if !contains(classes, want) {
+				return false
+			}
+		}
+	}
+	for _, a := range c.attrs {
+		v, ok := lookupAttr(n, a.name)
+		if !ok || (a.hasValue && v != a.value) {
+			return false
+		}
+	}
+	return true
+}
+
+func attr(n *html.Node, name string) string {
+	v, _ := lookupAttr(n, name)
+	return v
+}
+
+func lookupAttr(n *html.Node, name string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
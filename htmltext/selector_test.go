@@ -0,0 +1,99 @@
+package htmltext_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+	"github.com/RealGeeks/docparser/htmltext"
+)
+
+func ExamplePatternSelector() {
+	pattern := &htmltext.PatternSelector{
+		Name: "Listing details",
+		Selectors: map[string]string{
+			"mls":   "#details td.mls",
+			"price": "#details tr > td.price",
+		},
+	}
+
+	content := `<div id="details"><table>
+<tr><td class="label">MLS</td><td class="mls">2211</td></tr>
+<tr><td class="label">Price</td><td class="price"> $450,000 </td></tr>
+</table></div>`
+
+	fields, err := pattern.Search(content)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(fields.GetString("mls"))
+	fmt.Println(fields.GetString("price"))
+	// Output:
+	// 2211
+	// $450,000
+}
+
+const selectorContent = `<html><body>
+<div class="contact main" id="buyer">
+  <p>Name: <span class="name">Mark</span></p>
+  <a href="/listing" data-kind="listing">View <b>listing</b></a>
+</div>
+<div class="contact" id="seller">
+  <span class="name">Jane</span>
+</div>
+</body></html>`
+
+func TestPatternSelector(t *testing.T) {
+	pattern := &htmltext.PatternSelector{
+		Selectors: map[string]string{
+			"first":   "span.name",
+			"buyer":   "#buyer .name",
+			"seller":  "div#seller > span",
+			"main":    "div.main.contact span",
+			"link":    "a[data-kind=listing]",
+			"href":    "*[href]",
+			"missing": "#seller > p",
+		},
+		OptionalFields: []string{"missing"},
+	}
+	fields, err := pattern.Search(selectorContent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := docparser.Fields{
+		"first":  "Mark",
+		"buyer":  "Mark",
+		"seller": "Jane",
+		"main":   "Mark",
+		"link":   "View listing",
+		"href":   "View listing",
+	}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("want %v got %v", want, fields)
+	}
+}
+
+func TestPatternSelectorNoMatch(t *testing.T) {
+	pattern := &htmltext.PatternSelector{
+		Name:      "Seller",
+		Selectors: map[string]string{"name": "#seller > p > span"},
+	}
+	_, err := pattern.Search(selectorContent)
+	if _, ok := err.(*docparser.NoMatch); !ok {
+		t.Fatalf("want NoMatch, got %#v", err)
+	}
+	if err.Error() != `No match for "Seller - name"` {
+		t.Errorf("invalid error: %s", err)
+	}
+}
+
+func TestPatternSelectorInvalid(t *testing.T) {
+	for _, selector := range []string{"", "> p", "div >", "p > > a", "a[href", "div.", "#"} {
+		pattern := &htmltext.PatternSelector{Selectors: map[string]string{"x": selector}}
+		if _, err := pattern.Search(selectorContent); err == nil {
+			t.Errorf("selector %q should be invalid", selector)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package docparser
+
+import "time"
+
+// Hooks are callbacks Instrument calls around Pattern.Search()
+//
+// All callbacks are optional
+type Hooks struct {
+	// OnMatch is called with the fields when Search succeeds
+	OnMatch func(f Fields)
+
+	// OnNoMatch is called with the error when Search fails, usually a
+	// NoMatch
+	OnNoMatch func(err error)
+
+	// OnDuration is called with the time Search took, whether it
+	// succeeded or not
+	OnDuration func(d time.Duration)
+}
+
+// Instrument wraps p so hooks are called every time it's searched, i.e.
+// to collect per-pattern latency and match rate metrics
+//
+// The returned Pattern returns the same fields and errors as p. If p is
+// a PatternWithFields the fields collected so far are forwarded to it
+func Instrument(p Pattern, hooks Hooks) Pattern {
+	return &instrumented{wrappedPattern{p}, hooks}
+}
+
+type instrumented struct {
+	wrappedPattern
+	hooks Hooks
+}
+
+func (in *instrumented) Search(content string) (Fields, error) {
+	start := time.Now()
+	f, err := in.pattern.Search(content)
+	if in.hooks.OnDuration != nil {
+		in.hooks.OnDuration(time.Since(start))
+	}
+	if err != nil {
+		if in.hooks.OnNoMatch != nil {
+			in.hooks.OnNoMatch(err)
+		}
+	} else if in.hooks.OnMatch != nil {
+		in.hooks.OnMatch(f)
+	}
+	return f, err
+}
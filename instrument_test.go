@@ -0,0 +1,81 @@
+package docparser_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/RealGeeks/docparser"
+)
+
+func ExampleInstrument() {
+	matches, misses := 0, 0
+	pattern := docparser.Instrument(
+		&docparser.PatternGroup{
+			Name:  "Name",
+			Regex: regexp.MustCompile(`Name: (?P<name>.*)`),
+		},
+		docparser.Hooks{
+			OnMatch:   func(f docparser.Fields) { matches++ },
+			OnNoMatch: func(err error) { misses++ },
+		},
+	)
+
+	pattern.Search("Name: Mark")
+	pattern.Search("Phone: 221-1122")
+	pattern.Search("Name: Jane")
+
+	fmt.Printf("%d matches, %d misses\n", matches, misses)
+	// Output:
+	// 2 matches, 1 misses
+}
+
+func TestInstrument(t *testing.T) {
+	var (
+		matched   docparser.Fields
+		failed    error
+		durations int
+	)
+	hooks := docparser.Hooks{
+		OnMatch:    func(f docparser.Fields) { matched = f },
+		OnNoMatch:  func(err error) { failed = err },
+		OnDuration: func(d time.Duration) { durations++ },
+	}
+	document := &docparser.Document{
+		Patterns: []docparser.Pattern{
+			testDocuments[1].Patterns[0],
+			docparser.Instrument(testDocuments[1].Patterns[1], hooks),
+		},
+	}
+
+	fields, err := document.Search("My Name: josh\nMy name and email joshjosh@site.com\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if email := fields.GetString("email"); email != "josh@site.com" {
+		t.Errorf("want email %q got %q", "josh@site.com", email)
+	}
+	if matched.GetString("email") != "josh@site.com" || failed != nil {
+		t.Errorf("OnMatch not called with fields: %v, %v", matched, failed)
+	}
+
+	matched = nil
+	_, err = document.Search("My Name: josh\n")
+	if err == nil || failed != err || matched != nil {
+		t.Errorf("OnNoMatch not called with error: %v, %v", failed, err)
+	}
+	if durations != 2 {
+		t.Errorf("want 2 durations got %d", durations)
+	}
+}
+
+func TestInstrumentNoHooks(t *testing.T) {
+	pattern := docparser.Instrument(testDocuments[0], docparser.Hooks{})
+	if _, err := pattern.Search("Name: bob\nEmail: bob@site.com\n"); err != nil {
+		t.Error(err)
+	}
+	if _, err := pattern.Search(""); err == nil {
+		t.Error("empty content did not fail")
+	}
+}
@@ -0,0 +1,119 @@
+package docparser
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PatternJSON is a Pattern implementation that extracts fields from a
+// JSON object embedded in the content
+type PatternJSON struct {
+	Name string
+
+	// Regex where the first group captures the JSON text. If nil the
+	// whole content is parsed as JSON
+	Regex *regexp.Regexp
+
+	// Paths maps a dotted path in the JSON document to the field name
+	// it's stored as, i.e. "lead.contact.email" -> "email". Array
+	// elements are addressed by index, i.e. "items.0.id"
+	//
+	// Strings, numbers and booleans are stored as strings, objects and
+	// arrays as their JSON text. Paths not present in the document are
+	// skipped
+	Paths map[string]string
+
+	Optional bool
+}
+
+// FieldNames returns the field names of all Paths
+func (pj *PatternJSON) FieldNames() []string {
+	names := make([]string, 0, len(pj.Paths))
+	for _, name := range pj.Paths {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Search for the JSON document in content and extract all Paths from it
+//
+// Return NoMatch error if Regex doesn't match or if the captured text
+// isn't valid JSON
+func (pj *PatternJSON) Search(content string) (Fields, error) {
+	text := content
+	if pj.Regex != nil {
+		matches := pj.Regex.FindStringSubmatch(content)
+		if matches == nil {
+			if pj.Optional {
+				return Fields{}, nil
+			}
+			return Fields{}, NewNoMatch(pj.Name, content)
+		}
+		text = matches[1]
+	}
+
+	var doc interface{}
+	dec := json.NewDecoder(strings.NewReader(text))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil || dec.More() {
+		if pj.Optional {
+			return Fields{}, nil
+		}
+		return Fields{}, NewNoMatch(pj.Name+" - invalid JSON", text)
+	}
+
+	fields := Fields{}
+	for path, key := range pj.Paths {
+		if v, ok := jsonPath(doc, path); ok {
+			fields[key] = v
+		}
+	}
+	return fields, nil
+}
+
+// jsonPath returns the value found in doc following the dotted path,
+// converted to string
+func jsonPath(doc interface{}, path string) (string, bool) {
+	for _, step := range strings.Split(path, ".") {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			v, ok := node[step]
+			if !ok {
+				return "", false
+			}
+			doc = v
+		case []interface{}:
+			i, err := strconv.Atoi(step)
+			if err != nil || i < 0 || i >= len(node) {
+				return "", false
+			}
+			doc = node[i]
+		default:
+			return "", false
+		}
+	}
+
+	switch v := doc.(type) {
+	case nil:
+		return "", false
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	default:
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(v); err != nil {
+			return "", false
+		}
+		return strings.TrimSuffix(buf.String(), "\n"), true
+	}
+}
@@ -0,0 +1,97 @@
+package docparser_test
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+func ExamplePatternJSON() {
+	pattern := &docparser.PatternJSON{
+		Name: "Lead payload",
+		// Note the 's' flag so the JSON can span several lines
+		Regex: regexp.MustCompile(`(?s:Payload:\n(\{.*\}))`),
+		Paths: map[string]string{
+			"lead.name":          "name",
+			"lead.phones.0":      "phone",
+			"lead.property.beds": "beds",
+		},
+	}
+
+	content := `New lead
+Payload:
+{"lead": {"name": "Mark Stewart", "phones": ["(123) 221-1122"], "property": {"beds": 3}}}
+`
+
+	fields, err := pattern.Search(content)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(fields.GetString("name"))
+	fmt.Println(fields.GetString("phone"))
+	fmt.Println(fields.GetString("beds"))
+	// Output:
+	// Mark Stewart
+	// (123) 221-1122
+	// 3
+}
+
+func TestPatternJSON(t *testing.T) {
+	pattern := &docparser.PatternJSON{
+		Paths: map[string]string{
+			"a":       "a",
+			"b.c":     "c",
+			"b.d":     "d",
+			"list":    "list",
+			"list.1":  "second",
+			"list.9":  "missing",
+			"price":   "price",
+			"empty":   "empty",
+			"missing": "missing",
+			"a.b":     "missing",
+		},
+	}
+
+	fields, err := pattern.Search(`{"a": "x<y", "b": {"c": true, "d": {"e": 1}}, "list": [1, 2], "price": 1250000.00, "empty": null}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := docparser.Fields{
+		"a":      "x<y",
+		"c":      "true",
+		"d":      `{"e":1}`,
+		"list":   "[1,2]",
+		"second": "2",
+		"price":  "1250000.00",
+	}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("want %v got %v", want, fields)
+	}
+}
+
+func TestPatternJSONNoMatch(t *testing.T) {
+	var tests = []struct {
+		pattern *docparser.PatternJSON
+		content string
+	}{
+		{&docparser.PatternJSON{Name: "JSON"}, `not json`},
+		{&docparser.PatternJSON{Name: "JSON"}, `{"a": 1} {"b": 2}`},
+		{&docparser.PatternJSON{Name: "JSON", Regex: regexp.MustCompile(`json=(.*)`)}, `no payload`},
+		{&docparser.PatternJSON{Name: "JSON", Regex: regexp.MustCompile(`json=(.*)`)}, `json={"a": `},
+	}
+	for _, tt := range tests {
+		_, err := tt.pattern.Search(tt.content)
+		if _, ok := err.(*docparser.NoMatch); !ok {
+			t.Errorf("content %q want NoMatch, got %#v", tt.content, err)
+		}
+		tt.pattern.Optional = true
+		fields, err := tt.pattern.Search(tt.content)
+		if err != nil || len(fields) != 0 {
+			t.Errorf("content %q optional want empty fields, got %v (%v)", tt.content, fields, err)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package docparser
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// PatternKeyValue is a Pattern implementation that extracts fields from
+// "Label: value" lines, matching labels loosely so small wording changes
+// between senders don't need new regexes
+//
+// Labels are compared normalized: case is ignored and punctuation and
+// runs of spaces are collapsed, so "Phone #:" matches the label "phone"
+type PatternKeyValue struct {
+	Name string
+
+	// Labels maps field names to the label variants that populate them,
+	// i.e. "phone": {"Phone Number", "Tel"}. The field name itself is
+	// always accepted as a label. If Labels is empty every labeled line
+	// is extracted, using its label as the field name
+	Labels map[string][]string
+
+	// Separator between label and value, defaults to ":"
+	Separator string
+
+	// MaxDistance is the maximum edit distance between a normalized
+	// label in the content and one in Labels for them to match, to
+	// tolerate typos like "Emial". Zero only matches exactly
+	MaxDistance int
+
+	// KeyCase changes the case of the field names taken from the
+	// content when Labels is empty, so "NAME:" and "Name:" can end up
+	// in the same field. Values are always stored as found
+	KeyCase KeyCase
+
+	Optional bool
+}
+
+// KeyCase is the case of the field names PatternKeyValue takes from
+// labels
+type KeyCase int
+
+const (
+	// KeyCasePreserve keeps labels as found, the default
+	KeyCasePreserve KeyCase = iota
+	// KeyCaseLower lowercases labels
+	KeyCaseLower
+	// KeyCaseUpper uppercases labels
+	KeyCaseUpper
+)
+
+func (kc KeyCase) apply(key string) string {
+	switch kc {
+	case KeyCaseLower:
+		return strings.ToLower(key)
+	case KeyCaseUpper:
+		return strings.ToUpper(key)
+	}
+	return key
+}
+
+// Search each line of content for a label followed by Separator
+//
+// When a field is found in several lines, the first one wins. Return
+// NoMatch error if no field is found and the pattern isn't Optional
+func (pk *PatternKeyValue) Search(content string) (Fields, error) {
+	sep := pk.Separator
+	if sep == "" {
+		sep = ":"
+	}
+	fields := Fields{}
+	for _, line := range strings.Split(content, "\n") {
+		i := strings.Index(line, sep)
+		if i == -1 {
+			continue
+		}
+		label := strings.TrimSpace(line[:i])
+		if label == "" {
+			continue
+		}
+		key, ok := pk.KeyCase.apply(label), true
+		if len(pk.Labels) > 0 {
+			key, ok = pk.lookup(normalizeLabel(label))
+		}
+		if !ok || fields.Has(key) {
+			continue
+		}
+		fields[key] = strings.TrimSpace(line[i+len(sep):])
+	}
+	if len(fields) == 0 && !pk.Optional {
+		return Fields{}, NewNoMatch(pk.Name, content)
+	}
+	return fields, nil
+}
+
+// lookup returns the field whose labels are closest to label, within
+// MaxDistance. Ties are broken by field name
+func (pk *PatternKeyValue) lookup(label string) (string, bool) {
+	best, bestDistance := "", pk.MaxDistance+1
+	for _, key := range pk.FieldNames() {
+		for _, l := range append([]string{key}, pk.Labels[key]...) {
+			if d := editDistance(label, normalizeLabel(l)); d < bestDistance {
+				best, bestDistance = key, d
+			}
+		}
+	}
+	return best, best != ""
+}
+
+// FieldNames returns the keys of Labels, sorted
+func (pk *PatternKeyValue) FieldNames() []string {
+	keys := make([]string, 0, len(pk.Labels))
+	for key := range pk.Labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// normalizeLabel lowercases label, replaces each run of characters that
+// aren't letters or digits with a single space and trims it
+func normalizeLabel(label string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(label), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// editDistance returns the edit distance between a and b in runes,
+// counting insertions, deletions, substitutions and transpositions of
+// adjacent runes, the most common typos
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min3(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] && d[i-2][j-2]+1 < d[i][j] {
+				d[i][j] = d[i-2][j-2] + 1
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
@@ -0,0 +1,133 @@
+package docparser_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+func ExamplePatternKeyValue() {
+	pattern := &docparser.PatternKeyValue{
+		Name: "Contact",
+		Labels: map[string][]string{
+			"name":  {"Full Name"},
+			"phone": {"Phone Number", "Tel"},
+		},
+	}
+
+	content := `Full Name: Mark Stewart
+Tel: (123) 221-1122
+`
+
+	fields, err := pattern.Search(content)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(fields.GetString("name"))
+	fmt.Println(fields.GetString("phone"))
+	// Output:
+	// Mark Stewart
+	// (123) 221-1122
+}
+
+func TestPatternKeyValue(t *testing.T) {
+	pattern := &docparser.PatternKeyValue{
+		Labels: map[string][]string{
+			"phone": {"Phone Number", "Tel"},
+			"email": {"E-mail Address"},
+		},
+	}
+	var tests = []struct {
+		content string
+		want    docparser.Fields
+	}{
+		{"Phone: 111\n", docparser.Fields{"phone": "111"}},
+		{"PHONE #: 111\r\n", docparser.Fields{"phone": "111"}},
+		{"  phone number :111", docparser.Fields{"phone": "111"}},
+		{"Tel: 111\nTel: 222\nEmail address: bob@site.com", docparser.Fields{"phone": "111"}},
+		{"e-mail address: bob@site.com\nNote: call at 10:30", docparser.Fields{"email": "bob@site.com"}},
+		{"Emial: bob@site.com\nTel: 111", docparser.Fields{"phone": "111"}},
+	}
+	for _, tt := range tests {
+		fields, err := pattern.Search(tt.content)
+		if err != nil {
+			t.Errorf("content %q failed: %s", tt.content, err)
+			continue
+		}
+		if !reflect.DeepEqual(fields, tt.want) {
+			t.Errorf("content %q want %v got %v", tt.content, tt.want, fields)
+		}
+	}
+}
+
+func TestPatternKeyValueMaxDistance(t *testing.T) {
+	pattern := &docparser.PatternKeyValue{
+		Labels:      map[string][]string{"email": nil, "phone": {"Tel"}},
+		MaxDistance: 1,
+	}
+	fields, err := pattern.Search("Emial: bob@site.com\nPhoen: 111\nTe: 222\nFax: 333")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := docparser.Fields{"email": "bob@site.com", "phone": "111"}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("want %v got %v", want, fields)
+	}
+}
+
+func TestPatternKeyValueAllLabels(t *testing.T) {
+	pattern := &docparser.PatternKeyValue{Separator: "="}
+	fields, err := pattern.Search("Name = Bob\n= nothing\nbeds=3\nno separator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := docparser.Fields{"Name": "Bob", "beds": "3"}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("want %v got %v", want, fields)
+	}
+}
+
+func TestPatternKeyValueKeyCase(t *testing.T) {
+	content := "NAME: Mark STEWART\nName: Bob\nE-mail: Bob@Site.com\n"
+	var tests = []struct {
+		keyCase docparser.KeyCase
+		want    docparser.Fields
+	}{
+		{docparser.KeyCasePreserve, docparser.Fields{"NAME": "Mark STEWART", "Name": "Bob", "E-mail": "Bob@Site.com"}},
+		{docparser.KeyCaseLower, docparser.Fields{"name": "Mark STEWART", "e-mail": "Bob@Site.com"}},
+		{docparser.KeyCaseUpper, docparser.Fields{"NAME": "Mark STEWART", "E-MAIL": "Bob@Site.com"}},
+	}
+	for _, tt := range tests {
+		pattern := &docparser.PatternKeyValue{KeyCase: tt.keyCase}
+		fields, err := pattern.Search(content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(fields, tt.want) {
+			t.Errorf("%d: want %v got %v", tt.keyCase, tt.want, fields)
+		}
+	}
+
+	pattern := &docparser.PatternKeyValue{Labels: map[string][]string{"Email": {"e-mail"}}, KeyCase: docparser.KeyCaseUpper}
+	fields, err := pattern.Search(content)
+	if err != nil || !reflect.DeepEqual(fields, docparser.Fields{"Email": "Bob@Site.com"}) {
+		t.Errorf("KeyCase should not change Labels keys: %v (%v)", fields, err)
+	}
+}
+
+func TestPatternKeyValueNoMatch(t *testing.T) {
+	pattern := &docparser.PatternKeyValue{
+		Name:   "Contact",
+		Labels: map[string][]string{"phone": nil},
+	}
+	if _, err := pattern.Search("Email: bob@site.com"); err == nil || err.Error() != `No match for "Contact"` {
+		t.Errorf("want NoMatch got %v", err)
+	}
+	pattern.Optional = true
+	if fields, err := pattern.Search("Email: bob@site.com"); err != nil || len(fields) != 0 {
+		t.Errorf("want empty fields got %v %v", fields, err)
+	}
+}
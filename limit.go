@@ -0,0 +1,48 @@
+package docparser
+
+import "fmt"
+
+// ContentTooLarge error returned by PatternLimit when the content is
+// longer than allowed
+type ContentTooLarge struct {
+	Name   string // pattern name that refused the content
+	Length int    // length of the content in bytes
+	Max    int    // maximum length allowed in bytes
+}
+
+func (e *ContentTooLarge) Error() string {
+	return fmt.Sprintf("Content too large for %q: %d bytes, max %d", e.Name, e.Length, e.Max)
+}
+
+// PatternLimit is a Pattern that guards another Pattern against
+// oversized content
+//
+// Regexes run in linear time but huge inputs are still expensive, so
+// wrapping a Document, or a whole Documents, with PatternLimit bounds
+// the worst case for untrusted content:
+//
+//	limited := &PatternLimit{Name: "emails", MaxContentLength: 1 << 20, Pattern: &documents}
+type PatternLimit struct {
+	Name string
+
+	// MaxContentLength is the maximum content length in bytes. Zero
+	// means no limit
+	MaxContentLength int
+
+	// Pattern to run if content is within the limit
+	Pattern Pattern
+}
+
+// Search returns a ContentTooLarge error if content is longer than
+// MaxContentLength, otherwise returns the result of Pattern.Search()
+func (pl *PatternLimit) Search(content string) (Fields, error) {
+	if pl.MaxContentLength > 0 && len(content) > pl.MaxContentLength {
+		return Fields{}, &ContentTooLarge{pl.Name, len(content), pl.MaxContentLength}
+	}
+	return pl.Pattern.Search(content)
+}
+
+// FieldNames, SetFields and GetFields forward to Pattern, see wrappedPattern
+func (pl *PatternLimit) FieldNames() []string { return wrappedPattern{pl.Pattern}.FieldNames() }
+func (pl *PatternLimit) SetFields(f Fields)   { wrappedPattern{pl.Pattern}.SetFields(f) }
+func (pl *PatternLimit) GetFields() Fields    { return wrappedPattern{pl.Pattern}.GetFields() }
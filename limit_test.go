@@ -0,0 +1,57 @@
+package docparser_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+func TestPatternLimit(t *testing.T) {
+	pattern := &docparser.PatternLimit{
+		Name:             "Emails",
+		MaxContentLength: 64,
+		Pattern:          &testDocuments,
+	}
+
+	fields, err := pattern.Search("Name: bob\nEmail: bob@site.com\n")
+	if err != nil {
+		t.Fatalf("small content failed: %s", err)
+	}
+	if name := fields.GetString("name"); name != "bob" {
+		t.Errorf("want name %q got %q", "bob", name)
+	}
+
+	_, err = pattern.Search("Name: bob\nEmail: bob@site.com\n" + strings.Repeat("x", 64))
+	tooLarge, ok := err.(*docparser.ContentTooLarge)
+	if !ok {
+		t.Fatalf("want ContentTooLarge error, got %#v", err)
+	}
+	if tooLarge.Length != 94 || tooLarge.Max != 64 {
+		t.Errorf("invalid error: %#v", tooLarge)
+	}
+	if err.Error() != `Content too large for "Emails": 94 bytes, max 64` {
+		t.Errorf("invalid error message: %s", err)
+	}
+}
+
+func TestPatternLimitWithFields(t *testing.T) {
+	document := &docparser.Document{
+		Patterns: []docparser.Pattern{
+			testDocuments[1].Patterns[0],
+			&docparser.PatternLimit{
+				Name:             "Email",
+				MaxContentLength: 1024,
+				Pattern:          testDocuments[1].Patterns[1],
+			},
+		},
+	}
+
+	fields, err := document.Search("My Name: josh\nMy name and email joshjosh@site.com\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if email := fields.GetString("email"); email != "josh@site.com" {
+		t.Errorf("want email %q got %q", "josh@site.com", email)
+	}
+}
@@ -0,0 +1,31 @@
+package docparser
+
+import "fmt"
+
+// SearchMany searches each of contents with d and returns the result
+// with the most fields, and the index of the content it came from, i.e.
+// to parse both the text and the HTML part of a multipart email
+//
+// Ties are won by the content that comes first. If d fails for all
+// contents return an ErrorList with all errors and index -1
+func SearchMany(d *Document, contents ...string) (Fields, int, error) {
+	if len(contents) == 0 {
+		return Fields{}, -1, fmt.Errorf("no content to search for %s", d.Name)
+	}
+	best, bestIndex := Fields{}, -1
+	errList := &ErrorList{}
+	for i, content := range contents {
+		fields, err := d.Search(content)
+		if err != nil {
+			errList.Add(fmt.Errorf("Content %d: %s", i, err.Error()))
+			continue
+		}
+		if bestIndex == -1 || len(fields) > len(best) {
+			best, bestIndex = fields, i
+		}
+	}
+	if bestIndex == -1 {
+		return Fields{}, -1, errList
+	}
+	return best, bestIndex, nil
+}
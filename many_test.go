@@ -0,0 +1,56 @@
+package docparser_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+func ExampleSearchMany() {
+	document := &docparser.Document{
+		Patterns: []docparser.Pattern{
+			&docparser.PatternGroup{Regex: regexp.MustCompile(`Name: (?P<name>\w+)`)},
+			&docparser.PatternGroup{Regex: regexp.MustCompile(`Phone: (?P<phone>[\d-]+)`), Optional: true},
+		},
+	}
+
+	text := "Name: Mark"
+	html := "<p>Name: Mark</p><p>Phone: 221-1122</p>"
+	fields, index, err := docparser.SearchMany(document, text, html)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(index, fields.GetString("phone"))
+	// Output:
+	// 1 221-1122
+}
+
+func TestSearchMany(t *testing.T) {
+	document := &docparser.Document{
+		Name: "Lead",
+		Patterns: []docparser.Pattern{
+			&docparser.PatternGroup{Name: "Name", Regex: regexp.MustCompile(`Name: (?P<name>\w+)`)},
+			&docparser.PatternGroup{Regex: regexp.MustCompile(`Phone: (?P<phone>[\d-]+)`), Optional: true},
+		},
+	}
+
+	fields, index, err := docparser.SearchMany(document, "nothing", "Name: Mark", "Name: Bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index != 1 || fields.GetString("name") != "Mark" {
+		t.Errorf("tie not won by the first content: %d %v", index, fields)
+	}
+
+	_, index, err = docparser.SearchMany(document, "nothing", "still nothing")
+	if index != -1 || err == nil || err.Error() != `Content 0: No match for "Name"; Content 1: No match for "Name"` {
+		t.Errorf("invalid result %d %v", index, err)
+	}
+
+	if _, index, err = docparser.SearchMany(document); index != -1 || err == nil {
+		t.Errorf("no content did not return error: %d %v", index, err)
+	}
+}
@@ -0,0 +1,97 @@
+package docparser
+
+import (
+	"regexp"
+	"strings"
+)
+
+var markdownSeparator = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(?:\|\s*:?-+:?\s*)*\|?\s*$`)
+
+// PatternMarkdownTable is a Pattern implementation that extracts the
+// first Markdown table in the content, like:
+//
+//	| MLS  | Price    |
+//	|------|---------:|
+//	| 2211 | $450,000 |
+//
+// into a []Fields with one Fields for each row, keyed by the header
+// cells
+type PatternMarkdownTable struct {
+	Name string
+
+	// OutputKey is the field that holds the []Fields of all rows
+	OutputKey string
+
+	// Optional returns empty Fields instead of NoMatch if there's no
+	// table in the content
+	Optional bool
+}
+
+// Search for a header row followed by a separator row, and read the
+// rows after them up to the first line without a "|"
+//
+// Cells are trimmed and "\|" is a pipe inside a cell. Rows with fewer
+// cells than the header get empty values for the missing columns, and
+// extra cells are ignored. Return NoMatch error if no table is found
+func (pm *PatternMarkdownTable) Search(content string) (Fields, error) {
+	lines := strings.Split(content, "\n")
+	for i := 0; i+1 < len(lines); i++ {
+		if !strings.Contains(lines[i], "|") || !markdownSeparator.MatchString(lines[i+1]) {
+			continue
+		}
+		header := splitMarkdownRow(lines[i])
+		if len(header) != len(splitMarkdownRow(lines[i+1])) {
+			continue
+		}
+		rows := []Fields{}
+		for _, line := range lines[i+2:] {
+			if !strings.Contains(line, "|") {
+				break
+			}
+			cells := splitMarkdownRow(line)
+			row := Fields{}
+			for j, key := range header {
+				row[key] = ""
+				if j < len(cells) {
+					row[key] = cells[j]
+				}
+			}
+			rows = append(rows, row)
+		}
+		return Fields{pm.OutputKey: rows}, nil
+	}
+	if pm.Optional {
+		return Fields{}, nil
+	}
+	return Fields{}, NewNoMatch(pm.Name, content)
+}
+
+// FieldNames returns OutputKey
+func (pm *PatternMarkdownTable) FieldNames() []string {
+	return []string{pm.OutputKey}
+}
+
+// splitMarkdownRow returns the trimmed cells of a table row, without
+// the optional pipes at the start and end of the line
+func splitMarkdownRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
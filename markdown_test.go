@@ -0,0 +1,80 @@
+package docparser_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+func ExamplePatternMarkdownTable() {
+	pattern := &docparser.PatternMarkdownTable{Name: "Listings", OutputKey: "listings"}
+
+	content := `New listings:
+
+| MLS  | Price    |
+|------|---------:|
+| 2211 | $450,000 |
+| 1122 | $520,000 |
+
+Thanks`
+
+	fields, err := pattern.Search(content)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, row := range fields.GetMapSlice("listings") {
+		fmt.Println(row["MLS"], row["Price"])
+	}
+	// Output:
+	// 2211 $450,000
+	// 1122 $520,000
+}
+
+func TestPatternMarkdownTable(t *testing.T) {
+	content := "Not a table | just a pipe\n" +
+		"a | b\n" +
+		"plain line\n" +
+		"Name | Notes | Beds\r\n" +
+		":--- | :---: | ---\r\n" +
+		"Mark | likes a\\|b | 3\r\n" +
+		"| Jane |\r\n" +
+		"|Bob|x|2|extra|\r\n" +
+		"after the table\n" +
+		"| c | d |\n"
+
+	pattern := &docparser.PatternMarkdownTable{OutputKey: "rows"}
+	fields, err := pattern.Search(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]string{
+		{"Name": "Mark", "Notes": "likes a|b", "Beds": "3"},
+		{"Name": "Jane", "Notes": "", "Beds": ""},
+		{"Name": "Bob", "Notes": "x", "Beds": "2"},
+	}
+	if rows := fields.GetMapSlice("rows"); !reflect.DeepEqual(rows, want) {
+		t.Errorf("want %v got %v", want, rows)
+	}
+}
+
+func TestPatternMarkdownTableNoMatch(t *testing.T) {
+	pattern := &docparser.PatternMarkdownTable{Name: "Table", OutputKey: "rows"}
+	for _, content := range []string{"", "a | b\nc | d\n", "a | b\n---\n", "| a | b |\n|---|\n"} {
+		if _, err := pattern.Search(content); err == nil || err.Error() != `No match for "Table"` {
+			t.Errorf("%q: want NoMatch got %v", content, err)
+		}
+	}
+
+	pattern.Optional = true
+	if fields, err := pattern.Search("no table"); err != nil || len(fields) != 0 {
+		t.Errorf("optional want empty fields got %v (%v)", fields, err)
+	}
+
+	fields, err := pattern.Search("| a |\n|---|\n")
+	if err != nil || len(fields.GetMapSlice("rows")) != 0 {
+		t.Errorf("table without rows: invalid result %v (%v)", fields, err)
+	}
+}
@@ -0,0 +1,108 @@
+package docparser
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// MergePolicy decides what happens when fields from different sources
+// have the same key with different values
+type MergePolicy int
+
+const (
+	// MergeOverwrite keeps the last value, like Fields.Update
+	MergeOverwrite MergePolicy = iota
+
+	// MergeKeepFirst keeps the first value
+	MergeKeepFirst
+
+	// MergeError fails with a MergeConflict error
+	MergeError
+)
+
+// MergeConflict error returned by MergeError when a key has different
+// values
+type MergeConflict struct {
+	Key string
+}
+
+func (e *MergeConflict) Error() string {
+	return fmt.Sprintf("Merge conflict for %q", e.Key)
+}
+
+// merge updates f with other following policy, calling onSet, if not
+// nil, with each key whose value comes from other
+func (policy MergePolicy) merge(f, other Fields, onSet func(key string)) error {
+	for k, v := range other {
+		if prev, ok := f[k]; ok {
+			if reflect.DeepEqual(prev, v) {
+				continue
+			}
+			switch policy {
+			case MergeKeepFirst:
+				continue
+			case MergeError:
+				return &MergeConflict{k}
+			}
+		}
+		f[k] = v
+		if onSet != nil {
+			onSet(k)
+		}
+	}
+	return nil
+}
+
+// SearchMerge is like Search but instead of stopping at the first
+// Document that matches it searches all of them and merges the fields
+// of every one that matched, in order, with policy
+//
+// Only returns an ErrorList if no Document matched, or a MergeConflict
+// error for MergeError, prefixed with the Document index and wrapped so
+// errors.As finds it
+func (ds *Documents) SearchMerge(content string, policy MergePolicy) (Fields, error) {
+	errList := &ErrorList{}
+	f, matched := Fields{}, false
+	for i, doc := range *ds {
+		fields, err := doc.Search(content)
+		if err != nil {
+			errList.Add(fmt.Errorf("Document %d: %s", i, err.Error()))
+			continue
+		}
+		if err := policy.merge(f, fields, nil); err != nil {
+			return Fields{}, fmt.Errorf("Document %d: %w", i, err)
+		}
+		matched = true
+	}
+	if !matched {
+		return Fields{}, errList
+	}
+	return f, nil
+}
+
+// NamedFields are the Fields found by a source, i.e. a Document, and
+// its name
+type NamedFields struct {
+	Name   string
+	Fields Fields
+}
+
+// MergeWithProvenance merges the Fields of all inputs, in order, with
+// policy, and also returns the name of the input each field came from,
+// i.e. to audit results stitched from several sources
+//
+// When inputs have the same value for a key the first one is its
+// source. Return a MergeConflict error for MergeError, prefixed with the
+// input name and wrapped so errors.As finds it
+func MergeWithProvenance(policy MergePolicy, inputs ...NamedFields) (Fields, map[string]string, error) {
+	f, sources := Fields{}, map[string]string{}
+	for _, input := range inputs {
+		err := policy.merge(f, input.Fields, func(key string) {
+			sources[key] = input.Name
+		})
+		if err != nil {
+			return Fields{}, nil, fmt.Errorf("%s: %w", input.Name, err)
+		}
+	}
+	return f, sources, nil
+}
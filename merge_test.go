@@ -0,0 +1,142 @@
+package docparser_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+var mergeDocuments = docparser.Documents{
+	{
+		Patterns: []docparser.Pattern{
+			&docparser.PatternGroup{Regex: regexp.MustCompile(`Name: (?P<name>.*)\n`)},
+		},
+	},
+	{
+		Patterns: []docparser.Pattern{
+			&docparser.PatternGroup{Regex: regexp.MustCompile(`Listing: (?P<mls>\d+)\n`)},
+		},
+	},
+	{
+		Patterns: []docparser.Pattern{
+			&docparser.PatternGroup{Regex: regexp.MustCompile(`Buyer: (?P<name>.*)\n`)},
+		},
+	},
+}
+
+func ExampleDocuments_SearchMerge() {
+	content := "Name: Mark\nListing: 2211\n"
+
+	fields, err := mergeDocuments.SearchMerge(content, docparser.MergeError)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(fields.GetString("name"), fields.GetString("mls"))
+	// Output:
+	// Mark 2211
+}
+
+func TestDocumentsSearchMergePolicy(t *testing.T) {
+	content := "Name: Mark\nListing: 2211\nBuyer: Jane\n"
+	var tests = []struct {
+		policy docparser.MergePolicy
+		want   docparser.Fields
+	}{
+		{docparser.MergeOverwrite, docparser.Fields{"name": "Jane", "mls": "2211"}},
+		{docparser.MergeKeepFirst, docparser.Fields{"name": "Mark", "mls": "2211"}},
+	}
+	for _, tt := range tests {
+		fields, err := mergeDocuments.SearchMerge(content, tt.policy)
+		if err != nil {
+			t.Errorf("policy %d failed: %s", tt.policy, err)
+			continue
+		}
+		if !reflect.DeepEqual(fields, tt.want) {
+			t.Errorf("policy %d want %v got %v", tt.policy, tt.want, fields)
+		}
+	}
+
+	_, err := mergeDocuments.SearchMerge(content, docparser.MergeError)
+	if err == nil || err.Error() != `Document 2: Merge conflict for "name"` {
+		t.Errorf("invalid error: %v", err)
+	}
+	var conflict *docparser.MergeConflict
+	if !errors.As(err, &conflict) || conflict.Key != "name" {
+		t.Errorf("want MergeConflict for name got %#v", err)
+	}
+	if _, err := mergeDocuments.SearchMerge("Name: Mark\nBuyer: Mark\n", docparser.MergeError); err != nil {
+		t.Errorf("same values should not conflict: %s", err)
+	}
+}
+
+func TestDocumentsSearchMergeNoMatch(t *testing.T) {
+	_, err := mergeDocuments.SearchMerge("nothing", docparser.MergeOverwrite)
+	if _, ok := err.(*docparser.ErrorList); !ok {
+		t.Fatalf("want ErrorList got %#v", err)
+	}
+	if err.Error() != `Document 0: No match for ""; Document 1: No match for ""; Document 2: No match for ""` {
+		t.Errorf("invalid error: %s", err)
+	}
+}
+
+func ExampleMergeWithProvenance() {
+	fields, sources, err := docparser.MergeWithProvenance(docparser.MergeKeepFirst,
+		docparser.NamedFields{Name: "text", Fields: docparser.Fields{"name": "Mark"}},
+		docparser.NamedFields{Name: "html", Fields: docparser.Fields{"name": "Mark S.", "phone": "221-1122"}},
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(fields.GetString("name"), sources["name"])
+	fmt.Println(fields.GetString("phone"), sources["phone"])
+	// Output:
+	// Mark text
+	// 221-1122 html
+}
+
+func TestMergeWithProvenance(t *testing.T) {
+	inputs := []docparser.NamedFields{
+		{Name: "a", Fields: docparser.Fields{"name": "Mark", "city": "Kailua"}},
+		{Name: "b", Fields: docparser.Fields{"name": "Bob", "city": "Kailua", "zip": "96734"}},
+	}
+	var tests = []struct {
+		policy  docparser.MergePolicy
+		fields  docparser.Fields
+		sources map[string]string
+	}{
+		{
+			docparser.MergeOverwrite,
+			docparser.Fields{"name": "Bob", "city": "Kailua", "zip": "96734"},
+			map[string]string{"name": "b", "city": "a", "zip": "b"},
+		},
+		{
+			docparser.MergeKeepFirst,
+			docparser.Fields{"name": "Mark", "city": "Kailua", "zip": "96734"},
+			map[string]string{"name": "a", "city": "a", "zip": "b"},
+		},
+	}
+	for _, tt := range tests {
+		fields, sources, err := docparser.MergeWithProvenance(tt.policy, inputs...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(fields, tt.fields) || !reflect.DeepEqual(sources, tt.sources) {
+			t.Errorf("policy %d: want %v %v got %v %v", tt.policy, tt.fields, tt.sources, fields, sources)
+		}
+	}
+
+	_, _, err := docparser.MergeWithProvenance(docparser.MergeError, inputs...)
+	if err == nil || err.Error() != `b: Merge conflict for "name"` {
+		t.Errorf("invalid error %v", err)
+	}
+	var conflict *docparser.MergeConflict
+	if !errors.As(err, &conflict) || conflict.Key != "name" {
+		t.Errorf("want MergeConflict for name got %#v", err)
+	}
+}
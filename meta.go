@@ -0,0 +1,47 @@
+package docparser
+
+import "fmt"
+
+// Meta describes how a match result was produced, i.e. for auditing,
+// without adding keys to the returned Fields
+type Meta struct {
+	// Document is the Name of the Document that matched
+	Document string
+
+	// Index is the position of the Document that matched in Documents,
+	// always 0 when searching a single Document
+	Index int
+
+	// Patterns reports, for each Pattern of the Document in order,
+	// whether it returned any fields. Optional Patterns that didn't
+	// match return none
+	Patterns []bool
+}
+
+// SearchWithMeta is like Search but also returns the Meta of the match.
+// The Meta is only meaningful when the error is nil
+func (d *Document) SearchWithMeta(content string) (Fields, Meta, error) {
+	meta := Meta{Document: d.Name}
+	fields, err := d.search(content, func(p Pattern, f Fields) {
+		meta.Patterns = append(meta.Patterns, len(f) > 0)
+	})
+	if err != nil {
+		return Fields{}, Meta{}, err
+	}
+	return fields, meta, nil
+}
+
+// SearchWithMeta is like Search but also returns the Meta of the first
+// Document that matched
+func (ds *Documents) SearchWithMeta(content string) (Fields, Meta, error) {
+	errList := &ErrorList{}
+	for i, doc := range *ds {
+		fields, meta, err := doc.SearchWithMeta(content)
+		if err == nil {
+			meta.Index = i
+			return fields, meta, nil
+		}
+		errList.Add(fmt.Errorf("Document %d: %s", i, err.Error()))
+	}
+	return Fields{}, Meta{}, errList
+}
@@ -0,0 +1,67 @@
+package docparser_test
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+func ExampleDocuments_SearchWithMeta() {
+	documents := docparser.Documents{
+		{
+			Name: "Zillow",
+			Patterns: []docparser.Pattern{
+				&docparser.PatternGroup{Regex: regexp.MustCompile(`Zillow lead: (?P<name>.*)`)},
+			},
+		},
+		{
+			Name: "Trulia",
+			Patterns: []docparser.Pattern{
+				&docparser.PatternGroup{Regex: regexp.MustCompile(`Trulia lead: (?P<name>.*)`)},
+				&docparser.PatternGroup{Regex: regexp.MustCompile(`Phone: (?P<phone>.*)`), Optional: true},
+			},
+		},
+	}
+
+	fields, meta, err := documents.SearchWithMeta("Trulia lead: Bob")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(fields)
+	fmt.Println(meta.Document, meta.Index, meta.Patterns)
+	// Output:
+	// map[name:Bob]
+	// Trulia 1 [true false]
+}
+
+func TestDocumentSearchWithMeta(t *testing.T) {
+	document := testDocuments[0]
+	content := "Name: bob\nEmail: bob@site.com\n"
+	fields, meta, err := document.SearchWithMeta(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := document.Search(content); !reflect.DeepEqual(fields, want) {
+		t.Errorf("want %v got %v", want, fields)
+	}
+	want := docparser.Meta{Document: document.Name, Patterns: []bool{true, true}}
+	if !reflect.DeepEqual(meta, want) {
+		t.Errorf("want %#v got %#v", want, meta)
+	}
+
+	_, meta, err = document.SearchWithMeta("nothing")
+	if err == nil || !reflect.DeepEqual(meta, docparser.Meta{}) {
+		t.Errorf("want error and empty meta, got %v %#v", err, meta)
+	}
+}
+
+func TestDocumentsSearchWithMetaNoMatch(t *testing.T) {
+	_, _, err := testDocuments.SearchWithMeta("nothing")
+	if _, ok := err.(*docparser.ErrorList); !ok {
+		t.Errorf("want ErrorList got %#v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package docparser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// The Must getters panic instead of returning a default value, for
+// tools and tests where a missing field is a programming error. Use the
+// regular getters for production input
+
+// MustGetString is like GetString but panics if key is not present or
+// its value is not a string
+func (f *Fields) MustGetString(key string) string {
+	v, ok := (*f)[key]
+	if !ok {
+		panic(fmt.Sprintf("docparser: field %q not present", key))
+	}
+	s, ok := v.(string)
+	if !ok {
+		panic(fmt.Sprintf("docparser: field %q is a %T, not a string", key, v))
+	}
+	return s
+}
+
+// MustGetInt is like GetIntOr but panics if key is not present or its
+// value is not a string holding an integer
+func (f *Fields) MustGetInt(key string) int {
+	s := f.MustGetString(key)
+	i, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		panic(fmt.Sprintf("docparser: field %q is not an integer: %q", key, s))
+	}
+	return i
+}
+
+// MustGetBool is like GetBoolOr but panics if key is not present or its
+// value is not a string holding a boolean
+func (f *Fields) MustGetBool(key string) bool {
+	s := f.MustGetString(key)
+	b, err := strconv.ParseBool(strings.TrimSpace(s))
+	if err != nil {
+		panic(fmt.Sprintf("docparser: field %q is not a boolean: %q", key, s))
+	}
+	return b
+}
+
+// MustGetMapSlice is like GetMapSlice but panics if key is not present
+// or its value is not a slice of Fields
+func (f *Fields) MustGetMapSlice(key string) []map[string]string {
+	v, ok := (*f)[key]
+	if !ok {
+		panic(fmt.Sprintf("docparser: field %q not present", key))
+	}
+	if _, ok := v.([]Fields); !ok {
+		panic(fmt.Sprintf("docparser: field %q is a %T, not a []Fields", key, v))
+	}
+	return f.GetMapSlice(key)
+}
+
+// MustGetCents is like GetCents but panics on error
+func (f *Fields) MustGetCents(key string) int64 {
+	if !f.Has(key) {
+		panic(fmt.Sprintf("docparser: field %q not present", key))
+	}
+	cents, err := f.GetCents(key)
+	if err != nil {
+		panic(fmt.Sprintf("docparser: field %q is not an amount: %v", key, err))
+	}
+	return cents
+}
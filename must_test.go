@@ -0,0 +1,60 @@
+package docparser_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+func TestFieldsMust(t *testing.T) {
+	f := docparser.Fields{
+		"name":       "Mark",
+		"beds":       " 3 ",
+		"garage":     "true",
+		"price":      "$450,000.00",
+		"properties": []docparser.Fields{{"mls": "2211"}},
+	}
+	if v := f.MustGetString("name"); v != "Mark" {
+		t.Errorf("MustGetString want Mark got %q", v)
+	}
+	if v := f.MustGetInt("beds"); v != 3 {
+		t.Errorf("MustGetInt want 3 got %d", v)
+	}
+	if v := f.MustGetBool("garage"); !v {
+		t.Errorf("MustGetBool want true got %v", v)
+	}
+	if v := f.MustGetCents("price"); v != 45000000 {
+		t.Errorf("MustGetCents want 45000000 got %d", v)
+	}
+	if v := f.MustGetMapSlice("properties"); !reflect.DeepEqual(v, []map[string]string{{"mls": "2211"}}) {
+		t.Errorf("MustGetMapSlice invalid %v", v)
+	}
+}
+
+func TestFieldsMustPanics(t *testing.T) {
+	f := docparser.Fields{"name": "Mark", "tags": []string{"hot"}}
+	var tests = []struct {
+		get  func()
+		want string
+	}{
+		{func() { f.MustGetString("email") }, `docparser: field "email" not present`},
+		{func() { f.MustGetString("tags") }, `docparser: field "tags" is a []string, not a string`},
+		{func() { f.MustGetInt("name") }, `docparser: field "name" is not an integer: "Mark"`},
+		{func() { f.MustGetBool("name") }, `docparser: field "name" is not a boolean: "Mark"`},
+		{func() { f.MustGetMapSlice("name") }, `docparser: field "name" is a string, not a []Fields`},
+		{func() { f.MustGetMapSlice("properties") }, `docparser: field "properties" not present`},
+		{func() { f.MustGetCents("price") }, `docparser: field "price" not present`},
+	}
+	for _, tt := range tests {
+		func() {
+			defer func() {
+				if r := recover(); fmt.Sprint(r) != tt.want {
+					t.Errorf("want panic %q got %v", tt.want, r)
+				}
+			}()
+			tt.get()
+		}()
+	}
+}
@@ -0,0 +1,119 @@
+package docparser
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// PatternOutline is a Pattern implementation for outlines, numbered or
+// bulleted lists whose items nest, like:
+//
+//	Checklist
+//	1. Property
+//	  1.1 Location
+//	    a. Kailua
+//	2. Contact
+//	  - phone
+//	  - email
+//
+// Items are returned as a tree of Fields, each item with its children
+// under ChildrenKey
+type PatternOutline struct {
+	Name string
+
+	// ItemRegex matches an outline item in a line, without its
+	// indentation. The group "marker" captures the item number or
+	// bullet, like "1.1" or "-", and all named groups are stored in
+	// the item Fields. Lines that don't match are ignored
+	ItemRegex *regexp.Regexp
+
+	// OutputKey is the field that holds the []Fields of the top level
+	// items
+	OutputKey string
+
+	// ChildrenKey is the field of each item that holds the []Fields of
+	// its children, defaults to "children"
+	ChildrenKey string
+
+	// Optional returns empty Fields instead of NoMatch if there are no
+	// items
+	Optional bool
+}
+
+// outlineItem is an item while building the tree, with its depth
+type outlineItem struct {
+	fields         Fields
+	marker, indent int
+}
+
+// Search content for outline items, line by line, and nest them
+//
+// The depth of an item is given first by its indentation, and then by
+// its marker, the number of dot separated parts of a numbered marker
+// ("1.1.a" is 3), so both indented bullets and numbered outlines nest.
+// An item is a child of the closest previous item that is shallower.
+// Return NoMatch error if there are no items
+func (po *PatternOutline) Search(content string) (Fields, error) {
+	childrenKey := po.ChildrenKey
+	if childrenKey == "" {
+		childrenKey = "children"
+	}
+	var roots []Fields
+	var stack []outlineItem
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(line, "\r")
+		text := strings.TrimLeftFunc(line, unicode.IsSpace)
+		f, ok := regexGroups(po.ItemRegex, text, false)
+		if !ok {
+			continue
+		}
+		f[childrenKey] = []Fields{}
+		item := outlineItem{f, markerDepth(f.GetString("marker")), len(line) - len(text)}
+
+		for len(stack) > 0 && !stack[len(stack)-1].shallowerThan(item) {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			roots = append(roots, f)
+		} else {
+			parent := stack[len(stack)-1].fields
+			parent[childrenKey] = append(parent[childrenKey].([]Fields), f)
+		}
+		stack = append(stack, item)
+	}
+	if len(roots) == 0 {
+		if po.Optional {
+			return Fields{}, nil
+		}
+		return Fields{}, NewNoMatch(po.Name, content)
+	}
+	return Fields{po.OutputKey: roots}, nil
+}
+
+func (item outlineItem) shallowerThan(other outlineItem) bool {
+	if item.indent != other.indent {
+		return item.indent < other.indent
+	}
+	return item.marker < other.marker
+}
+
+// markerDepth returns the number of dot separated parts of a numbered
+// marker, or 1 for bullets
+func markerDepth(marker string) int {
+	depth := 0
+	for _, part := range strings.Split(marker, ".") {
+		if strings.IndexFunc(part, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) != -1 {
+			depth++
+		}
+	}
+	if depth == 0 {
+		return 1
+	}
+	return depth
+}
+
+// FieldNames returns OutputKey
+func (po *PatternOutline) FieldNames() []string {
+	return []string{po.OutputKey}
+}
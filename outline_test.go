@@ -0,0 +1,129 @@
+package docparser_test
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+func ExamplePatternOutline() {
+	pattern := &docparser.PatternOutline{
+		Name:      "Checklist",
+		ItemRegex: regexp.MustCompile(`^(?P<marker>[\d.a-z]+\.?|[-*]) (?P<text>.*)`),
+		OutputKey: "items",
+	}
+
+	content := `Closing checklist
+1. Inspection
+1.1 Termites
+1.1.a Garage
+1.2 Roof
+2. Appraisal
+  - order
+  - review
+`
+
+	fields, err := pattern.Search(content)
+	if err != nil {
+		panic(err)
+	}
+
+	var print func(items []docparser.Fields, depth int)
+	print = func(items []docparser.Fields, depth int) {
+		for _, item := range items {
+			fmt.Printf("%s%s\n", strings.Repeat("  ", depth), item.GetString("text"))
+			print(item["children"].([]docparser.Fields), depth+1)
+		}
+	}
+	print(fields["items"].([]docparser.Fields), 0)
+	// Output:
+	// Inspection
+	//   Termites
+	//     Garage
+	//   Roof
+	// Appraisal
+	//   order
+	//   review
+}
+
+func TestPatternOutlineIndentation(t *testing.T) {
+	pattern := &docparser.PatternOutline{
+		ItemRegex:   regexp.MustCompile(`^- (?P<text>.*)`),
+		OutputKey:   "items",
+		ChildrenKey: "sub",
+	}
+	content := "- a\r\n    - a1\r\n        - a1x\r\n    - a2\r\nnot an item\r\n- b\r\n"
+	fields, err := pattern.Search(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	item := func(text string, children ...docparser.Fields) docparser.Fields {
+		return docparser.Fields{"text": text, "sub": append([]docparser.Fields{}, children...)}
+	}
+	want := []docparser.Fields{
+		item("a", item("a1", item("a1x")), item("a2")),
+		item("b"),
+	}
+	if items := fields["items"]; !reflect.DeepEqual(items, want) {
+		t.Errorf("want %v got %v", want, items)
+	}
+}
+
+func TestPatternOutlineMixed(t *testing.T) {
+	pattern := &docparser.PatternOutline{
+		ItemRegex: regexp.MustCompile(`^(?P<marker>[\d.a-z]+\.?|[-*]) (?P<text>.*)`),
+		OutputKey: "items",
+	}
+	content := "1. Property\n  1.1 Location\n    a. Kailua\n  1.2 Size\n2. Contact\n"
+	fields, err := pattern.Search(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	item := func(marker, text string, children ...docparser.Fields) docparser.Fields {
+		return docparser.Fields{"marker": marker, "text": text, "children": append([]docparser.Fields{}, children...)}
+	}
+	want := []docparser.Fields{
+		item("1.", "Property", item("1.1", "Location", item("a.", "Kailua")), item("1.2", "Size")),
+		item("2.", "Contact"),
+	}
+	if items := fields["items"]; !reflect.DeepEqual(items, want) {
+		t.Errorf("want %v got %v", want, items)
+	}
+}
+
+func TestPatternOutlineNoMatch(t *testing.T) {
+	pattern := &docparser.PatternOutline{
+		Name:      "Outline",
+		ItemRegex: regexp.MustCompile(`^(?P<marker>\d+\.) (?P<text>.*)`),
+		OutputKey: "items",
+	}
+	if _, err := pattern.Search("no items"); err == nil || err.Error() != `No match for "Outline"` {
+		t.Errorf("want NoMatch got %v", err)
+	}
+	pattern.Optional = true
+	if fields, err := pattern.Search("no items"); err != nil || len(fields) != 0 {
+		t.Errorf("want empty fields got %v %v", fields, err)
+	}
+}
+
+func TestPatternOutlineGetMapSlice(t *testing.T) {
+	pattern := &docparser.PatternOutline{
+		ItemRegex: regexp.MustCompile(`^(?P<marker>[\d.]+\.?) (?P<text>.*)`),
+		OutputKey: "items",
+	}
+	fields, err := pattern.Search("1. a\n1.1 b\n2. c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]string{
+		{"marker": "1.", "text": "a"},
+		{"marker": "2.", "text": "c"},
+	}
+	if items := fields.GetMapSlice("items"); !reflect.DeepEqual(items, want) {
+		t.Errorf("want %v got %v", want, items)
+	}
+}
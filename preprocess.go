@@ -0,0 +1,152 @@
+package docparser
+
+import (
+	"fmt"
+	"io"
+	"mime/quotedprintable"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var lineEndings = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// NormalizeLineEndings is a Preprocessor that converts Windows (\r\n)
+// and old Mac (\r) line endings to \n
+//
+// Regexes are usually written for \n, which makes anchored patterns
+// and SplitRegex behave subtly wrong with other line endings, like
+// capturing a trailing \r. It's recommended as the first Preprocessor
+// of every Document that parses emails
+func NormalizeLineEndings(content string) (string, error) {
+	return lineEndings.Replace(content), nil
+}
+
+// QuotedPrintableDecode is a Preprocessor that decodes quoted-printable
+// content, as found in the raw body of forwarded emails
+//
+// Soft line breaks (= at the end of a line) are removed and =XX hex
+// escapes decoded, so "Name: Mark=20=\nStewart" becomes "Name: Mark
+// Stewart". Malformed escapes, like a = not followed by two hex digits,
+// are kept as they are
+func QuotedPrintableDecode(content string) (string, error) {
+	decoded, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(content)))
+	if err != nil {
+		return "", fmt.Errorf("failed to decode quoted-printable: %v", err)
+	}
+	return string(decoded), nil
+}
+
+// CollapseWrappedLines is a Preprocessor that joins lines an email
+// client wrapped in the middle of a sentence, so values split across
+// lines like "call me after\nlunch" can be matched on one line
+//
+// It's conservative: a line is joined with the next one, with a space
+// in between, only if it doesn't end in sentence punctuation (.!?:;)
+// and the next line starts with a lowercase letter. Empty lines are
+// never joined. See CollapseWrappedLinesWith for other heuristics
+func CollapseWrappedLines(content string) (string, error) {
+	return CollapseWrappedLinesWith(WrapOptions{})(content)
+}
+
+// WrapOptions change which lines CollapseWrappedLinesWith joins
+type WrapOptions struct {
+	// Paragraphs joins all the lines not separated by an empty line,
+	// regardless of punctuation and case, so "123 Main\nStreet" is
+	// joined too. Only use it for documents without one field per line
+	Paragraphs bool
+
+	// EndPunctuation are the characters that end a sentence, a line
+	// ending in one is not joined unless Paragraphs is set. Defaults to
+	// ".!?:;"
+	EndPunctuation string
+}
+
+// CollapseWrappedLinesWith returns a Preprocessor like
+// CollapseWrappedLines with options
+func CollapseWrappedLinesWith(opts WrapOptions) Preprocessor {
+	punctuation := opts.EndPunctuation
+	if punctuation == "" {
+		punctuation = ".!?:;"
+	}
+	wrapped := func(line, next string) bool {
+		line, next = strings.TrimSpace(line), strings.TrimSpace(next)
+		if line == "" || next == "" {
+			return false
+		}
+		if opts.Paragraphs {
+			return true
+		}
+		last, _ := utf8.DecodeLastRuneInString(line)
+		first, _ := utf8.DecodeRuneInString(next)
+		return !strings.ContainsRune(punctuation, last) && unicode.IsLower(first)
+	}
+	return func(content string) (string, error) {
+		lines := strings.Split(content, "\n")
+		var b strings.Builder
+		for i, line := range lines {
+			if i+1 < len(lines) && wrapped(line, lines[i+1]) {
+				b.WriteString(strings.TrimRight(line, " \t\r"))
+				b.WriteByte(' ')
+				lines[i+1] = strings.TrimLeft(lines[i+1], " \t")
+				continue
+			}
+			b.WriteString(line)
+			if i+1 < len(lines) {
+				b.WriteByte('\n')
+			}
+		}
+		return b.String(), nil
+	}
+}
+
+// BoxBorders are the characters StripBoxBorders removes
+const BoxBorders = "|+-="
+
+// StripBoxBorders is a Preprocessor that removes the borders of boxes
+// drawn with BoxBorders characters around the text, as sent by some
+// legacy systems, so the key/values inside can be matched as usual
+//
+// Lines made only of border characters and spaces, at least 3 of them,
+// like "+------+", are removed. Lines that start and end with a border
+// character, like "| Name: Mark |", lose that first and last border
+// character and the spaces padding the text, so border characters that
+// are part of the text, like "| Rating: A+ |", are kept. Borders inside
+// a line, i.e. between columns, and all other lines are kept. See
+// StripBoxBordersWith for other border characters
+func StripBoxBorders(content string) (string, error) {
+	return StripBoxBordersWith(BoxBorders)(content)
+}
+
+// StripBoxBordersWith returns a Preprocessor like StripBoxBorders that
+// removes borders drawn with the characters in borders, i.e. "│─┌┐└┘"
+// for Unicode box drawings
+func StripBoxBordersWith(borders string) Preprocessor {
+	isBorder := func(r rune) bool { return strings.ContainsRune(borders, r) }
+	isBorderOrSpace := func(r rune) bool { return isBorder(r) || unicode.IsSpace(r) }
+	return func(content string) (string, error) {
+		lines := strings.SplitAfter(content, "\n")
+		var b strings.Builder
+		for _, line := range lines {
+			text := strings.TrimRight(line, "\r\n")
+			eol := line[len(text):]
+			trimmed := strings.TrimSpace(text)
+			switch {
+			case trimmed == "":
+			case strings.TrimFunc(trimmed, isBorderOrSpace) == "":
+				if utf8.RuneCountInString(strings.Join(strings.Fields(trimmed), "")) >= 3 {
+					continue
+				}
+			default:
+				first, _ := utf8.DecodeRuneInString(trimmed)
+				last, _ := utf8.DecodeLastRuneInString(trimmed)
+				if isBorder(first) && isBorder(last) {
+					text = strings.TrimSpace(trimmed[utf8.RuneLen(first) : len(trimmed)-utf8.RuneLen(last)])
+				}
+			}
+			b.WriteString(text)
+			b.WriteString(eol)
+		}
+		return b.String(), nil
+	}
+}
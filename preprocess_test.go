@@ -0,0 +1,213 @@
+package docparser_test
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+func ExampleNormalizeLineEndings() {
+	document := &docparser.Document{
+		Preprocess: []docparser.Preprocessor{docparser.NormalizeLineEndings},
+		Patterns: []docparser.Pattern{
+			&docparser.PatternGroup{
+				Name:  "Name",
+				Regex: regexp.MustCompile(`(?m:^Name: (?P<name>.*)$)`),
+			},
+		},
+	}
+
+	fields, err := document.Search("Lead\r\nName: Mark\r\nPhone: 221-1122\r\n")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("%q\n", fields.GetString("name"))
+	// Output:
+	// "Mark"
+}
+
+func TestNormalizeLineEndings(t *testing.T) {
+	var tests = []struct {
+		in, out string
+	}{
+		{"a\nb\n", "a\nb\n"},
+		{"a\r\nb\r\n", "a\nb\n"},
+		{"a\rb\r", "a\nb\n"},
+		{"a\r\n\r\nb\r\r\n", "a\n\nb\n\n"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		out, err := docparser.NormalizeLineEndings(tt.in)
+		if err != nil || out != tt.out {
+			t.Errorf("%q want %q got %q (%v)", tt.in, tt.out, out, err)
+		}
+	}
+}
+
+func ExampleQuotedPrintableDecode() {
+	document := &docparser.Document{
+		Preprocess: []docparser.Preprocessor{docparser.QuotedPrintableDecode},
+		Patterns: []docparser.Pattern{
+			&docparser.PatternGroup{
+				Name:  "Name",
+				Regex: regexp.MustCompile(`(?m:^Name: (?P<name>.*)$)`),
+			},
+		},
+	}
+
+	fields, err := document.Search("Lead\nName: Mark=20=\nStewart=3D\n")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("%q\n", fields.GetString("name"))
+	// Output:
+	// "Mark Stewart="
+}
+
+func TestQuotedPrintableDecode(t *testing.T) {
+	var tests = []struct {
+		in, out string
+	}{
+		{"plain text\n", "plain text\n"},
+		{"soft=\nbreak\n", "softbreak\n"},
+		{"soft=\r\nbreak\r\n", "softbreak\r\n"},
+		{"caf=C3=A9 =3D=20\n", "caf\u00e9 = \n"},
+		{"bad =ZZ escape", "bad =ZZ escape"},
+		{"end=", "end"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		out, err := docparser.QuotedPrintableDecode(tt.in)
+		if err != nil || out != tt.out {
+			t.Errorf("%q want %q got %q (%v)", tt.in, tt.out, out, err)
+		}
+	}
+}
+
+func TestDocumentsPreprocess(t *testing.T) {
+	callOrder := []string{}
+	trace := func(s string) docparser.Preprocessor {
+		return func(content string) (string, error) {
+			callOrder = append(callOrder, s)
+			return content, nil
+		}
+	}
+	documents := docparser.Documents{
+		{
+			Preprocess: []docparser.Preprocessor{trace("doc")},
+			Patterns:   []docparser.Pattern{&docparser.PatternGroup{Regex: regexp.MustCompile(`Name: (?P<name>.*)`)}},
+		},
+	}
+	documents.Preprocess(docparser.QuotedPrintableDecode, trace("shared"))
+
+	fields, err := documents.Search("Name: Mark=20Stewart")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fields.GetString("name") != "Mark Stewart" {
+		t.Errorf("content not decoded: %v", fields)
+	}
+	if want := []string{"shared", "doc"}; !reflect.DeepEqual(callOrder, want) {
+		t.Errorf("want %v got %v", want, callOrder)
+	}
+}
+
+func ExampleCollapseWrappedLines() {
+	document := &docparser.Document{
+		Preprocess: []docparser.Preprocessor{docparser.CollapseWrappedLines},
+		Patterns: []docparser.Pattern{
+			&docparser.PatternGroup{
+				Name:  "Comments",
+				Regex: regexp.MustCompile(`(?m:^Comments: (?P<comments>.*)$)`),
+			},
+		},
+	}
+
+	fields, err := document.Search("Comments: I'd like to see the house\non Sunday morning.\nPhone: 221-1122\n")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(fields.GetString("comments"))
+	// Output:
+	// I'd like to see the house on Sunday morning.
+}
+
+func TestCollapseWrappedLines(t *testing.T) {
+	var tests = []struct {
+		opts    docparser.WrapOptions
+		in, out string
+	}{
+		{docparser.WrapOptions{}, "a long\nline\n", "a long line\n"},
+		{docparser.WrapOptions{}, "a long  \n  line\nB\n", "a long line\nB\n"},
+		{docparser.WrapOptions{}, "Name: Mark\nPhone: 1\n", "Name: Mark\nPhone: 1\n"},
+		{docparser.WrapOptions{}, "end.\nnext\n", "end.\nnext\n"},
+		{docparser.WrapOptions{}, "one\n\ntwo\n", "one\n\ntwo\n"},
+		{docparser.WrapOptions{}, "a\r\nb\r\nc\r\n", "a b c\r\n"},
+		{docparser.WrapOptions{}, "a,\nb", "a, b"},
+		{docparser.WrapOptions{EndPunctuation: ","}, "a,\nb", "a,\nb"},
+		{docparser.WrapOptions{Paragraphs: true}, "123 Main\nStreet.\nApt 1\n\nnext\n", "123 Main Street. Apt 1\n\nnext\n"},
+		{docparser.WrapOptions{}, "", ""},
+	}
+	for _, tt := range tests {
+		out, err := docparser.CollapseWrappedLinesWith(tt.opts)(tt.in)
+		if err != nil || out != tt.out {
+			t.Errorf("%+v %q want %q got %q (%v)", tt.opts, tt.in, tt.out, out, err)
+		}
+	}
+}
+
+func ExampleStripBoxBorders() {
+	document := &docparser.Document{
+		Preprocess: []docparser.Preprocessor{docparser.StripBoxBorders},
+		Patterns: []docparser.Pattern{
+			&docparser.PatternGroup{
+				Name:  "Contact",
+				Regex: regexp.MustCompile(`(?m:^Name: (?P<name>.*)\nPhone: (?P<phone>.*)$)`),
+			},
+		},
+	}
+
+	content := `+-------------------+
+| Name: Mark        |
+| Phone: 221-1122   |
++===================+
+`
+
+	fields, err := document.Search(content)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("%q %q\n", fields.GetString("name"), fields.GetString("phone"))
+	// Output:
+	// "Mark" "221-1122"
+}
+
+func TestStripBoxBorders(t *testing.T) {
+	var tests = []struct {
+		borders string
+		in, out string
+	}{
+		{docparser.BoxBorders, "+---+\n| a |\n+---+\n", "a\n"},
+		{docparser.BoxBorders, "||  a | b  ||\r\n", "|  a | b  |\r\n"},
+		{docparser.BoxBorders, "| - a - |\n", "- a -\n"},
+		{docparser.BoxBorders, "| Rating: A+ |\n| Score: 5- |\n", "Rating: A+\nScore: 5-\n"},
+		{docparser.BoxBorders, "- item\n--\n- - -\n", "- item\n--\n"},
+		{docparser.BoxBorders, "| open\nline\n", "| open\nline\n"},
+		{docparser.BoxBorders, "a\n\nb", "a\n\nb"},
+		{"\u2502\u2500\u250c\u2510\u2514\u2518", "\u250c\u2500\u2500\u2500\u2510\n\u2502 a \u2502\n\u2514\u2500\u2500\u2500\u2518\n", "a\n"},
+		{docparser.BoxBorders, "", ""},
+	}
+	for _, tt := range tests {
+		out, err := docparser.StripBoxBordersWith(tt.borders)(tt.in)
+		if err != nil || out != tt.out {
+			t.Errorf("%q %q want %q got %q (%v)", tt.borders, tt.in, tt.out, out, err)
+		}
+	}
+}
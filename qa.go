@@ -0,0 +1,113 @@
+package docparser
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// QuestionRegex and AnswerRegex are the default regexes of
+	// PatternQA, for lines like "Q: Budget?" and "A: 500k"
+	QuestionRegex = regexp.MustCompile(`(?i)^\s*Q(?:uestion)?\s*[:.]\s*(.*)$`)
+	AnswerRegex   = regexp.MustCompile(`(?i)^\s*A(?:nswer)?\s*[:.]\s*(.*)$`)
+)
+
+// PatternQA is a Pattern implementation that extracts the question and
+// answer pairs of forms, like:
+//
+//	Q: How can we help?
+//	A: I want to sell my house
+//
+//	Q: Budget?
+//	A: 500k
+//
+// into a []Fields with the keys "question" and "answer"
+type PatternQA struct {
+	Name string
+
+	// QuestionRegex and AnswerRegex match the first line of a question
+	// and an answer, the first group capturing its text, or the text
+	// after the match if there's no group. Default to QuestionRegex and
+	// AnswerRegex
+	QuestionRegex, AnswerRegex *regexp.Regexp
+
+	// PairSplit matches the lines that end an answer, besides the next
+	// question. Defaults to blank lines
+	PairSplit *regexp.Regexp
+
+	// OutputKey is the field that holds the []Fields of all pairs
+	OutputKey string
+
+	Optional bool
+}
+
+var blankLine = regexp.MustCompile(`^\s*$`)
+
+// Search for the question and answer pairs in content, in order
+//
+// Answers can span several lines, up to the next question or a line
+// matching PairSplit, and are joined with "\n". A question without an
+// answer gets an empty one, and answers without a question are
+// ignored. Return NoMatch error if no question is found
+func (pq *PatternQA) Search(content string) (Fields, error) {
+	question, answer, split := pq.QuestionRegex, pq.AnswerRegex, pq.PairSplit
+	if question == nil {
+		question = QuestionRegex
+	}
+	if answer == nil {
+		answer = AnswerRegex
+	}
+	if split == nil {
+		split = blankLine
+	}
+
+	pairs := []Fields{}
+	var current Fields
+	var answerLines []string
+	inAnswer := false
+	flush := func() {
+		if current != nil {
+			current["answer"] = strings.TrimSpace(strings.Join(answerLines, "\n"))
+			pairs = append(pairs, current)
+		}
+		current, answerLines, inAnswer = nil, nil, false
+	}
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		switch {
+		case question.MatchString(line):
+			flush()
+			current = Fields{"question": lineText(question, line)}
+		case current != nil && !inAnswer && answer.MatchString(line):
+			answerLines, inAnswer = []string{lineText(answer, line)}, true
+		case inAnswer && split.MatchString(line):
+			flush()
+		case inAnswer:
+			answerLines = append(answerLines, strings.TrimSpace(line))
+		}
+	}
+	flush()
+
+	if len(pairs) == 0 {
+		if pq.Optional {
+			return Fields{}, nil
+		}
+		return Fields{}, NewNoMatch(pq.Name, content)
+	}
+	return Fields{pq.OutputKey: pairs}, nil
+}
+
+// FieldNames returns OutputKey
+func (pq *PatternQA) FieldNames() []string {
+	return []string{pq.OutputKey}
+}
+
+// lineText returns the first group of re in line, or the text after the
+// match if re has no groups, trimmed
+func lineText(re *regexp.Regexp, line string) string {
+	loc := re.FindStringSubmatchIndex(line)
+	if len(loc) > 2 && loc[2] >= 0 {
+		return strings.TrimSpace(line[loc[2]:loc[3]])
+	}
+	return strings.TrimSpace(line[loc[1]:])
+}
@@ -0,0 +1,91 @@
+package docparser_test
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+func ExamplePatternQA() {
+	pattern := &docparser.PatternQA{Name: "Form", OutputKey: "answers"}
+
+	content := `Q: How can we help?
+A: I want to sell my house
+
+Q: Budget?
+A: 500k`
+
+	fields, err := pattern.Search(content)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, pair := range fields.GetMapSlice("answers") {
+		fmt.Printf("%s %s\n", pair["question"], pair["answer"])
+	}
+	// Output:
+	// How can we help? I want to sell my house
+	// Budget? 500k
+}
+
+func TestPatternQA(t *testing.T) {
+	content := "A: orphan answer\r\n" +
+		"Question: Tell us about your home\r\n" +
+		"Answer: 3 bedrooms,\r\n" +
+		"  big yard\r\n" +
+		"\r\n" +
+		"ignored text\r\n" +
+		"Q: Phone?\r\n" +
+		"Q. Timeline?\r\n" +
+		"a. Soon\r\n" +
+		"Q: When can we call?\r\n"
+
+	pattern := &docparser.PatternQA{OutputKey: "qa"}
+	fields, err := pattern.Search(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]string{
+		{"question": "Tell us about your home", "answer": "3 bedrooms,\nbig yard"},
+		{"question": "Phone?", "answer": ""},
+		{"question": "Timeline?", "answer": "Soon"},
+		{"question": "When can we call?", "answer": ""},
+	}
+	if pairs := fields.GetMapSlice("qa"); !reflect.DeepEqual(pairs, want) {
+		t.Errorf("want %v got %v", want, pairs)
+	}
+}
+
+func TestPatternQARegexes(t *testing.T) {
+	pattern := &docparser.PatternQA{
+		QuestionRegex: regexp.MustCompile(`^\d+\) `),
+		AnswerRegex:   regexp.MustCompile(`^> (?P<answer>.*)`),
+		PairSplit:     regexp.MustCompile(`^---`),
+		OutputKey:     "qa",
+	}
+	fields, err := pattern.Search("1) Name?\n> Mark\n\nStewart\n---\nfooter\n2) Email?\n> bob@site.com\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]string{
+		{"question": "Name?", "answer": "Mark\n\nStewart"},
+		{"question": "Email?", "answer": "bob@site.com"},
+	}
+	if pairs := fields.GetMapSlice("qa"); !reflect.DeepEqual(pairs, want) {
+		t.Errorf("want %v got %v", want, pairs)
+	}
+}
+
+func TestPatternQANoMatch(t *testing.T) {
+	pattern := &docparser.PatternQA{Name: "Form", OutputKey: "qa"}
+	if _, err := pattern.Search("A: answer only"); err == nil || err.Error() != `No match for "Form"` {
+		t.Errorf("want NoMatch got %v", err)
+	}
+	pattern.Optional = true
+	if fields, err := pattern.Search(""); err != nil || len(fields) != 0 {
+		t.Errorf("optional want empty fields got %v (%v)", fields, err)
+	}
+}
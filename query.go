@@ -0,0 +1,102 @@
+package docparser
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// PatternQuery is a Pattern implementation that extracts fields from a
+// URL-encoded query string blob found in the content, like
+// "data=name%3DBob%26phone%3D808"
+type PatternQuery struct {
+	Name string
+
+	// Regex where the group "query", or the first group if there's no
+	// group with that name, captures the blob. The blob is unescaped
+	// once with url.QueryUnescape and then split into parameters, so
+	// the values aren't decoded a second time, i.e. "%2B" gives "+"
+	Regex *regexp.Regexp
+
+	// Keys are the query parameters to extract. Empty means all
+	Keys []string
+
+	// Flatten stores parameters with a single value as a string.
+	// Otherwise all values are stored as []string
+	Flatten bool
+
+	Optional bool
+}
+
+// FieldNames returns Keys, or nil if all parameters are extracted
+func (pq *PatternQuery) FieldNames() []string {
+	return pq.Keys
+}
+
+// Search for the query string blob in content and return its parameters
+// as Fields
+//
+// Return NoMatch error if Regex doesn't match, and an error if Regex
+// has no group or the blob can't be unescaped
+func (pq *PatternQuery) Search(content string) (Fields, error) {
+	group := pq.Regex.SubexpIndex("query")
+	if group == -1 {
+		group = 1
+	}
+	if pq.Regex.NumSubexp() < group {
+		return Fields{}, fmt.Errorf("no group in regex for %s", pq.Name)
+	}
+	matches := pq.Regex.FindStringSubmatch(content)
+	if matches == nil {
+		if pq.Optional {
+			return Fields{}, nil
+		}
+		return Fields{}, NewNoMatch(pq.Name, content)
+	}
+
+	blob, err := url.QueryUnescape(matches[group])
+	if err != nil {
+		return Fields{}, fmt.Errorf("failed to unescape query for %s: %v", pq.Name, err)
+	}
+	values := splitQuery(blob)
+
+	keys := pq.Keys
+	if len(keys) == 0 {
+		keys = make([]string, 0, len(values))
+		for k := range values {
+			keys = append(keys, k)
+		}
+	}
+
+	fields := Fields{}
+	for _, key := range keys {
+		v, ok := values[key]
+		if !ok {
+			continue
+		}
+		if pq.Flatten && len(v) == 1 {
+			fields[key] = v[0]
+		} else {
+			fields[key] = v
+		}
+	}
+	return fields, nil
+}
+
+// splitQuery splits an already unescaped query string into its
+// parameters, like url.ParseQuery but without unescaping them again
+func splitQuery(query string) url.Values {
+	values := url.Values{}
+	for _, param := range strings.Split(query, "&") {
+		if param == "" {
+			continue
+		}
+		key, value := param, ""
+		if i := strings.IndexByte(param, '='); i != -1 {
+			key, value = param[:i], param[i+1:]
+		}
+		values[key] = append(values[key], value)
+	}
+	return values
+}
@@ -0,0 +1,102 @@
+package docparser_test
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+func ExamplePatternQuery() {
+	pattern := &docparser.PatternQuery{
+		Name:    "Tracking data",
+		Regex:   regexp.MustCompile(`data=(\S+)`),
+		Keys:    []string{"name", "phone"},
+		Flatten: true,
+	}
+
+	content := "Lead received\ndata=name%3DBob%20Smith%26phone%3D808-221-1122%26source%3Dweb\n"
+
+	fields, err := pattern.Search(content)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(fields.GetString("name"))
+	fmt.Println(fields.GetString("phone"))
+	fmt.Println(len(fields))
+	// Output:
+	// Bob Smith
+	// 808-221-1122
+	// 2
+}
+
+func TestPatternQuery(t *testing.T) {
+	var tests = []struct {
+		pattern *docparser.PatternQuery
+		content string
+		want    docparser.Fields
+	}{
+		{
+			pattern: &docparser.PatternQuery{Regex: regexp.MustCompile(`data=(\S+)`)},
+			content: "data=a%3D1%26b%3D2%26b%3D3",
+			want:    docparser.Fields{"a": []string{"1"}, "b": []string{"2", "3"}},
+		},
+		{
+			pattern: &docparser.PatternQuery{Regex: regexp.MustCompile(`data=(\S+)`), Flatten: true},
+			content: "data=a%3D1%26b%3D2%26b%3D3",
+			want:    docparser.Fields{"a": "1", "b": []string{"2", "3"}},
+		},
+		{
+			pattern: &docparser.PatternQuery{Regex: regexp.MustCompile(`data=(\S+)`), Keys: []string{"a", "c"}},
+			content: "data=a%3D1%26b%3D2",
+			want:    docparser.Fields{"a": []string{"1"}},
+		},
+		{
+			pattern: &docparser.PatternQuery{Regex: regexp.MustCompile(`data=(\S+)`), Flatten: true},
+			content: "data=email%3Dbob%2Bx%40site.com%26q%3D50%2525",
+			want:    docparser.Fields{"email": "bob+x@site.com", "q": "50%25"},
+		},
+		{
+			pattern: &docparser.PatternQuery{Regex: regexp.MustCompile(`(id)=\d+ data=(?P<query>\S+)`), Flatten: true},
+			content: "id=1 data=a%3D1",
+			want:    docparser.Fields{"a": "1"},
+		},
+		{
+			pattern: &docparser.PatternQuery{Regex: regexp.MustCompile(`data=(\S+)`), Optional: true},
+			content: "nothing here",
+			want:    docparser.Fields{},
+		},
+	}
+	for _, tt := range tests {
+		fields, err := tt.pattern.Search(tt.content)
+		if err != nil {
+			t.Errorf("content %q failed: %s", tt.content, err)
+			continue
+		}
+		if !reflect.DeepEqual(fields, tt.want) {
+			t.Errorf("content %q want %v got %v", tt.content, tt.want, fields)
+		}
+	}
+}
+
+func TestPatternQueryErrors(t *testing.T) {
+	pattern := &docparser.PatternQuery{Name: "Data", Regex: regexp.MustCompile(`data=(\S+)`)}
+
+	_, err := pattern.Search("nothing here")
+	if _, ok := err.(*docparser.NoMatch); !ok {
+		t.Errorf("want NoMatch, got %#v", err)
+	}
+
+	_, err = pattern.Search("data=a%3D%ZZ")
+	if err == nil {
+		t.Errorf("invalid escape did not return error")
+	}
+
+	pattern.Regex = regexp.MustCompile(`data=\S+`)
+	if _, err = pattern.Search("data=a%3D1"); err == nil || err.Error() != "no group in regex for Data" {
+		t.Errorf("regex without group: invalid error %v", err)
+	}
+}
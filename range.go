@@ -0,0 +1,80 @@
+package docparser
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// AmountRangeRegex is the default regex of PatternRange, for USD ranges
+// like "$400,000 - $450,000", "$400,000 to $450,000" and the open ended
+// "$400,000+". The low amount needs a "$" or thousands separators, so
+// phone numbers like "808-221-1122" and dates like "2026-10-15" aren't
+// taken for ranges
+var AmountRangeRegex = regexp.MustCompile(`(?:^|[^\d.,])(?P<low>\$\d(?:[\d,.]*\d)?|\d{1,3}(?:,\d{3})+(?:\.\d+)?)\s*(?:(?:-|\x{2013}|to)\s*(?P<high>\$?\d(?:[\d,.]*\d)?)|\+)`)
+
+// PatternRange is a Pattern implementation that extracts a range of
+// monetary amounts, like listing prices, into two fields holding the
+// low and high amounts as int64 minor units (i.e. cents). Read them
+// with Get[int64]
+type PatternRange struct {
+	Name string
+
+	// Regex matches the range, the group "low" capturing the low
+	// amount and "high" the high one. If "high" doesn't participate in
+	// the match the range is open ended. Defaults to AmountRangeRegex
+	Regex *regexp.Regexp
+
+	// LowKey and HighKey are the fields of the low and high amounts.
+	// HighKey is left out for open ended ranges
+	LowKey, HighKey string
+
+	// Currency parses the amounts, defaults to DefaultCurrency
+	Currency *Currency
+
+	Optional bool
+}
+
+// Search for the range in content and parse both amounts
+//
+// Return NoMatch error if Regex doesn't match, and an error if an amount
+// can't be parsed
+func (pr *PatternRange) Search(content string) (Fields, error) {
+	regex := pr.Regex
+	if regex == nil {
+		regex = AmountRangeRegex
+	}
+	currency := DefaultCurrency
+	if pr.Currency != nil {
+		currency = *pr.Currency
+	}
+
+	match := regex.FindStringSubmatch(content)
+	if match == nil {
+		if pr.Optional {
+			return Fields{}, nil
+		}
+		return Fields{}, NewNoMatch(pr.Name, content)
+	}
+	fields := Fields{}
+	for _, key := range []string{"low", "high"} {
+		i := regex.SubexpIndex(key)
+		if i == -1 || match[i] == "" {
+			continue
+		}
+		amount, err := currency.ParseMinor(match[i])
+		if err != nil {
+			return Fields{}, fmt.Errorf("failed to parse %s amount for %s: %v", key, pr.Name, err)
+		}
+		if key == "low" {
+			fields[pr.LowKey] = amount
+		} else {
+			fields[pr.HighKey] = amount
+		}
+	}
+	return fields, nil
+}
+
+// FieldNames returns LowKey and HighKey
+func (pr *PatternRange) FieldNames() []string {
+	return []string{pr.LowKey, pr.HighKey}
+}
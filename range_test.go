@@ -0,0 +1,90 @@
+package docparser_test
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+func ExamplePatternRange() {
+	pattern := &docparser.PatternRange{
+		Name:    "Price range",
+		LowKey:  "price_min",
+		HighKey: "price_max",
+	}
+
+	fields, err := pattern.Search("Looking for homes in $400,000 - $450,000.")
+	if err != nil {
+		panic(err)
+	}
+
+	low, _ := docparser.Get[int64](fields, "price_min")
+	high, _ := docparser.Get[int64](fields, "price_max")
+	fmt.Println(low, high)
+	// Output:
+	// 40000000 45000000
+}
+
+func TestPatternRange(t *testing.T) {
+	pattern := &docparser.PatternRange{LowKey: "low", HighKey: "high"}
+	var tests = []struct {
+		content string
+		want    docparser.Fields
+	}{
+		{"$400,000-$450,000", docparser.Fields{"low": int64(40000000), "high": int64(45000000)}},
+		{"Price: $400,000 to $450,000.50", docparser.Fields{"low": int64(40000000), "high": int64(45000050)}},
+		{"400,000 \u2013 450,000", docparser.Fields{"low": int64(40000000), "high": int64(45000000)}},
+		{"Budget $400,000+ cash", docparser.Fields{"low": int64(40000000)}},
+		{"Call 808-221-1122. Price: $400,000 - $450,000", docparser.Fields{"low": int64(40000000), "high": int64(45000000)}},
+		{"Listed 2026-10-15 for $400,000+", docparser.Fields{"low": int64(40000000)}},
+		{"$1,200 - 1,500 a month", docparser.Fields{"low": int64(120000), "high": int64(150000)}},
+	}
+	for _, tt := range tests {
+		fields, err := pattern.Search(tt.content)
+		if err != nil {
+			t.Errorf("content %q failed: %s", tt.content, err)
+			continue
+		}
+		if !reflect.DeepEqual(fields, tt.want) {
+			t.Errorf("content %q want %v got %v", tt.content, tt.want, fields)
+		}
+	}
+}
+
+func TestPatternRangeCustom(t *testing.T) {
+	euro := docparser.Currency{Symbol: "EUR", Grouping: ".", Decimal: ",", MinorDigits: 2}
+	pattern := &docparser.PatternRange{
+		Name:     "Range",
+		Regex:    regexp.MustCompile(`from (?P<low>[\d.,]+ EUR)(?: up to (?P<high>[\d.,]+ EUR))?`),
+		LowKey:   "low",
+		HighKey:  "high",
+		Currency: &euro,
+	}
+	fields, err := pattern.Search("from 1.250,50 EUR up to 2.000 EUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (docparser.Fields{"low": int64(125050), "high": int64(200000)}); !reflect.DeepEqual(fields, want) {
+		t.Errorf("want %v got %v", want, fields)
+	}
+
+	_, err = pattern.Search("from 1,2,3 EUR")
+	if err == nil || err.Error() != `failed to parse low amount for Range: invalid amount "1,2,3 EUR"` {
+		t.Errorf("invalid error: %v", err)
+	}
+	if _, err := pattern.Search("no range"); err == nil || err.Error() != `No match for "Range"` {
+		t.Errorf("want NoMatch got %v", err)
+	}
+}
+
+func TestPatternRangeNotAmounts(t *testing.T) {
+	pattern := &docparser.PatternRange{Name: "Range", LowKey: "low", HighKey: "high"}
+	for _, content := range []string{"Call 808-221-1122", "Listed 2026-10-15", "3-4 beds"} {
+		if _, err := pattern.Search(content); err == nil || err.Error() != `No match for "Range"` {
+			t.Errorf("%q: want NoMatch got %v", content, err)
+		}
+	}
+}
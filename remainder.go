@@ -0,0 +1,113 @@
+package docparser
+
+import "strings"
+
+// SearchRemainder is like Search but also returns the lines of content
+// no Pattern matched, i.e. to feed a catch-all or flag the text for
+// manual review
+//
+// A line is matched when it overlaps the text matched by a Pattern: the
+// whole Regex match of a PatternGroup, or every match with LabelValues,
+// or the ListRegex match of a PatternList. For other Patterns, whose
+// matched text isn't known, a line is matched when it contains one of
+// the string values they returned. The remainder is made of the
+// unmatched lines of the preprocessed content, in order and with their
+// line endings, leaving out blank lines
+func (d *Document) SearchRemainder(content string) (Fields, string, error) {
+	content, err := d.preprocess(content)
+	if err != nil {
+		return Fields{}, "", err
+	}
+	lines := strings.SplitAfter(content, "\n")
+	starts := make([]int, len(lines))
+	for i, pos := 0, 0; i < len(lines); i++ {
+		starts[i], pos = pos, pos+len(lines[i])
+	}
+	matched := make([]bool, len(lines))
+	markSpan := func(start, end int) {
+		for i, line := range lines {
+			if start < starts[i]+len(line) && end > starts[i] {
+				matched[i] = true
+			}
+		}
+	}
+	markValue := func(value string) {
+		for _, part := range strings.Split(value, "\n") {
+			if part = strings.TrimSpace(part); part == "" {
+				continue
+			}
+			for i, line := range lines {
+				if strings.Contains(line, part) {
+					matched[i] = true
+				}
+			}
+		}
+	}
+
+	fields, err := d.searchPatterns(content, func(p Pattern, f Fields) {
+		if len(f) == 0 {
+			return
+		}
+		if spans, ok := matchSpans(p, content); ok {
+			for _, span := range spans {
+				markSpan(span[0], span[1])
+			}
+			return
+		}
+		for _, value := range f.Flatten(".") {
+			markValue(value)
+		}
+	})
+	if err != nil {
+		return Fields{}, "", err
+	}
+
+	var remainder strings.Builder
+	for i, line := range lines {
+		if !matched[i] && strings.TrimSpace(line) != "" {
+			remainder.WriteString(line)
+		}
+	}
+	return fields, remainder.String(), nil
+}
+
+// matchSpans returns the byte offsets of the texts p matched in content,
+// if p is a Pattern whose matched text is known. That's a single span
+// except for PatternGroup.LabelValues, which uses every match of Regex
+func matchSpans(p Pattern, content string) ([][2]int, bool) {
+	switch p := p.(type) {
+	case *PatternGroup:
+		line, start, ok := selectLine(content, p.Line)
+		if !ok {
+			return nil, false
+		}
+		text, offsets := line, []int(nil)
+		if p.Fold != nil {
+			text, offsets = p.Fold(line)
+		}
+		var locs [][]int
+		if p.LabelValues {
+			locs = p.Regex.FindAllStringIndex(text, -1)
+		} else if loc := p.find(text); loc != nil {
+			locs = [][]int{loc}
+		}
+		if len(locs) == 0 {
+			return nil, false
+		}
+		spans := make([][2]int, len(locs))
+		for i, loc := range locs {
+			if offsets != nil {
+				loc[0], loc[1] = offsets[loc[0]], offsets[loc[1]]
+			}
+			spans[i] = [2]int{start + loc[0], start + loc[1]}
+		}
+		return spans, true
+	case *PatternList:
+		loc := p.ListRegex.FindStringSubmatchIndex(content)
+		if loc == nil || loc[2] < 0 {
+			return nil, false
+		}
+		return [][2]int{{loc[0], loc[1]}}, true
+	}
+	return nil, false
+}
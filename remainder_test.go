@@ -0,0 +1,87 @@
+package docparser_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+func ExampleDocument_SearchRemainder() {
+	document := &docparser.Document{
+		Patterns: []docparser.Pattern{
+			&docparser.PatternGroup{Regex: regexp.MustCompile(`Name: (?P<name>.*)`)},
+			&docparser.PatternGroup{Regex: regexp.MustCompile(`Phone: (?P<phone>.*)`)},
+		},
+	}
+
+	content := `Name: Mark
+Phone: 221-1122
+
+Please call after 5pm, I have a dog.
+`
+
+	_, remainder, err := document.SearchRemainder(content)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Print(remainder)
+	// Output:
+	// Please call after 5pm, I have a dog.
+}
+
+func TestDocumentSearchRemainder(t *testing.T) {
+	document := &docparser.Document{
+		Preprocess: []docparser.Preprocessor{docparser.NormalizeLineEndings},
+		Patterns: []docparser.Pattern{
+			&docparser.PatternGroup{Regex: regexp.MustCompile(`(?s:Address: (?P<address>.*?)\n\n)`)},
+			&docparser.PatternGroup{Regex: regexp.MustCompile(`(?P<mls>\d+)`), Line: -1},
+			&docparser.PatternGroup{Regex: regexp.MustCompile(`Fax: (?P<fax>.*)`), Optional: true},
+			&docparser.PatternList{
+				ListRegex:  regexp.MustCompile(`(?s:Tags:\n(?P<tags>.*?)\nEnd)`),
+				SplitRegex: regexp.MustCompile(`\n`),
+				ItemRegex:  regexp.MustCompile(`- (?P<tag>.*)`),
+			},
+			&docparser.PatternKeyValue{Labels: map[string][]string{"email": nil}},
+		},
+	}
+	content := "Address: 1 Main St\r\nHonolulu\r\n\r\nunmatched 1\r\nTags:\r\n- a\r\nEnd\r\nEmail: bob@site.com\r\nunmatched 2\r\nMLS 2211\r\n"
+
+	fields, remainder, err := document.SearchRemainder(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "unmatched 1\nunmatched 2\n"; remainder != want {
+		t.Errorf("want %q got %q", want, remainder)
+	}
+	if fields.GetString("mls") != "2211" || fields.GetString("email") != "bob@site.com" {
+		t.Errorf("invalid fields %v", fields)
+	}
+
+	if _, _, err := document.SearchRemainder("nothing"); err == nil {
+		t.Error("did not return error")
+	}
+}
+
+func TestDocumentSearchRemainderLabelValues(t *testing.T) {
+	document := &docparser.Document{
+		Patterns: []docparser.Pattern{
+			&docparser.PatternGroup{
+				Regex:       regexp.MustCompile(`(?P<label>Beds|Baths|Sqft): (?P<value>\d+)`),
+				LabelValues: true,
+			},
+		},
+	}
+	fields, remainder, err := document.SearchRemainder("Beds: 3\nBaths: 2  Sqft: 1500\nPool\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fields) != 3 {
+		t.Errorf("want 3 fields got %v", fields)
+	}
+	if remainder != "Pool\n" {
+		t.Errorf("want remainder %q got %q", "Pool\n", remainder)
+	}
+}
@@ -0,0 +1,208 @@
+package docparser
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// JSONSchema returns a JSON Schema describing the union of the fields
+// all Documents can return, i.e. for UIs that map parse results
+//
+// Fields are found with Document.FieldNames, so Patterns that don't
+// implement FieldNamer and fields added by Finalize are left out. It's
+// best-effort: fields are strings unless the Pattern is known to return
+// something else, like the lists of PatternList, PatternTable and the
+// other Patterns that return []Fields, arrays of objects with the item
+// keys as properties when they're known, the []string of PatternGroup
+// SliceFields and PatternCheckboxes, and the numbers of PatternRange and
+// PatternGeo. Wrappers like PatternScoped or Instrument are described by
+// the Pattern they wrap, and Patterns of other packages can describe
+// their fields implementing FieldSchemer. No property is required, since
+// which fields are found depends on the content
+func JSONSchema(ds Documents) ([]byte, error) {
+	properties := map[string]interface{}{}
+	for _, d := range ds {
+		known := map[string]interface{}{}
+		for _, p := range d.Patterns {
+			for name, schema := range patternSchemas(p) {
+				known[name] = schema
+			}
+		}
+		for _, name := range d.FieldNames() {
+			if schema, ok := known[name]; ok {
+				properties[name] = schema
+			} else if _, ok := properties[name]; !ok {
+				properties[name] = stringSchema()
+			}
+		}
+	}
+	return json.MarshalIndent(map[string]interface{}{
+		"$schema":    "http://json-schema.org/draft-07/schema#",
+		"type":       "object",
+		"properties": properties,
+	}, "", "  ")
+}
+
+// FieldSchemer is implemented by Patterns that return fields that aren't
+// strings, to describe them in JSONSchema
+type FieldSchemer interface {
+	// FieldSchemas returns the JSON Schema of the fields that aren't
+	// strings, by field name
+	FieldSchemas() map[string]interface{}
+}
+
+// patternSchemas returns the schema of the fields of p that aren't
+// strings
+func patternSchemas(p Pattern) map[string]interface{} {
+	if schemer, ok := p.(FieldSchemer); ok {
+		return schemer.FieldSchemas()
+	}
+	schemas := map[string]interface{}{}
+	switch p := p.(type) {
+	case *Document:
+		for _, inner := range p.Patterns {
+			for name, schema := range patternSchemas(inner) {
+				schemas[name] = schema
+			}
+		}
+	case *PatternScoped:
+		return patternSchemas(p.Inner)
+	case *PatternConditional:
+		return patternSchemas(p.Then)
+	case *PatternLimit:
+		return patternSchemas(p.Pattern)
+	case *instrumented:
+		return patternSchemas(p.pattern)
+	case *confident:
+		return patternSchemas(p.pattern)
+	case *PatternBase64:
+		if p.Pattern != nil {
+			return patternSchemas(p.Pattern)
+		}
+	case *PatternFallback:
+		for _, inner := range []Pattern{p.Secondary, p.Primary} {
+			for name, schema := range patternSchemas(inner) {
+				schemas[name] = schema
+			}
+		}
+	case *PatternGroup:
+		for name := range p.SliceFields {
+			schemas[name] = arraySchema(stringSchema())
+		}
+	case *PatternList:
+		names := p.FieldNames()
+		if len(names) == 0 {
+			break
+		}
+		var keys []string
+		if p.ItemRegex != nil {
+			keys = subexpNames(p.ItemRegex)
+		}
+		for key := range p.Defaults {
+			keys = append(keys, key)
+		}
+		if p.IncludeFullMatch {
+			keys = append(keys, fullMatchKey(p.FullMatchKey))
+		}
+		schemas[names[0]] = objectsSchema(keys...)
+	case *PatternQA:
+		schemas[p.OutputKey] = objectsSchema("question", "answer")
+	case *PatternTable:
+		schemas[p.OutputKey] = anyObjectsSchema()
+	case *PatternMarkdownTable:
+		schemas[p.OutputKey] = anyObjectsSchema()
+	case *PatternFixedWidth:
+		keys := make([]string, 0, len(p.Fields))
+		for _, field := range p.Fields {
+			keys = append(keys, field.Name)
+		}
+		schemas[p.OutputKey] = objectsSchema(keys...)
+	case *PatternSections:
+		namer, ok := p.SectionPattern.(FieldNamer)
+		if !ok {
+			schemas[p.OutputKey] = anyObjectsSchema()
+			break
+		}
+		properties := stringProperties(namer.FieldNames()...)
+		for name, schema := range patternSchemas(p.SectionPattern) {
+			properties[name] = schema
+		}
+		schemas[p.OutputKey] = objectsOf(properties)
+	case *PatternOutline:
+		childrenKey := p.ChildrenKey
+		if childrenKey == "" {
+			childrenKey = "children"
+		}
+		properties := stringProperties(subexpNames(p.ItemRegex)...)
+		properties[childrenKey] = map[string]interface{}{"$ref": "#/properties/" + jsonPointerEscape(p.OutputKey)}
+		schemas[p.OutputKey] = objectsOf(properties)
+	case *PatternCheckboxes:
+		if p.All {
+			properties := stringProperties("label")
+			properties["checked"] = map[string]interface{}{"type": "boolean"}
+			schemas[p.OutputKey] = objectsOf(properties)
+		} else {
+			schemas[p.OutputKey] = arraySchema(stringSchema())
+		}
+	case *PatternQuery:
+		for _, key := range p.Keys {
+			if p.Flatten {
+				schemas[key] = map[string]interface{}{"type": []string{"string", "array"}, "items": stringSchema()}
+			} else {
+				schemas[key] = arraySchema(stringSchema())
+			}
+		}
+	case *PatternRange:
+		schemas[p.LowKey] = map[string]interface{}{"type": "integer"}
+		schemas[p.HighKey] = map[string]interface{}{"type": "integer"}
+	case *PatternGeo:
+		schemas[p.LatKey] = map[string]interface{}{"type": "number"}
+		schemas[p.LonKey] = map[string]interface{}{"type": "number"}
+	}
+	return schemas
+}
+
+// jsonPointerEscape escapes a key to be a JSON Pointer reference token
+func jsonPointerEscape(key string) string {
+	return strings.Replace(strings.Replace(key, "~", "~0", -1), "/", "~1", -1)
+}
+
+func stringSchema() map[string]interface{} {
+	return map[string]interface{}{"type": "string"}
+}
+
+func arraySchema(items map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"type": "array", "items": items}
+}
+
+// objectsSchema is the schema of a []Fields whose items have keys
+func objectsSchema(keys ...string) map[string]interface{} {
+	return objectsOf(stringProperties(keys...))
+}
+
+// objectsOf is the schema of a []Fields whose items have properties
+func objectsOf(properties map[string]interface{}) map[string]interface{} {
+	return arraySchema(map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	})
+}
+
+// stringProperties returns the properties of an object whose keys are
+// all strings
+func stringProperties(keys ...string) map[string]interface{} {
+	properties := map[string]interface{}{}
+	for _, key := range keys {
+		properties[key] = stringSchema()
+	}
+	return properties
+}
+
+// anyObjectsSchema is the schema of a []Fields whose item keys depend on
+// the content, like the columns of a table
+func anyObjectsSchema() map[string]interface{} {
+	return arraySchema(map[string]interface{}{
+		"type":                 "object",
+		"additionalProperties": stringSchema(),
+	})
+}
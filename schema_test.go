@@ -0,0 +1,151 @@
+package docparser_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+func ExampleJSONSchema() {
+	ds := docparser.Documents{
+		&docparser.Document{
+			Name: "Lead",
+			Patterns: []docparser.Pattern{
+				&docparser.PatternGroup{
+					Regex: regexp.MustCompile(`Name: (?P<name>.*)`),
+				},
+			},
+		},
+	}
+
+	schema, err := docparser.JSONSchema(ds)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(string(schema))
+	// Output:
+	// {
+	//   "$schema": "http://json-schema.org/draft-07/schema#",
+	//   "properties": {
+	//     "name": {
+	//       "type": "string"
+	//     }
+	//   },
+	//   "type": "object"
+	// }
+}
+
+func TestJSONSchema(t *testing.T) {
+	ds := docparser.Documents{
+		&docparser.Document{
+			Patterns: []docparser.Pattern{
+				&docparser.PatternGroup{
+					Regex:       regexp.MustCompile(`Name: (?P<name>.*)\nTags: (?P<tags>.*)`),
+					SliceFields: map[string]string{"tags": ","},
+				},
+				&docparser.PatternList{
+					ListRegex:        regexp.MustCompile(`(?s)Listings:\n(?P<listings>.*)`),
+					SplitRegex:       regexp.MustCompile(`\n`),
+					ItemRegex:        regexp.MustCompile(`(?P<mls>\d+) (?P<price>.*)`),
+					Defaults:         map[string]string{"status": "Active"},
+					IncludeFullMatch: true,
+				},
+			},
+		},
+		&docparser.Document{
+			Patterns: []docparser.Pattern{
+				&docparser.PatternGroup{Regex: regexp.MustCompile(`Name: (?P<name>.*) (?P<phone>.*)`)},
+				&docparser.PatternQA{OutputKey: "questions"},
+			},
+		},
+	}
+	schema, err := docparser.JSONSchema(ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(schema, &got); err != nil {
+		t.Fatal(err)
+	}
+	str := map[string]interface{}{"type": "string"}
+	objects := func(keys ...string) map[string]interface{} {
+		properties := map[string]interface{}{}
+		for _, key := range keys {
+			properties[key] = str
+		}
+		return map[string]interface{}{
+			"type":  "array",
+			"items": map[string]interface{}{"type": "object", "properties": properties},
+		}
+	}
+	want := map[string]interface{}{
+		"name":      str,
+		"phone":     str,
+		"tags":      map[string]interface{}{"type": "array", "items": str},
+		"listings":  objects("mls", "price", "status", "_match"),
+		"questions": objects("question", "answer"),
+	}
+	if !reflect.DeepEqual(got["properties"], want) {
+		t.Errorf("want %v got %v", want, got["properties"])
+	}
+}
+
+func TestJSONSchemaPatternTypes(t *testing.T) {
+	item := &docparser.PatternGroup{Regex: regexp.MustCompile(`Name: (?P<name>.*)`)}
+	ds := docparser.Documents{
+		&docparser.Document{
+			Patterns: []docparser.Pattern{
+				&docparser.PatternTable{Header: regexp.MustCompile(`MLS`), OutputKey: "table"},
+				&docparser.PatternMarkdownTable{OutputKey: "markdown"},
+				&docparser.PatternFixedWidth{Fields: []docparser.FixedField{{Name: "mls", Length: 4}}, OutputKey: "records"},
+				&docparser.PatternSections{SectionPattern: &docparser.Document{Patterns: []docparser.Pattern{item}}, OutputKey: "sections"},
+				&docparser.PatternOutline{ItemRegex: regexp.MustCompile(`- (?P<text>.*)`), OutputKey: "outline"},
+				&docparser.PatternCheckboxes{OutputKey: "selected"},
+				&docparser.PatternCheckboxes{OutputKey: "options", All: true},
+				&docparser.PatternQuery{Regex: regexp.MustCompile(`q=(.*)`), Keys: []string{"tag"}},
+				docparser.Instrument(&docparser.PatternRange{LowKey: "low", HighKey: "high"}, docparser.Hooks{}),
+				&docparser.PatternScoped{Inner: &docparser.PatternGeo{LatKey: "lat", LonKey: "lon"}},
+			},
+		},
+	}
+	schema, err := docparser.JSONSchema(ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(schema, &got); err != nil {
+		t.Fatal(err)
+	}
+	str := map[string]interface{}{"type": "string"}
+	array := func(items interface{}) map[string]interface{} {
+		return map[string]interface{}{"type": "array", "items": items}
+	}
+	objects := func(properties map[string]interface{}) map[string]interface{} {
+		return array(map[string]interface{}{"type": "object", "properties": properties})
+	}
+	anyObjects := array(map[string]interface{}{"type": "object", "additionalProperties": str})
+	want := map[string]interface{}{
+		"table":    anyObjects,
+		"markdown": anyObjects,
+		"records":  objects(map[string]interface{}{"mls": str}),
+		"sections": objects(map[string]interface{}{"name": str}),
+		"outline":  objects(map[string]interface{}{"text": str, "children": map[string]interface{}{"$ref": "#/properties/outline"}}),
+		"selected": array(str),
+		"options":  objects(map[string]interface{}{"label": str, "checked": map[string]interface{}{"type": "boolean"}}),
+		"tag":      array(str),
+		"low":      map[string]interface{}{"type": "integer"},
+		"high":     map[string]interface{}{"type": "integer"},
+		"lat":      map[string]interface{}{"type": "number"},
+		"lon":      map[string]interface{}{"type": "number"},
+	}
+	if !reflect.DeepEqual(got["properties"], want) {
+		t.Errorf("want %v got %v", want, got["properties"])
+	}
+}
@@ -0,0 +1,47 @@
+package docparser
+
+import "regexp"
+
+// PatternScoped is a Pattern that runs another Pattern only against the
+// region of the content between two markers, i.e. to find the "Name:"
+// of the buyer section when the seller section also has one
+type PatternScoped struct {
+	Name string
+
+	// Start marks the beginning of the region, which starts right
+	// after the first Start match
+	Start *regexp.Regexp
+
+	// End marks the end of the region, which ends right before the
+	// first End match after Start. If nil the region goes up to the end
+	// of the content
+	End *regexp.Regexp
+
+	// Inner Pattern searched in the region, errors are returned as is
+	Inner Pattern
+}
+
+// Search returns the result of Inner.Search() with the region between
+// Start and End
+//
+// Return NoMatch error if Start or End are not found
+func (ps *PatternScoped) Search(content string) (Fields, error) {
+	start := ps.Start.FindStringIndex(content)
+	if start == nil {
+		return Fields{}, NewNoMatch(ps.Name+" - start", content)
+	}
+	region := content[start[1]:]
+	if ps.End != nil {
+		end := ps.End.FindStringIndex(region)
+		if end == nil {
+			return Fields{}, NewNoMatch(ps.Name+" - end", content)
+		}
+		region = region[:end[0]]
+	}
+	return ps.Inner.Search(region)
+}
+
+// FieldNames, SetFields and GetFields forward to Inner, see wrappedPattern
+func (ps *PatternScoped) FieldNames() []string { return wrappedPattern{ps.Inner}.FieldNames() }
+func (ps *PatternScoped) SetFields(f Fields)   { wrappedPattern{ps.Inner}.SetFields(f) }
+func (ps *PatternScoped) GetFields() Fields    { return wrappedPattern{ps.Inner}.GetFields() }
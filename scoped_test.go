@@ -0,0 +1,86 @@
+package docparser_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+func ExamplePatternScoped() {
+	name := func(key string) docparser.Pattern {
+		return &docparser.PatternGroup{
+			Name:  "Name",
+			Regex: regexp.MustCompile(`Name: (?P<` + key + `>.*)\n`),
+		}
+	}
+	document := &docparser.Document{
+		Patterns: []docparser.Pattern{
+			&docparser.PatternScoped{
+				Name:  "Seller",
+				Start: regexp.MustCompile(`(?m:^Seller$)`),
+				End:   regexp.MustCompile(`(?m:^Buyer$)`),
+				Inner: name("seller"),
+			},
+			&docparser.PatternScoped{
+				Name:  "Buyer",
+				Start: regexp.MustCompile(`(?m:^Buyer$)`),
+				Inner: name("buyer"),
+			},
+		},
+	}
+
+	content := "Seller\nName: Jane\nBuyer\nName: Mark\n"
+
+	fields, err := document.Search(content)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(fields.GetString("seller"))
+	fmt.Println(fields.GetString("buyer"))
+	// Output:
+	// Jane
+	// Mark
+}
+
+func TestPatternScoped(t *testing.T) {
+	pattern := &docparser.PatternScoped{
+		Name:  "Buyer",
+		Start: regexp.MustCompile(`Buyer:\n`),
+		End:   regexp.MustCompile(`\n\n`),
+		Inner: &docparser.PatternGroup{
+			Name:  "Name",
+			Regex: regexp.MustCompile(`Name: (?P<name>.*)`),
+		},
+	}
+	var tests = []struct {
+		content string
+		name    string
+		err     string
+	}{
+		{content: "Name: Jane\nBuyer:\nName: Mark\n\nName: Bob", name: "Mark"},
+		{content: "Name: Jane\n\nBuyer:\nName: Mark\n\n", name: "Mark"},
+		{content: "Name: Jane\nBuyer:\nPhone: 111\n\nName: Bob", err: `No match for "Name"`},
+		{content: "Name: Jane\n", err: `No match for "Buyer - start"`},
+		{content: "Buyer:\nName: Mark", err: `No match for "Buyer - end"`},
+		{content: "\n\nBuyer:\nName: Mark", err: `No match for "Buyer - end"`},
+	}
+	for _, tt := range tests {
+		fields, err := pattern.Search(tt.content)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("content %q want error %q got %v", tt.content, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("content %q failed: %s", tt.content, err)
+			continue
+		}
+		if name := fields.GetString("name"); name != tt.name {
+			t.Errorf("content %q want name %q got %q", tt.content, tt.name, name)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package docparser
+
+// Confident is implemented by Patterns that declare how much their
+// fields can be trusted, from 0 to 1, see Document.SearchScored
+type Confident interface {
+	Confidence() float64
+}
+
+// LowConfidence is the score of the fields PatternFallback found with
+// its Secondary pattern
+const LowConfidence = 0.5
+
+// WithConfidence wraps p so its fields are scored with confidence by
+// Document.SearchScored, i.e. 1 for strict patterns and lower for loose
+// ones
+//
+// The returned Pattern returns the same fields and errors as p. If p is
+// a PatternWithFields the fields collected so far are forwarded to it
+func WithConfidence(p Pattern, confidence float64) Pattern {
+	return &confident{wrappedPattern{p}, confidence}
+}
+
+type confident struct {
+	wrappedPattern
+	confidence float64
+}
+
+func (c *confident) Search(content string) (Fields, error) {
+	return c.pattern.Search(content)
+}
+
+func (c *confident) Confidence() float64 {
+	return c.confidence
+}
+
+// SearchScored is like Search but also returns a score from 0 to 1 for
+// each field, i.e. to decide which value to keep when merging results
+// from several sources
+//
+// A field is scored with the Confidence of the Pattern that returned
+// it, if the Pattern is Confident, otherwise 1. Fields PatternFallback
+// tagged as low confidence score at most LowConfidence. Fields added by
+// Finalize score 1
+func (d *Document) SearchScored(content string) (Fields, map[string]float64, error) {
+	scores := map[string]float64{}
+	fields, err := d.search(content, func(p Pattern, f Fields) {
+		score := 1.0
+		if c, ok := p.(Confident); ok {
+			score = c.Confidence()
+		}
+		for key := range f {
+			scores[key] = score
+			if f[confidenceKey(key)] == "low" && score > LowConfidence {
+				scores[key] = LowConfidence
+			}
+		}
+	})
+	if err != nil {
+		return Fields{}, nil, err
+	}
+	for key := range scores {
+		if !fields.Has(key) {
+			delete(scores, key)
+		}
+	}
+	for key := range fields {
+		if _, ok := scores[key]; !ok {
+			scores[key] = 1
+		}
+	}
+	return fields, scores, nil
+}
@@ -0,0 +1,67 @@
+package docparser_test
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+func ExampleDocument_SearchScored() {
+	document := &docparser.Document{
+		Patterns: []docparser.Pattern{
+			&docparser.PatternGroup{Regex: regexp.MustCompile(`Name: (?P<name>.*)`)},
+			docparser.WithConfidence(&docparser.PatternGroup{
+				Regex: regexp.MustCompile(`(?P<phone>\d{3}-\d{4})`),
+			}, 0.7),
+		},
+	}
+
+	_, scores, err := document.SearchScored("Name: Mark\nCall 221-1122")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(scores["name"], scores["phone"])
+	// Output:
+	// 1 0.7
+}
+
+func TestDocumentSearchScored(t *testing.T) {
+	document := &docparser.Document{
+		Patterns: []docparser.Pattern{
+			docparser.WithConfidence(&docparser.PatternGroup{Regex: regexp.MustCompile(`Name: (?P<name>\w+)`)}, 0.9),
+			&docparser.PatternFallback{
+				Primary:   &docparser.PatternGroup{Regex: regexp.MustCompile(`Phone: (?P<phone>\d{3}-\d{4})`)},
+				Secondary: &docparser.PatternGroup{Regex: regexp.MustCompile(`(?P<phone>\d[\d ]+\d)`)},
+			},
+			docparser.WithConfidence(&docparser.PatternGroup{Regex: regexp.MustCompile(`(?P<name>Bob)`)}, 0.2),
+		},
+		Finalize: func(f docparser.Fields) (docparser.Fields, error) {
+			f["greeting"] = "Hi " + f.GetString("name")
+			return f, nil
+		},
+	}
+	fields, scores, err := document.SearchScored("Name: Mark\ncall 221 1122 Bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]float64{
+		"name":              0.2,
+		"phone":             docparser.LowConfidence,
+		"_phone_confidence": 1,
+		"greeting":          1,
+	}
+	if !reflect.DeepEqual(scores, want) {
+		t.Errorf("want %v got %v", want, scores)
+	}
+	if fields.GetString("name") != "Bob" {
+		t.Errorf("invalid fields %v", fields)
+	}
+
+	if _, _, err := document.SearchScored("nothing"); err == nil {
+		t.Error("did not return error")
+	}
+}
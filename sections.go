@@ -0,0 +1,108 @@
+package docparser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// BlankLines matches one or more blank lines, the default SectionSplit
+// of PatternSections
+var BlankLines = regexp.MustCompile(`\r?\n(?:[ \t]*\r?\n)+`)
+
+// PatternSections is a Pattern implementation for documents that repeat
+// a whole block of fields, like "Buyer 1: ... Buyer 2: ...". The
+// content is split into sections and another Pattern extracts the
+// fields of each one
+//
+// It sits between PatternGroup, that finds fields once, and PatternList,
+// that finds a list of single line items
+type PatternSections struct {
+	Name string
+
+	// SectionSplit splits the content into sections, the same way
+	// PatternList.SplitRegex splits the list. Sections that are empty
+	// or only white space are ignored. Note the text before the first
+	// match is a section too, use PatternScoped to skip a preamble.
+	// Defaults to BlankLines
+	SectionSplit *regexp.Regexp
+
+	// SectionPattern is searched in each section, usually a
+	// PatternGroup or a Document
+	SectionPattern Pattern
+
+	// OutputKey is the field that holds the []Fields of all sections
+	OutputKey string
+
+	// SkipUnmatchedSections drops the sections SectionPattern doesn't
+	// match instead of returning NoMatch
+	SkipUnmatchedSections bool
+
+	// Optional returns empty Fields instead of NoMatch if no section
+	// is found
+	Optional bool
+}
+
+// Search splits content into sections and searches SectionPattern in
+// each one
+//
+// Return value will be a hash with only OutputKey where the value is a
+// slice of Fields, one for each section. Return NoMatch if
+// SectionPattern doesn't match some section, or if there are no
+// sections
+func (ps *PatternSections) Search(content string) (Fields, error) {
+	split := ps.SectionSplit
+	if split == nil {
+		split = BlankLines
+	}
+	sections := []Fields{}
+	for i, text := range split.Split(content, -1) {
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		fields, err := ps.SectionPattern.Search(text)
+		if _, ok := err.(*NoMatch); ok {
+			if ps.SkipUnmatchedSections {
+				continue
+			}
+			return Fields{}, NewNoMatch(fmt.Sprintf("%s - section %d", ps.Name, i), text)
+		}
+		if err != nil {
+			return Fields{}, err
+		}
+		sections = append(sections, fields)
+	}
+	if len(sections) == 0 {
+		if ps.Optional {
+			return Fields{}, nil
+		}
+		return Fields{}, NewNoMatch(ps.Name, content)
+	}
+	return Fields{ps.OutputKey: sections}, nil
+}
+
+// FieldNames returns OutputKey
+func (ps *PatternSections) FieldNames() []string {
+	return []string{ps.OutputKey}
+}
+
+// BlankLineSeparatedRecords returns a PatternSections for the common
+// layout of records separated by blank lines, each one a block of
+// "key: value" lines, storing the []Fields of all records in "records"
+//
+// Each record is searched with a PatternKeyValue for the labels keys,
+// or every label if there are none. Blocks without any of the labels,
+// like a footer, are skipped. Fields of the returned pattern can be
+// changed to tune it, i.e. its OutputKey
+func BlankLineSeparatedRecords(keys ...string) *PatternSections {
+	labels := make(map[string][]string, len(keys))
+	for _, key := range keys {
+		labels[key] = nil
+	}
+	return &PatternSections{
+		Name:                  "Records",
+		SectionPattern:        &PatternKeyValue{Name: "Record", Labels: labels},
+		OutputKey:             "records",
+		SkipUnmatchedSections: true,
+	}
+}
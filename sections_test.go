@@ -0,0 +1,126 @@
+package docparser_test
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+func ExamplePatternSections() {
+	pattern := &docparser.PatternSections{
+		Name:         "Buyers",
+		SectionSplit: regexp.MustCompile(`Buyer \d+:\n`),
+		SectionPattern: &docparser.PatternGroup{
+			Name:  "Buyer",
+			Regex: regexp.MustCompile(`Name: (?P<name>.*)\nPhone: (?P<phone>.*)\n`),
+		},
+		OutputKey: "buyers",
+	}
+
+	content := `Buyer 1:
+Name: Mark Stewart
+Phone: (123) 221-1122
+Buyer 2:
+Name: Jane Stewart
+Phone: (123) 221-3344
+`
+
+	fields, err := pattern.Search(content)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, buyer := range fields.GetMapSlice("buyers") {
+		fmt.Printf("%s: %s\n", buyer["name"], buyer["phone"])
+	}
+	// Output:
+	// Mark Stewart: (123) 221-1122
+	// Jane Stewart: (123) 221-3344
+}
+
+func TestPatternSections(t *testing.T) {
+	pattern := &docparser.PatternSections{
+		Name:         "Records",
+		SectionSplit: regexp.MustCompile(`\n---\n`),
+		SectionPattern: &docparser.PatternGroup{
+			Name:  "Record",
+			Regex: regexp.MustCompile(`Name: (?P<name>.*)`),
+		},
+		OutputKey: "records",
+	}
+	content := "Name: a\n---\nName: b\n---\n  \n---\nfooter\n"
+
+	if _, err := pattern.Search(content); err == nil || err.Error() != `No match for "Records - section 3"` {
+		t.Errorf("want NoMatch for section 3, got %v", err)
+	}
+
+	pattern.SkipUnmatchedSections = true
+	fields, err := pattern.Search(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]string{{"name": "a"}, {"name": "b"}}
+	if records := fields.GetMapSlice("records"); !reflect.DeepEqual(records, want) {
+		t.Errorf("want %v got %v", want, records)
+	}
+
+	if _, err := pattern.Search("footer"); err == nil || err.Error() != `No match for "Records"` {
+		t.Errorf("no sections want NoMatch, got %v", err)
+	}
+	pattern.Optional = true
+	if fields, err := pattern.Search("footer"); err != nil || len(fields) != 0 {
+		t.Errorf("optional want empty fields, got %v (%v)", fields, err)
+	}
+}
+
+func ExampleBlankLineSeparatedRecords() {
+	pattern := docparser.BlankLineSeparatedRecords("name", "phone")
+
+	content := `Name: Mark
+Phone: 221-1122
+
+Name: Jane
+Phone: 221-3344
+`
+
+	fields, err := pattern.Search(content)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, record := range fields.GetMapSlice("records") {
+		fmt.Println(record["name"], record["phone"])
+	}
+	// Output:
+	// Mark 221-1122
+	// Jane 221-3344
+}
+
+func TestBlankLineSeparatedRecords(t *testing.T) {
+	content := "\n\n  Name: Mark\n  Phone: 221-1122\r\n\r\n \t\n\nName: Jane\nEmail: jane@site.com\n\nThanks for using our service\n\n\n"
+
+	fields, err := docparser.BlankLineSeparatedRecords("name", "phone").Search(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]string{{"name": "Mark", "phone": "221-1122"}, {"name": "Jane"}}
+	if records := fields.GetMapSlice("records"); !reflect.DeepEqual(records, want) {
+		t.Errorf("want %v got %v", want, records)
+	}
+
+	fields, err = docparser.BlankLineSeparatedRecords().Search(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []map[string]string{{"Name": "Mark", "Phone": "221-1122"}, {"Name": "Jane", "Email": "jane@site.com"}}
+	if records := fields.GetMapSlice("records"); !reflect.DeepEqual(records, want) {
+		t.Errorf("all labels: want %v got %v", want, records)
+	}
+
+	if _, err := docparser.BlankLineSeparatedRecords("name").Search("no records\n\nhere"); err == nil {
+		t.Error("did not return NoMatch")
+	}
+}
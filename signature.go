@@ -0,0 +1,164 @@
+package docparser
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	signatureEmail = regexp.MustCompile(`[\w.+-]+@[\w-]+(?:\.[\w-]+)+`)
+	signaturePhone = regexp.MustCompile(`\+?\(?\d[\d ().-]{5,}\d`)
+	signatureURL   = regexp.MustCompile(`^(?i:https?://|www\.)`)
+)
+
+// signatureClosings are lines that end the message and start the
+// signature, in lowercase without punctuation
+var signatureClosings = map[string]bool{
+	"thanks": true, "thank you": true, "regards": true, "best": true,
+	"best regards": true, "kind regards": true, "warm regards": true,
+	"sincerely": true, "cheers": true, "all the best": true,
+	"sent from my iphone": true,
+}
+
+// PatternSignature is a Pattern implementation that extracts contact
+// fields from the signature at the end of emails, for when it's the
+// only structured data in the message
+//
+// It's heuristic, and applies these rules in order to the last Lines
+// lines of content:
+//
+//	Only the lines after a "--" delimiter line are used, if there's one
+//	"email" is the first email address found
+//	"phone" is the first run of at least 7 digits, spaces and ().-
+//	Lines with an email, phone or URL hold nothing else
+//	"name" is the first line, or its first "|" separated part, of 2 to
+//	4 capitalized words without digits, like "Mark A. Stewart"
+//	"title" and "company" are the next parts of the name line, or the
+//	lines right after it
+//
+// Closings like "Best regards" and empty lines are ignored
+type PatternSignature struct {
+	Name string
+
+	// Lines is the number of trailing lines that may hold the
+	// signature, defaults to 10
+	Lines int
+
+	Optional bool
+}
+
+// Search the end of content for a signature
+//
+// Return NoMatch error if no field is found and the pattern isn't
+// Optional
+func (ps *PatternSignature) Search(content string) (Fields, error) {
+	fields := Fields{}
+	following := false // the line is right after the name
+	for _, line := range ps.lines(content) {
+		line = strings.TrimSpace(line)
+		if line == "" || signatureClosings[normalizeClosing(line)] {
+			following = false
+			continue
+		}
+		contact := signatureURL.MatchString(line)
+		if email := signatureEmail.FindString(line); email != "" {
+			if !fields.Has("email") {
+				fields["email"] = email
+			}
+			line, contact = strings.Replace(line, email, "", 1), true
+		}
+		if phone := signaturePhone.FindString(line); phone != "" {
+			if !fields.Has("phone") {
+				fields["phone"] = strings.TrimSpace(phone)
+			}
+			contact = true
+		}
+		if contact {
+			following = false
+			continue
+		}
+		parts := splitSignatureLine(line)
+		switch {
+		case !fields.Has("name") && isPersonName(parts[0]):
+			fields["name"], parts = parts[0], parts[1:]
+			following = true
+		case !following:
+			continue
+		}
+		for _, part := range parts {
+			if !fields.Has("title") {
+				fields["title"] = part
+			} else if !fields.Has("company") {
+				fields["company"] = part
+			}
+		}
+	}
+	if len(fields) == 0 && !ps.Optional {
+		return Fields{}, NewNoMatch(ps.Name, content)
+	}
+	return fields, nil
+}
+
+// FieldNames returns the fields the signature may populate
+func (ps *PatternSignature) FieldNames() []string {
+	return []string{"company", "email", "name", "phone", "title"}
+}
+
+// lines returns the lines of content the signature may be in
+func (ps *PatternSignature) lines(content string) []string {
+	n := ps.Lines
+	if n <= 0 {
+		n = 10
+	}
+	lines := strings.Split(strings.TrimRight(content, " \t\r\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.TrimSpace(lines[i]) == "--" {
+			return lines[i+1:]
+		}
+	}
+	return lines
+}
+
+func splitSignatureLine(line string) []string {
+	var parts []string
+	for _, part := range strings.Split(line, "|") {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return []string{""}
+	}
+	return parts
+}
+
+// normalizeClosing lowercases line and removes punctuation, so "Best
+// Regards," can be looked up in signatureClosings
+func normalizeClosing(line string) string {
+	return strings.TrimRight(strings.ToLower(line), ",.!- \u2013")
+}
+
+// isPersonName reports whether s looks like a person's name: 2 to 4
+// words starting with an uppercase letter, made only of letters and
+// .'-
+func isPersonName(s string) bool {
+	words := strings.Fields(s)
+	if len(words) < 2 || len(words) > 4 {
+		return false
+	}
+	for _, word := range words {
+		for i, r := range word {
+			if i == 0 && !unicode.IsUpper(r) {
+				return false
+			}
+			if !unicode.IsLetter(r) && !strings.ContainsRune(".'-", r) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+package docparser_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+func ExamplePatternSignature() {
+	pattern := &docparser.PatternSignature{Name: "Signature"}
+
+	content := `Hi, I'd like to see the house on Sunday.
+
+Best regards,
+Mark Stewart | Broker | Stewart Realty
+Cell: (808) 221-1122
+mark@stewart.example.com
+`
+
+	fields, err := pattern.Search(content)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, key := range []string{"name", "title", "company", "phone", "email"} {
+		fmt.Printf("%s: %s\n", key, fields.GetString(key))
+	}
+	// Output:
+	// name: Mark Stewart
+	// title: Broker
+	// company: Stewart Realty
+	// phone: (808) 221-1122
+	// email: mark@stewart.example.com
+}
+
+func TestPatternSignature(t *testing.T) {
+	var tests = []struct {
+		lines   int
+		content string
+		want    docparser.Fields
+	}{
+		{
+			0,
+			"Thanks!\n\nJane O'Neil\nListing Agent\nAcme Homes\nwww.acme.example.com\n+1 808 555 0100\n",
+			docparser.Fields{"name": "Jane O'Neil", "title": "Listing Agent", "company": "Acme Homes", "phone": "+1 808 555 0100"},
+		},
+		{
+			0,
+			"Call Bob Smith at the office.\n--\nMark A. Stewart\nmark@site.com\n",
+			docparser.Fields{"name": "Mark A. Stewart", "email": "mark@site.com"},
+		},
+		{
+			0,
+			"Please call me back\n221-1122\n",
+			docparser.Fields{"phone": "221-1122"},
+		},
+		{
+			2,
+			"Mark Stewart\nBroker\nline\nlast line\n",
+			docparser.Fields{},
+		},
+		{
+			0,
+			"Sent from my iPhone\nJose \u00c1lvarez\n",
+			docparser.Fields{"name": "Jose \u00c1lvarez"},
+		},
+	}
+	for i, tt := range tests {
+		pattern := &docparser.PatternSignature{Lines: tt.lines, Optional: true}
+		fields, err := pattern.Search(tt.content)
+		if err != nil {
+			t.Errorf("%d: %s", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(fields, tt.want) {
+			t.Errorf("%d: want %v got %v", i, tt.want, fields)
+		}
+	}
+}
+
+func TestPatternSignatureNoMatch(t *testing.T) {
+	pattern := &docparser.PatternSignature{Name: "Signature"}
+	_, err := pattern.Search("see you there\n")
+	if _, ok := err.(*docparser.NoMatch); !ok {
+		t.Errorf("want NoMatch got %#v", err)
+	}
+}
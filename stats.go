@@ -0,0 +1,140 @@
+package docparser
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Counts are how many times something matched and failed
+type Counts struct {
+	Matched, Failed int
+}
+
+// DocumentStats are the Counts of a Document and of each of its
+// Patterns, see Stats
+type DocumentStats struct {
+	Counts
+
+	// Patterns are the Counts of each Pattern, in order. Patterns that
+	// weren't searched because content didn't pass Preprocess or SkipIf,
+	// or an earlier Pattern failed, aren't counted. With
+	// Document.MinMatches the Patterns skipped count as failed
+	Patterns []Counts
+}
+
+// Stats collects how often Documents and their Patterns matched, i.e.
+// to export them to a metrics system and alert when a sender changes
+// its layout. Pass the same Stats to SearchWithStats, it's safe to use
+// from several goroutines. The zero value is ready to use
+//
+// Optional Patterns never fail, and Documents are counted by Name, so
+// Documents sharing a name share their counts
+type Stats struct {
+	mu        sync.Mutex
+	searches  Counts
+	documents map[string]*DocumentStats
+}
+
+// Searches returns the Counts of Documents.SearchWithStats, failing
+// when no Document matched
+func (s *Stats) Searches() Counts {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.searches
+}
+
+// Documents returns a copy of the stats of each Document, by Name
+func (s *Stats) Documents() map[string]DocumentStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	documents := make(map[string]DocumentStats, len(s.documents))
+	for name, ds := range s.documents {
+		documents[name] = DocumentStats{ds.Counts, append([]Counts{}, ds.Patterns...)}
+	}
+	return documents
+}
+
+// Reset sets all counts to zero, i.e. after exporting them
+func (s *Stats) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.searches = Counts{}
+	s.documents = nil
+}
+
+// add counts a search of d, where the first Patterns matched or failed
+// as reported by patterns and, if the search failed, the next one
+// failed unless failedPattern is false
+func (s *Stats) add(d *Document, patterns []bool, failedPattern bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.documents == nil {
+		s.documents = map[string]*DocumentStats{}
+	}
+	ds, ok := s.documents[d.Name]
+	if !ok {
+		ds = &DocumentStats{}
+		s.documents[d.Name] = ds
+	}
+	for len(ds.Patterns) < len(d.Patterns) {
+		ds.Patterns = append(ds.Patterns, Counts{})
+	}
+	for i, matched := range patterns {
+		if matched {
+			ds.Patterns[i].Matched++
+		} else {
+			ds.Patterns[i].Failed++
+		}
+	}
+	if err == nil {
+		ds.Matched++
+		return
+	}
+	ds.Failed++
+	if failedPattern {
+		ds.Patterns[len(patterns)].Failed++
+	}
+}
+
+// SearchWithStats is like Search but also counts the result in stats
+func (d *Document) SearchWithStats(content string, stats *Stats) (Fields, error) {
+	content, err := d.preprocess(content)
+	if err != nil {
+		stats.add(d, nil, false, err)
+		return Fields{}, err
+	}
+	var patterns []bool
+	f, err := d.searchPatterns(content, func(p Pattern, f Fields) {
+		patterns = append(patterns, f != nil)
+	})
+	stats.add(d, patterns, err != nil && len(patterns) < len(d.Patterns), err)
+	return f, err
+}
+
+// SearchWithStats is like Search but also counts the result in stats.
+// Only the Documents tried are counted: the ones that failed and the
+// first one that matched
+func (ds *Documents) SearchWithStats(content string, stats *Stats) (Fields, error) {
+	errList := &ErrorList{}
+	for i, doc := range *ds {
+		fields, err := doc.SearchWithStats(content, stats)
+		if err == nil {
+			stats.count(true)
+			return fields, nil
+		}
+		errList.Add(fmt.Errorf("Document %d: %s", i, err.Error()))
+	}
+	stats.count(false)
+	return Fields{}, errList
+}
+
+// count adds a search of Documents to stats
+func (s *Stats) count(matched bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if matched {
+		s.searches.Matched++
+	} else {
+		s.searches.Failed++
+	}
+}
@@ -0,0 +1,109 @@
+package docparser_test
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+func ExampleStats() {
+	ds := docparser.Documents{
+		&docparser.Document{
+			Name: "Lead",
+			Patterns: []docparser.Pattern{
+				&docparser.PatternGroup{Regex: regexp.MustCompile(`Name: (?P<name>.*)`)},
+				&docparser.PatternGroup{Regex: regexp.MustCompile(`Phone: (?P<phone>.*)`)},
+			},
+		},
+	}
+	stats := &docparser.Stats{}
+
+	ds.SearchWithStats("Name: Mark\nPhone: 221-1122", stats)
+	ds.SearchWithStats("Name: Jane\nEmail: jane@site.com", stats)
+
+	lead := stats.Documents()["Lead"]
+	fmt.Printf("%d matched, %d failed\n", lead.Matched, lead.Failed)
+	fmt.Printf("phone: %d matched, %d failed\n", lead.Patterns[1].Matched, lead.Patterns[1].Failed)
+	// Output:
+	// 1 matched, 1 failed
+	// phone: 1 matched, 1 failed
+}
+
+func TestStats(t *testing.T) {
+	ds := docparser.Documents{
+		&docparser.Document{
+			Name:   "Lead",
+			SkipIf: regexp.MustCompile(`SPAM`),
+			Patterns: []docparser.Pattern{
+				&docparser.PatternGroup{Regex: regexp.MustCompile(`Name: (?P<name>.*)`)},
+				&docparser.PatternGroup{Regex: regexp.MustCompile(`Phone: (?P<phone>.*)`), Optional: true},
+			},
+			RequiredFields: []string{"phone"},
+		},
+		&docparser.Document{
+			Name: "Fallback",
+			Patterns: []docparser.Pattern{
+				&docparser.PatternGroup{Regex: regexp.MustCompile(`(?P<text>.+)`)},
+			},
+		},
+	}
+	stats := &docparser.Stats{}
+	for _, content := range []string{
+		"Name: Mark\nPhone: 221-1122", // Lead
+		"Name: Jane",                  // Lead RequiredFields, Fallback
+		"Phone: 221-1122",             // Lead pattern 0, Fallback
+		"SPAM",                        // Lead SkipIf, Fallback
+		"",                            // Lead pattern 0, Fallback pattern 0
+	} {
+		ds.SearchWithStats(content, stats)
+	}
+
+	if want := (docparser.Counts{Matched: 4, Failed: 1}); stats.Searches() != want {
+		t.Errorf("searches: want %v got %v", want, stats.Searches())
+	}
+	want := map[string]docparser.DocumentStats{
+		"Lead": {
+			Counts:   docparser.Counts{Matched: 1, Failed: 4},
+			Patterns: []docparser.Counts{{Matched: 2, Failed: 2}, {Matched: 2}},
+		},
+		"Fallback": {
+			Counts:   docparser.Counts{Matched: 3, Failed: 1},
+			Patterns: []docparser.Counts{{Matched: 3, Failed: 1}},
+		},
+	}
+	if got := stats.Documents(); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v got %v", want, got)
+	}
+
+	stats.Reset()
+	if len(stats.Documents()) != 0 || stats.Searches() != (docparser.Counts{}) {
+		t.Errorf("stats not reset: %v", stats.Documents())
+	}
+}
+
+func TestStatsConcurrent(t *testing.T) {
+	d := &docparser.Document{
+		Name:     "Lead",
+		Patterns: []docparser.Pattern{&docparser.PatternGroup{Regex: regexp.MustCompile(`Name: (?P<name>.*)`)}},
+	}
+	stats := &docparser.Stats{}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				d.SearchWithStats(fmt.Sprintf("Name: %d", i), stats)
+				stats.Documents()
+			}
+		}(i)
+	}
+	wg.Wait()
+	if got := stats.Documents()["Lead"].Matched; got != 1000 {
+		t.Errorf("want 1000 matches got %d", got)
+	}
+}
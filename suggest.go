@@ -0,0 +1,100 @@
+package docparser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// SuggestRegex suggests a regex for each field of examples, which maps
+// field names to their value in content, to bootstrap the patterns of a
+// new layout. Suggestions are meant to be refined by the author
+//
+// Each value is anchored on the literal text around its first
+// occurrence: the text before it in its line, or only the last label if
+// there are several, or the previous line if the value starts the line,
+// and the label after it in the line, if any. For "Mark Stewart" in
+// "Name: Mark Stewart Phone: 221-1122" it suggests:
+//
+//	(?m:^Name: (?P<name>.*?) Phone:)
+//
+// Return an error if a value is empty, spans lines, isn't found, or if
+// the suggested regex doesn't capture the value from content
+func SuggestRegex(content string, examples map[string]string) (map[string]string, error) {
+	suggestions := make(map[string]string, len(examples))
+	for name, value := range examples {
+		if !fieldNameRe.MatchString(name) {
+			return nil, fmt.Errorf("invalid field name %q", name)
+		}
+		regex, err := suggestRegex(content, name, value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to suggest regex for %s: %v", name, err)
+		}
+		suggestions[name] = regex
+	}
+	return suggestions, nil
+}
+
+func suggestRegex(content, name, value string) (string, error) {
+	if strings.TrimSpace(value) == "" || strings.ContainsAny(value, "\r\n") {
+		return "", fmt.Errorf("value %q must be a non empty single line", value)
+	}
+	start := strings.Index(content, value)
+	if start == -1 {
+		return "", fmt.Errorf("value %q not found", value)
+	}
+	end := start + len(value)
+	lineStart := strings.LastIndexByte(content[:start], '\n') + 1
+	lineEnd := len(content)
+	if i := strings.IndexByte(content[end:], '\n'); i != -1 {
+		lineEnd = end + i
+	}
+
+	var before string
+	prefix := content[lineStart:start]
+	switch {
+	case strings.Count(prefix, ":") > 1:
+		// several labels in the line, anchor on the last one only
+		label := strings.LastIndexByte(prefix, ':')
+		label = strings.LastIndexAny(strings.TrimRight(prefix[:label], " \t"), " \t") + 1
+		before = regexp.QuoteMeta(prefix[label:])
+	case strings.TrimSpace(prefix) != "" || lineStart == 0:
+		before = `^` + regexp.QuoteMeta(prefix)
+	default:
+		prevStart := strings.LastIndexByte(content[:lineStart-1], '\n') + 1
+		prev := strings.TrimSuffix(content[prevStart:lineStart-1], "\r")
+		before = `^` + regexp.QuoteMeta(prev) + `\r?\n` + regexp.QuoteMeta(prefix)
+	}
+	after := `\r?$`
+	if suffix := suggestSuffix(strings.TrimSuffix(content[end:lineEnd], "\r")); suffix != "" {
+		after = regexp.QuoteMeta(suffix)
+	}
+
+	reg := `(?m:` + before + `(?P<` + name + `>.*?)` + after + `)`
+	re, err := regexp.Compile(reg)
+	if err != nil {
+		return "", err
+	}
+	match := re.FindStringSubmatch(content)
+	if match == nil || match[re.SubexpIndex(name)] != value {
+		return "", fmt.Errorf("suggested regex %s doesn't capture %q", re, value)
+	}
+	return re.String(), nil
+}
+
+// suggestSuffix returns the literal that follows a value: the rest of
+// the line up to the next ":", like a following label, or else its
+// first word. Empty if the rest is only white space
+func suggestSuffix(rest string) string {
+	if strings.TrimSpace(rest) == "" {
+		return ""
+	}
+	if i := strings.IndexByte(rest, ':'); i != -1 {
+		return rest[:i+1]
+	}
+	word := strings.TrimLeft(rest, " \t")
+	if i := strings.IndexAny(word, " \t"); i != -1 {
+		word = word[:i]
+	}
+	return rest[:len(rest)-len(strings.TrimLeft(rest, " \t"))] + word
+}
@@ -0,0 +1,80 @@
+package docparser_test
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+const suggestContent = `New lead from website
+
+Name: Mark Stewart Phone: (808) 221-1122
+Email:  mark@site.com
+Comments:
+Call me after 5pm please
+`
+
+func ExampleSuggestRegex() {
+	suggestions, err := docparser.SuggestRegex(suggestContent, map[string]string{
+		"name":     "Mark Stewart",
+		"phone":    "(808) 221-1122",
+		"comments": "Call me after 5pm please",
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(suggestions["name"])
+	fmt.Println(suggestions["phone"])
+	fmt.Println(suggestions["comments"])
+	// Output:
+	// (?m:^Name: (?P<name>.*?) Phone:)
+	// (?m:Phone: (?P<phone>.*?)\r?$)
+	// (?m:^Comments:\r?\n(?P<comments>.*?)\r?$)
+}
+
+func TestSuggestRegex(t *testing.T) {
+	examples := map[string]string{
+		"email": "mark@site.com",
+		"when":  "5pm",
+		"title": "New lead",
+	}
+	suggestions, err := docparser.SuggestRegex(suggestContent, examples)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"email": `(?m:^Email:  (?P<email>.*?)\r?$)`,
+		"when":  `(?m:^Call me after (?P<when>.*?) please)`,
+		"title": `(?m:^(?P<title>.*?) from)`,
+	}
+	if !reflect.DeepEqual(suggestions, want) {
+		t.Errorf("want %v got %v", want, suggestions)
+	}
+	for name, regex := range suggestions {
+		fields, err := (&docparser.PatternGroup{Regex: regexp.MustCompile(regex)}).Search(suggestContent)
+		if err != nil || fields.GetString(name) != examples[name] {
+			t.Errorf("suggestion for %s doesn't work: %v %v", name, fields, err)
+		}
+	}
+}
+
+func TestSuggestRegexErrors(t *testing.T) {
+	var tests = []struct {
+		examples map[string]string
+		err      string
+	}{
+		{map[string]string{"fax": "221-3344"}, `failed to suggest regex for fax: value "221-3344" not found`},
+		{map[string]string{"x": ""}, `failed to suggest regex for x: value "" must be a non empty single line`},
+		{map[string]string{"bad name": "Mark"}, `invalid field name "bad name"`},
+	}
+	for _, tt := range tests {
+		_, err := docparser.SuggestRegex(suggestContent, tt.examples)
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("examples %v want error %q got %v", tt.examples, tt.err, err)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package docparser
+
+import (
+	"regexp"
+	"strings"
+)
+
+// PatternTable is a Pattern implementation for tables aligned with
+// white space into columns, like fixed width reports, that have no
+// delimiter to split the columns on
+//
+// Column boundaries are inferred from the positions of the names in the
+// header row, then each following row is sliced by those positions,
+// counting runes, until a blank line or the end of the content:
+//
+//	MLS     Address              Price
+//	2211    331 Kailua Rd, HI    $450,000
+//	9090    990 Kaelepulu Dr     $1,200,000
+type PatternTable struct {
+	Name string
+
+	// Header matches the header row. The whole line where the match
+	// starts is the header
+	Header *regexp.Regexp
+
+	// MinColumnGap is the minimum number of spaces between two column
+	// names in the header, so names can contain single spaces, like
+	// "List Price". Defaults to 2
+	MinColumnGap int
+
+	// OutputKey is the field that holds the []Fields of all rows, keyed
+	// by column name
+	OutputKey string
+
+	// Optional returns empty Fields instead of NoMatch if Header
+	// doesn't match
+	Optional bool
+}
+
+// Search for the header and slice each row below it into columns
+//
+// Rows shorter than the header get empty values for the missing
+// columns, while text past the last column start belongs to the last
+// column. Rows made only of separators like "----" are skipped. Values
+// are trimmed. Return NoMatch error if Header doesn't match
+func (pt *PatternTable) Search(content string) (Fields, error) {
+	loc := pt.Header.FindStringIndex(content)
+	if loc == nil {
+		if pt.Optional {
+			return Fields{}, nil
+		}
+		return Fields{}, NewNoMatch(pt.Name, content)
+	}
+	start := strings.LastIndexByte(content[:loc[0]], '\n') + 1
+	lines := strings.Split(content[start:], "\n")
+	columns := pt.columns([]rune(strings.TrimRight(lines[0], "\r")))
+
+	rows := []Fields{}
+	for _, line := range lines[1:] {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			break
+		}
+		if isTableSeparator(line) {
+			continue
+		}
+		rows = append(rows, sliceRow([]rune(line), columns))
+	}
+	return Fields{pt.OutputKey: rows}, nil
+}
+
+// tableColumn is a column name and its position in the header, in runes.
+// gapStart is where the white space before the name starts
+type tableColumn struct {
+	name            string
+	start, gapStart int
+}
+
+// columns splits header into column names separated by at least
+// MinColumnGap spaces
+func (pt *PatternTable) columns(header []rune) []tableColumn {
+	gap := pt.MinColumnGap
+	if gap <= 0 {
+		gap = 2
+	}
+	var columns []tableColumn
+	i := 0
+	for i < len(header) {
+		for i < len(header) && header[i] == ' ' {
+			i++
+		}
+		if i == len(header) {
+			break
+		}
+		gapStart := 0
+		if len(columns) > 0 {
+			gapStart = columns[len(columns)-1].start + len([]rune(columns[len(columns)-1].name))
+		}
+		start, spaces := i, 0
+		for i < len(header) && spaces < gap {
+			if header[i] == ' ' {
+				spaces++
+			} else {
+				spaces = 0
+			}
+			i++
+		}
+		name := strings.TrimSpace(string(header[start:i]))
+		columns = append(columns, tableColumn{name, start, gapStart})
+	}
+	return columns
+}
+
+// sliceRow cuts row at the start of each column. When a value overflows
+// to the left of its column start, like right aligned numbers, the cut
+// moves left to the closest space in the gap before the column
+func sliceRow(row []rune, columns []tableColumn) Fields {
+	fields := Fields{}
+	cuts := make([]int, len(columns)+1)
+	for i, column := range columns {
+		cut := column.start
+		if i > 0 && cut < len(row) && row[cut] != ' ' && row[cut-1] != ' ' {
+			for p := cut - 1; p >= column.gapStart && p > cuts[i-1]; p-- {
+				if row[p] == ' ' {
+					cut = p + 1
+					break
+				}
+			}
+		}
+		cuts[i] = cut
+	}
+	cuts[len(columns)] = len(row)
+	for i, column := range columns {
+		from, to := cuts[i], cuts[i+1]
+		if to > len(row) {
+			to = len(row)
+		}
+		if from > to {
+			from = to
+		}
+		fields[column.name] = strings.TrimSpace(string(row[from:to]))
+	}
+	return fields
+}
+
+func isTableSeparator(line string) bool {
+	return strings.Trim(line, "-=+| \t") == ""
+}
+
+// FieldNames returns OutputKey
+func (pt *PatternTable) FieldNames() []string {
+	return []string{pt.OutputKey}
+}
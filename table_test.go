@@ -0,0 +1,101 @@
+package docparser_test
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+func ExamplePatternTable() {
+	pattern := &docparser.PatternTable{
+		Name:      "Listings",
+		Header:    regexp.MustCompile(`MLS +Address`),
+		OutputKey: "listings",
+	}
+
+	content := `Daily report
+
+MLS     Address              List Price
+----    -------              ----------
+2211    331 Kailua Rd, HI      $450,000
+9090    990 Kaelepulu Dr     $1,200,000
+
+Thanks
+`
+
+	fields, err := pattern.Search(content)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, row := range fields["listings"].([]docparser.Fields) {
+		fmt.Printf("%s | %s | %s\n", row.GetString("MLS"), row.GetString("Address"), row.GetString("List Price"))
+	}
+	// Output:
+	// 2211 | 331 Kailua Rd, HI | $450,000
+	// 9090 | 990 Kaelepulu Dr | $1,200,000
+}
+
+func TestPatternTableRaggedRows(t *testing.T) {
+	pattern := &docparser.PatternTable{
+		Header:    regexp.MustCompile(`Name`),
+		OutputKey: "rows",
+	}
+	content := "Name    City      Beds\r\n" +
+		"Jos\u00e9    Kailua    3\r\n" +
+		"Mark    Hale\u02bbiwa\r\n" +
+		"Jane\r\n" +
+		"Bob     Hilo      4    pool\r\n"
+
+	fields, err := pattern.Search(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []docparser.Fields{
+		{"Name": "Jos\u00e9", "City": "Kailua", "Beds": "3"},
+		{"Name": "Mark", "City": "Hale\u02bbiwa", "Beds": ""},
+		{"Name": "Jane", "City": "", "Beds": ""},
+		{"Name": "Bob", "City": "Hilo", "Beds": "4    pool"},
+	}
+	if rows := fields["rows"]; !reflect.DeepEqual(rows, want) {
+		t.Errorf("want %#v got %#v", want, rows)
+	}
+}
+
+func TestPatternTableMinColumnGap(t *testing.T) {
+	pattern := &docparser.PatternTable{
+		Header:       regexp.MustCompile(`First Name`),
+		MinColumnGap: 3,
+		OutputKey:    "rows",
+	}
+	fields, err := pattern.Search("First Name   Last Name\nMark         Stewart\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []docparser.Fields{{"First Name": "Mark", "Last Name": "Stewart"}}
+	if rows := fields["rows"]; !reflect.DeepEqual(rows, want) {
+		t.Errorf("want %v got %v", want, rows)
+	}
+}
+
+func TestPatternTableNoMatch(t *testing.T) {
+	pattern := &docparser.PatternTable{
+		Name:      "Listings",
+		Header:    regexp.MustCompile(`MLS`),
+		OutputKey: "rows",
+	}
+	if _, err := pattern.Search("nothing"); err == nil || err.Error() != `No match for "Listings"` {
+		t.Errorf("want NoMatch got %v", err)
+	}
+	pattern.Optional = true
+	if fields, err := pattern.Search("nothing"); err != nil || len(fields) != 0 {
+		t.Errorf("want empty fields got %v %v", fields, err)
+	}
+	fields, err := pattern.Search("MLS  Price")
+	if err != nil || !reflect.DeepEqual(fields["rows"], []docparser.Fields{}) {
+		t.Errorf("want no rows got %v %v", fields, err)
+	}
+}
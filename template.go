@@ -0,0 +1,227 @@
+package docparser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// TemplateRegex compiles a template into a regex, where the template is
+// literal text with {field} placeholders, i.e. "Name: {name}\n" gives
+// the regex `Name:[ \t]+(?P<name>[^\r\n]*)\r?\n`
+//
+// Everything outside placeholders is matched literally, including
+// regex metacharacters, except white space: each run of spaces and tabs
+// matches one or more spaces or tabs, and line breaks match both "\n"
+// and "\r\n". A placeholder followed by literal text captures up to
+// the first occurrence of that text in the line, see CaptureUntil, so
+// "Name: {name} Phone: {phone}" splits correctly even if the name has
+// spaces. Otherwise it captures everything up to the end of the line.
+// Field names must start with a letter or underscore followed by
+// letters, digits or underscores, and can't repeat. Use {{ and }} for
+// literal braces
+//
+// Note these placeholders are unrelated to the variables of
+// TemplatePatternGroup, which are replaced by fields found before
+func TemplateRegex(tmpl string) (*regexp.Regexp, error) {
+	return TemplateRegexWith(tmpl, TemplateOptions{})
+}
+
+// TemplateOptions change how TemplateRegexWith compiles templates
+type TemplateOptions struct {
+	// ExactWhitespace matches white space literally, like any other
+	// text, and placeholders capture with `.` instead of `[^\r\n]`,
+	// so they include a trailing "\r"
+	ExactWhitespace bool
+}
+
+// TemplateRegexWith is like TemplateRegex with options
+func TemplateRegexWith(tmpl string, opts TemplateOptions) (*regexp.Regexp, error) {
+	var reg, literal strings.Builder
+	pending := "" // placeholder waiting to know what follows it
+	flush := func() {
+		if pending != "" {
+			lazy := strings.Trim(literal.String(), " \t") != ""
+			reg.WriteString(capture(pending, lazy, opts))
+			pending = ""
+		}
+		reg.WriteString(quoteLiteral(literal.String(), opts))
+		literal.Reset()
+	}
+	seen := map[string]bool{}
+	for i := 0; i < len(tmpl); i++ {
+		c := tmpl[i]
+		switch {
+		case c == '{' && strings.HasPrefix(tmpl[i:], "{{"):
+			literal.WriteByte('{')
+			i++
+		case c == '}' && strings.HasPrefix(tmpl[i:], "}}"):
+			literal.WriteByte('}')
+			i++
+		case c == '{':
+			end := strings.IndexByte(tmpl[i:], '}')
+			if end == -1 {
+				return nil, fmt.Errorf("unclosed placeholder at offset %d", i)
+			}
+			name := tmpl[i+1 : i+end]
+			if !fieldNameRe.MatchString(name) {
+				return nil, fmt.Errorf("invalid field name %q at offset %d", name, i)
+			}
+			if seen[name] {
+				return nil, fmt.Errorf("duplicate field name %q at offset %d", name, i)
+			}
+			seen[name] = true
+			flush()
+			pending = name
+			i += end
+		case c == '}':
+			return nil, fmt.Errorf("unexpected } at offset %d", i)
+		default:
+			literal.WriteByte(c)
+		}
+	}
+	flush()
+	return regexp.Compile(reg.String())
+}
+
+// CaptureUntil returns a regex capturing the named group up to the first
+// occurrence of literal in the line, and then matching literal, i.e.
+// CaptureUntil("name", " Phone:") gives `(?P<name>[^\r\n]*?) Phone:`
+//
+// Its lazy repetition is what TemplateRegex uses for placeholders
+// followed by text, and it can be used to build regexes by hand, where a
+// greedy `.*` would run past the next label
+func CaptureUntil(name, literal string) string {
+	return `(?P<` + name + `>[^\r\n]*?)` + regexp.QuoteMeta(literal)
+}
+
+// capture returns the group for a template placeholder, lazy if it's
+// followed by literal text other than spaces, which would otherwise
+// stop it at the first space
+func capture(name string, lazy bool, opts TemplateOptions) string {
+	reg := `[^\r\n]*`
+	if opts.ExactWhitespace {
+		reg = `.*`
+	}
+	if lazy {
+		reg += `?`
+	}
+	return `(?P<` + name + `>` + reg + `)`
+}
+
+// quoteLiteral returns a regex matching the literal text of a template
+func quoteLiteral(literal string, opts TemplateOptions) string {
+	if opts.ExactWhitespace {
+		return regexp.QuoteMeta(literal)
+	}
+	var reg strings.Builder
+	for literal != "" {
+		switch i := strings.IndexAny(literal, " \t\r\n"); {
+		case i == -1:
+			reg.WriteString(regexp.QuoteMeta(literal))
+			literal = ""
+		case i > 0:
+			reg.WriteString(regexp.QuoteMeta(literal[:i]))
+			literal = literal[i:]
+		case literal[0] == ' ' || literal[0] == '\t':
+			reg.WriteString(`[ \t]+`)
+			literal = strings.TrimLeft(literal, " \t")
+		default:
+			reg.WriteString(`\r?\n`)
+			literal = strings.TrimPrefix(strings.TrimPrefix(literal, "\r"), "\n")
+		}
+	}
+	return reg.String()
+}
+
+var fieldNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// TemplateSpec describes a Pattern with a template, see TemplateRegex
+type TemplateSpec struct {
+	// Name of the Pattern
+	Name string
+
+	// Template with {field} placeholders to find in the content. For
+	// lists the template of each item
+	Template string
+
+	// List is the name of the list field. If set the spec builds a
+	// PatternList whose items are the lines after ListHeader, up to
+	// the first blank line or the end of the content, and each item
+	// must match Template
+	List       string
+	ListHeader string
+
+	// Cleaners maps field names to the names of the registered
+	// Cleaners applied to them, in order. See RegisterCleaner
+	Cleaners map[string][]string
+
+	// ExactWhitespace matches white space in Template literally, see
+	// TemplateOptions
+	ExactWhitespace bool
+
+	Optional bool
+}
+
+// NewDocumentFromTemplates builds a Document with one Pattern for each
+// spec, in order
+//
+// All specs are validated before returning. Return the first error
+// found, prefixed with the spec name
+func NewDocumentFromTemplates(specs []TemplateSpec) (*Document, error) {
+	doc := &Document{Patterns: make([]Pattern, 0, len(specs))}
+	for _, spec := range specs {
+		p, err := spec.pattern()
+		if err != nil {
+			return nil, fmt.Errorf("template %q: %v", spec.Name, err)
+		}
+		doc.Patterns = append(doc.Patterns, p)
+	}
+	return doc, nil
+}
+
+func (spec *TemplateSpec) pattern() (Pattern, error) {
+	regex, err := TemplateRegexWith(spec.Template, TemplateOptions{ExactWhitespace: spec.ExactWhitespace})
+	if err != nil {
+		return nil, err
+	}
+	clean, err := spec.clean()
+	if err != nil {
+		return nil, err
+	}
+	if spec.List == "" {
+		return &PatternGroup{
+			Name:     spec.Name,
+			Regex:    regex,
+			Clean:    clean,
+			Optional: spec.Optional,
+		}, nil
+	}
+
+	if !fieldNameRe.MatchString(spec.List) {
+		return nil, fmt.Errorf("invalid list name %q", spec.List)
+	}
+	if spec.ListHeader == "" {
+		return nil, fmt.Errorf("list %q has no header", spec.List)
+	}
+	return &PatternList{
+		Name:       spec.Name,
+		ListRegex:  listRegex(regexp.QuoteMeta(spec.ListHeader), spec.List),
+		SplitRegex: regexp.MustCompile(`\n`),
+		ItemRegex:  regex,
+		CleanItem:  clean,
+		Optional:   spec.Optional,
+	}, nil
+}
+
+// listRegex returns the ListRegex of a template list, capturing under
+// name the lines after the header regex up to the first blank line or
+// the end of the content
+func listRegex(header, name string) *regexp.Regexp {
+	return regexp.MustCompile(`(?s:` + header + `\r?\n(?P<` + name + `>.*?)(?:\r?\n[ \t]*\r?\n|\z))`)
+}
+
+// clean returns a function applying all Cleaners, see cleanersFunc
+func (spec *TemplateSpec) clean() (func(f Fields) Fields, error) {
+	return cleanersFunc(spec.Cleaners)
+}
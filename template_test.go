@@ -0,0 +1,221 @@
+package docparser_test
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+)
+
+func init() {
+	docparser.RegisterCleaner("test_upper", func(key string) func(f docparser.Fields) docparser.Fields {
+		return func(f docparser.Fields) docparser.Fields {
+			f[key] = strings.ToUpper(f.GetString(key))
+			return f
+		}
+	})
+}
+
+func ExampleNewDocumentFromTemplates() {
+	document, err := docparser.NewDocumentFromTemplates([]docparser.TemplateSpec{
+		{
+			Name:     "Contact information",
+			Template: "Name: {name}\nPhone: {phone}\n",
+		},
+		{
+			Name:       "Properties viewed",
+			List:       "properties",
+			ListHeader: "Properties:",
+			Template:   " - MLS #{mls} / {address}",
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	content := `Name: Mark Stewart
+Phone: (123) 221-1122
+
+Properties:
+ - MLS #2211 / 331 Kailua Rd, HI
+ - MLS #9090 / 990 Kaelepulu Dr, HI
+
+Thanks!
+`
+
+	fields, err := document.Search(content)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(fields.GetString("name"))
+	fmt.Println(fields.GetString("phone"))
+	for _, property := range fields.GetMapSlice("properties") {
+		fmt.Printf("#%s: %s\n", property["mls"], property["address"])
+	}
+	// Output:
+	// Mark Stewart
+	// (123) 221-1122
+	// #2211: 331 Kailua Rd, HI
+	// #9090: 990 Kaelepulu Dr, HI
+}
+
+func TestTemplateRegex(t *testing.T) {
+	var tests = []struct {
+		tmpl, regex string
+	}{
+		{"Name: {name}\n", `Name:[ \t]+(?P<name>[^\r\n]*?)\r?\n`},
+		{"Price (USD): ${price}.", `Price[ \t]+\(USD\):[ \t]+\$(?P<price>[^\r\n]*?)\.`},
+		{"{{literal}} {_a1}", `\{literal\}[ \t]+(?P<_a1>[^\r\n]*)`},
+		{"no placeholders", `no[ \t]+placeholders`},
+		{"Kaʻilua: {city}", "Kaʻilua:[ \\t]+(?P<city>[^\\r\\n]*)"},
+		{"A  \t{a}\r\n\nB", `A[ \t]+(?P<a>[^\r\n]*?)\r?\n\r?\nB`},
+		{"{a}{b}", `(?P<a>[^\r\n]*)(?P<b>[^\r\n]*)`},
+		{"{a} ", `(?P<a>[^\r\n]*)[ \t]+`},
+	}
+	for _, tt := range tests {
+		regex, err := docparser.TemplateRegex(tt.tmpl)
+		if err != nil {
+			t.Errorf("template %q failed: %s", tt.tmpl, err)
+			continue
+		}
+		if regex.String() != tt.regex {
+			t.Errorf("template %q want %q got %q", tt.tmpl, tt.regex, regex)
+		}
+	}
+}
+
+func TestTemplateRegexWhitespace(t *testing.T) {
+	regex, err := docparser.TemplateRegex("Name: {name}\nPhone: {phone}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, content := range []string{
+		"Name: Bob Smith\nPhone: 221-1122\n",
+		"Name:  Bob Smith\r\nPhone:\t221-1122\r\n",
+	} {
+		match := regex.FindStringSubmatch(content)
+		if match == nil || match[1] != "Bob Smith" || match[2] != "221-1122" {
+			t.Errorf("content %q invalid match %q", content, match)
+		}
+	}
+
+	regex, err = docparser.TemplateRegexWith("Name: {name}\n", docparser.TemplateOptions{ExactWhitespace: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `Name: (?P<name>.*?)` + "\n"; regex.String() != want {
+		t.Errorf("want %q got %q", want, regex)
+	}
+	if match := regex.FindStringSubmatch("Name:  Bob\r\n"); match == nil || match[1] != " Bob\r" {
+		t.Errorf("exact whitespace should capture white space, got %q", match)
+	}
+}
+
+func TestTemplateRegexUntil(t *testing.T) {
+	regex, err := docparser.TemplateRegex("Name: {name} Phone: {phone} Email: {email}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tests = []struct {
+		content, name, phone, email string
+	}{
+		{"Name: Mark Stewart Phone: (808) 221 1122 Email: mark@site.com", "Mark Stewart", "(808) 221 1122", "mark@site.com"},
+		{"Name: Mary Jo Van Dyke  Phone: 221-1122 Email: a@b.com Email: c@d.com", "Mary Jo Van Dyke", "221-1122", "a@b.com Email: c@d.com"},
+	}
+	for _, tt := range tests {
+		match := regex.FindStringSubmatch(tt.content)
+		if match == nil || match[1] != tt.name || match[2] != tt.phone || match[3] != tt.email {
+			t.Errorf("content %q invalid match %q", tt.content, match)
+		}
+	}
+}
+
+func ExampleCaptureUntil() {
+	regex := regexp.MustCompile(`Name: ` + docparser.CaptureUntil("name", " Phone:"))
+	fmt.Println(regex)
+	fmt.Printf("%q\n", regex.FindStringSubmatch("Name: Mark Stewart Phone: 221-1122")[1])
+	// Output:
+	// Name: (?P<name>[^\r\n]*?) Phone:
+	// "Mark Stewart"
+}
+
+func TestTemplateRegexInvalid(t *testing.T) {
+	var tests = []struct {
+		tmpl, err string
+	}{
+		{"Name: {name", "unclosed placeholder at offset 6"},
+		{"Name: {}", `invalid field name "" at offset 6`},
+		{"Name: {first name}", `invalid field name "first name" at offset 6`},
+		{"Name: {1st}", `invalid field name "1st" at offset 6`},
+		{"{a} {a}", `duplicate field name "a" at offset 4`},
+		{"a } b", "unexpected } at offset 2"},
+	}
+	for _, tt := range tests {
+		_, err := docparser.TemplateRegex(tt.tmpl)
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("template %q want error %q got %v", tt.tmpl, tt.err, err)
+		}
+	}
+}
+
+func TestNewDocumentFromTemplatesCleaners(t *testing.T) {
+	document, err := docparser.NewDocumentFromTemplates([]docparser.TemplateSpec{
+		{
+			Name:     "Name",
+			Template: "Name: {name}\n",
+			Cleaners: map[string][]string{"name": {"test_upper"}},
+		},
+		{
+			Name:     "Email",
+			Template: "Email: {email}\n",
+			Optional: true,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields, err := document.Search("Name: bob\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name := fields.GetString("name"); name != "BOB" {
+		t.Errorf("cleaner not applied, got %q", name)
+	}
+	if fields.Has("email") {
+		t.Errorf("optional pattern should not return fields: %v", fields)
+	}
+}
+
+func TestNewDocumentFromTemplatesInvalid(t *testing.T) {
+	var tests = []struct {
+		spec docparser.TemplateSpec
+		err  string
+	}{
+		{
+			docparser.TemplateSpec{Name: "Bad", Template: "{name"},
+			`template "Bad": unclosed placeholder at offset 0`,
+		},
+		{
+			docparser.TemplateSpec{Name: "Cleaner", Template: "{name}", Cleaners: map[string][]string{"name": {"nope"}}},
+			`template "Cleaner": unknown cleaner "nope" for field "name"`,
+		},
+		{
+			docparser.TemplateSpec{Name: "List", Template: "{name}", List: "names"},
+			`template "List": list "names" has no header`,
+		},
+		{
+			docparser.TemplateSpec{Name: "List", Template: "{name}", List: "my names", ListHeader: "Names:"},
+			`template "List": invalid list name "my names"`,
+		},
+	}
+	for _, tt := range tests {
+		specs := []docparser.TemplateSpec{{Name: "Good", Template: "{good}"}, tt.spec}
+		_, err := docparser.NewDocumentFromTemplates(specs)
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("want error %q got %v", tt.err, err)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+// Package textnorm provides docparser preprocessors that normalize
+// Unicode text before matching
+//
+// It's a separate package to keep golang.org/x/text out of docparser's
+// dependencies
+package textnorm
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+var quotes = strings.NewReplacer(
+	"‘", "'", "’", "'", "‚", "'", "‛", "'",
+	"“", `"`, "”", `"`, "„", `"`, "‟", `"`,
+)
+
+// NormalizeUnicode makes text copied from word processors match ASCII
+// oriented regexes. Exactly these transformations are applied:
+//
+//   - NFKC normalization: compatibility characters are replaced by
+//     their canonical equivalent, i.e. full-width "Ｎａｍｅ" becomes
+//     "Name", "ﬁ" becomes "fi" and non-breaking and other fixed-width
+//     spaces become a regular space (U+0020). Accented letters are
+//     composed but kept, "é" stays "é"
+//   - single smart quotes (‘ ’ ‚ ‛) become ' and double smart quotes
+//     (“ ” „ ‟) become "
+//
+// NormalizeUnicode has the docparser.Preprocessor signature and never
+// returns an error
+func NormalizeUnicode(content string) (string, error) {
+	return quotes.Replace(norm.NFKC.String(content)), nil
+}
+
+// okina is the Hawaiian ʻokina, removed by FoldDiacritics although it's
+// a letter and not a combining mark, since senders include it
+// inconsistently
+const okina = '\u02bb'
+
+// FoldDiacritics is a docparser.Folder that removes diacritics, so
+// "Kaʻilua" and "Kāneʻohe" match "Kailua" and "Kaneohe"
+//
+// Each character is decomposed (NFD) and combining marks, like accents
+// and the kahakō, are removed, as well as the ʻokina. Use it as
+// PatternGroup.Fold so regexes match without diacritics while captured
+// values keep them:
+//
+//	&docparser.PatternGroup{
+//		Regex: regexp.MustCompile(`City: (?P<city>Kailua|Kaneohe)`),
+//		Fold:  textnorm.FoldDiacritics,
+//	}
+func FoldDiacritics(content string) (string, []int) {
+	var folded strings.Builder
+	offsets := make([]int, 0, len(content)+1)
+	for i, r := range content {
+		if r == okina {
+			continue
+		}
+		for _, d := range norm.NFD.String(string(r)) {
+			if unicode.Is(unicode.Mn, d) {
+				continue
+			}
+			folded.WriteRune(d)
+			for n := utf8.RuneLen(d); n > 0; n-- {
+				offsets = append(offsets, i)
+			}
+		}
+	}
+	offsets = append(offsets, len(content))
+	return folded.String(), offsets
+}
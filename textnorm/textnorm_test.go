@@ -0,0 +1,129 @@
+package textnorm_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/RealGeeks/docparser"
+	"github.com/RealGeeks/docparser/textnorm"
+)
+
+func ExampleNormalizeUnicode() {
+	document := &docparser.Document{
+		Preprocess: []docparser.Preprocessor{textnorm.NormalizeUnicode},
+		Patterns: []docparser.Pattern{
+			&docparser.PatternGroup{
+				Name:  "Name",
+				Regex: regexp.MustCompile(`Name: "(?P<name>[^"]*)"`),
+			},
+		},
+	}
+
+	fields, err := document.Search("Ｎａｍｅ: “Mark Stewart”")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(fields.GetString("name"))
+	// Output:
+	// Mark Stewart
+}
+
+func TestNormalizeUnicode(t *testing.T) {
+	var tests = []struct {
+		in, out string
+	}{
+		{"plain ascii", "plain ascii"},
+		{"a\u00a0b\u202fc\u2009d", "a b c d"},
+		{"Cafe\u0301", "Caf\u00e9"},
+		{"ＭＬＳ ＃２２１１", "MLS #2211"},
+		{"‘single’ “double” „low‟", `'single' "double" "low"`},
+		{"ﬁle", "file"},
+		{"Café Kaʻilua", "Café Kaʻilua"},
+	}
+	for _, tt := range tests {
+		out, err := textnorm.NormalizeUnicode(tt.in)
+		if err != nil {
+			t.Errorf("%q failed: %s", tt.in, err)
+			continue
+		}
+		if out != tt.out {
+			t.Errorf("%q want %q got %q", tt.in, tt.out, out)
+		}
+	}
+}
+
+func ExampleFoldDiacritics() {
+	pattern := &docparser.PatternGroup{
+		Name:  "City",
+		Regex: regexp.MustCompile(`City: (?P<city>Kailua|Kaneohe)\b`),
+		Fold:  textnorm.FoldDiacritics,
+	}
+
+	for _, content := range []string{"City: Kailua", "City: Ka\u02bbilua", "City: K\u0101ne\u02bbohe"} {
+		fields, err := pattern.Search(content)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(fields.GetString("city"))
+	}
+	// Output:
+	// Kailua
+	// Kaʻilua
+	// Kāneʻohe
+}
+
+func TestFoldDiacritics(t *testing.T) {
+	var tests = []struct {
+		in, folded string
+	}{
+		{"plain", "plain"},
+		{"Caf\u00e9", "Cafe"},
+		{"Cafe\u0301", "Cafe"},
+		{"K\u0101ne\u02bbohe", "Kaneohe"},
+		{"na\u00efve r\u00e9sum\u00e9", "naive resume"},
+		{"\u00c5ngstr\u00f6m", "Angstrom"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		folded, offsets := textnorm.FoldDiacritics(tt.in)
+		if folded != tt.folded {
+			t.Errorf("%q want %q got %q", tt.in, tt.folded, folded)
+		}
+		if len(offsets) != len(folded)+1 || offsets[len(folded)] != len(tt.in) {
+			t.Errorf("%q invalid offsets %v", tt.in, offsets)
+		}
+	}
+}
+
+func TestFoldDiacriticsSpans(t *testing.T) {
+	pattern := &docparser.PatternGroup{
+		Regex:            regexp.MustCompile(`Name: (?P<name>Jose) (?P<last>Pena)`),
+		Fold:             textnorm.FoldDiacritics,
+		IncludeFullMatch: true,
+	}
+	content := "Name: Jose\u0301 Pe\u00f1a!"
+
+	fields, err := pattern.Search(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name := fields.GetString("name"); name != "Jose\u0301" {
+		t.Errorf("want trailing combining mark kept in %q", name)
+	}
+	if last := fields.GetString("last"); last != "Pe\u00f1a" {
+		t.Errorf("want original value, got %q", last)
+	}
+	if full := fields.GetString("_match"); full != "Name: Jose\u0301 Pe\u00f1a" {
+		t.Errorf("want original full match, got %q", full)
+	}
+
+	spans, err := pattern.SearchSpans(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if span := spans["last"]; content[span[0]:span[1]] != "Pe\u00f1a" {
+		t.Errorf("want spans in original content, got %v", span)
+	}
+}
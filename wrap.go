@@ -0,0 +1,31 @@
+package docparser
+
+// wrappedPattern implements FieldNames, SetFields and GetFields for a
+// Pattern that runs another one, forwarding them to pattern. Unexported
+// wrappers embed it, exported ones call it with the Pattern they wrap
+type wrappedPattern struct {
+	pattern Pattern
+}
+
+// FieldNames returns the field names of pattern if it's a FieldNamer
+func (w wrappedPattern) FieldNames() []string {
+	if namer, ok := w.pattern.(FieldNamer); ok {
+		return namer.FieldNames()
+	}
+	return nil
+}
+
+// SetFields forwards the fields to pattern if it's a PatternWithFields
+func (w wrappedPattern) SetFields(f Fields) {
+	if withFields, ok := w.pattern.(PatternWithFields); ok {
+		withFields.SetFields(f)
+	}
+}
+
+// GetFields returns the fields from pattern if it's a PatternWithFields
+func (w wrappedPattern) GetFields() Fields {
+	if withFields, ok := w.pattern.(PatternWithFields); ok {
+		return withFields.GetFields()
+	}
+	return nil
+}